@@ -0,0 +1,53 @@
+package cardinal
+
+import (
+	"slices"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// Archetype is a set of components, along with the entities that have exactly those components. Entities with tags
+// are stored in a separate archetype from the entities without tags, with the same components.
+type Archetype struct {
+	ID         types.ArchetypeID
+	Components []types.ComponentMetadata
+	// Entities are the entities of the archetype, in the order they are stored in.
+	Entities []types.EntityID
+}
+
+// EachArchetype calls the callback with each archetype that matches the filter and has at least one entity, in order
+// of archetype IDs. Returning false from the callback stops the iteration early. This lets systems process the
+// entities of an archetype as a batch, e.g. to look up what an archetype's components are once instead of once per
+// entity, rather than one entity at a time as with Search.Each.
+//
+// The archetype passed to the callback is a copy, taken when the callback is called. The callback may change the
+// component values of the entities, and may create and remove entities or add and remove components, but the changes
+// to which entities belong to an archetype are not reflected in the Entities of an archetype that was already passed
+// to the callback. Entities moved to an archetype that is visited later are visited again there.
+func EachArchetype(
+	wCtx WorldContext, componentFilter filter.ComponentFilter, callback func(arch Archetype) bool,
+) error {
+	search := NewLegacySearch(componentFilter).(*Search) //nolint:errcheck // It's safe
+	archIDs := slices.Clone(search.evaluateSearch(wCtx))
+	for _, archID := range archIDs {
+		ids, err := wCtx.storeReader().GetEntitiesForArchID(archID)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		comps, err := wCtx.storeReader().GetComponentTypesForArchID(archID)
+		if err != nil {
+			return err
+		}
+		// The component that holds the tags of entities is internal, and tags are not components.
+		comps = slices.DeleteFunc(slices.Clone(comps), func(c types.ComponentMetadata) bool { return isTagsComponent(c) })
+		arch := Archetype{ID: archID, Components: comps, Entities: slices.Clone(ids)}
+		if !callback(arch) {
+			break
+		}
+	}
+	return nil
+}
@@ -91,3 +91,25 @@ func BenchmarkWorld_TickWithSystem(b *testing.B) {
 		)
 	}
 }
+
+func BenchmarkWorld_CreateMany(b *testing.B) {
+	const numOfEntities = 10000
+	tf := setupWorld(b, 0, false)
+	wCtx := cardinal.NewWorldContext(tf.World)
+	b.Run("CreateMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			_, err := cardinal.CreateMany(wCtx, numOfEntities, Health{})
+			assert.NilError(b, err)
+		}
+	})
+	b.Run("Create in a loop", func(b *testing.B) {
+		b.ReportAllocs()
+		for j := 0; j < b.N; j++ {
+			for k := 0; k < numOfEntities; k++ {
+				_, err := cardinal.Create(wCtx, Health{})
+				assert.NilError(b, err)
+			}
+		}
+	})
+}
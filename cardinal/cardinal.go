@@ -2,13 +2,17 @@ package cardinal
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/cardinal/component"
 	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/cardinal/worldstage"
 )
@@ -20,6 +24,9 @@ var (
 	ErrEntityMustHaveAtLeastOneComponent = gamestate.ErrEntityMustHaveAtLeastOneComponent
 	ErrComponentNotOnEntity              = gamestate.ErrComponentNotOnEntity
 	ErrComponentAlreadyOnEntity          = gamestate.ErrComponentAlreadyOnEntity
+	ErrMessageQueueFull                  = txpool.ErrPoolFull
+	ErrMessageQueueClosed                = txpool.ErrPoolClosed
+	ErrNonceNotIncreasing                = types.ErrNonceNotIncreasing
 )
 
 // FilterFunction wrap your component filter function of func(comp T) bool inside FilterFunction to use
@@ -47,6 +54,89 @@ func RegisterSystems(w *World, sys ...System) error {
 	return w.SystemManager.registerSystems(false, sys...)
 }
 
+// RegisterSystem registers a system under the given name instead of the name derived from the system's function name.
+// This is useful for systems that are anonymous functions, whose derived names are not meaningful, e.g. in
+// SystemTimings.
+func RegisterSystem(w *World, name string, sys System) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register systems",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	if name == "" {
+		return eris.New("system name must not be empty")
+	}
+	return w.SystemManager.registerSystem(false, name, sys)
+}
+
+// RegisterSystemWithPriority registers a system that runs before all systems with a lower priority, regardless of the
+// order in which they were registered. Systems registered without a priority have a priority of 0, and systems with
+// the same priority run in the order they were registered. The order is computed once when the world starts.
+func RegisterSystemWithPriority(w *World, priority int, sys System) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register systems",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	opts := systemOptions{Timeout: 0, Priority: priority, Interval: 0, Offset: 0}
+	return w.SystemManager.registerSystemsWithOptions(false, opts, sys)
+}
+
+// RegisterSystemsWithTimeout registers systems that may each run for at most the given timeout in a tick. Once the
+// timeout has passed, the context of the system's world context (see WorldContext.Context) is canceled, and a system
+// that does long running work should return as soon as it is done. When a system does not return within its timeout,
+// the tick fails with an error that wraps ErrSystemTimeout, in the same way as when a system returns an error.
+//
+// Go cannot stop a running function, so the tick waits for a timed out system to return before failing. A system that
+// ignores its context and has still not returned once its timeout has passed a second time is considered stalled, and
+// the process panics with a diagnostic that names the system and includes the stacks of all goroutines, instead of
+// letting the tick loop hang. State changes made by systems are only committed at the end of a successful tick, so
+// none of the changes made during the aborted tick, including those of earlier systems, are persisted, and a restarted
+// world recovers from the last committed tick.
+func RegisterSystemsWithTimeout(w *World, timeout time.Duration, sys ...System) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register systems",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	if timeout <= 0 {
+		return eris.Errorf("system timeout must be positive, got %s", timeout)
+	}
+	opts := systemOptions{Timeout: timeout, Priority: 0, Interval: 0, Offset: 0}
+	return w.SystemManager.registerSystemsWithOptions(false, opts, sys...)
+}
+
+// RegisterSystemEvery registers a system that only runs on the ticks that are a multiple of n, starting at tick 0.
+func RegisterSystemEvery(w *World, n int, sys System) error {
+	return RegisterSystemEveryWithOffset(w, n, 0, sys)
+}
+
+// RegisterSystemEveryWithOffset registers a system that first runs on tick offset, and then every n ticks after that.
+// The system is not run on the ticks before offset.
+func RegisterSystemEveryWithOffset(w *World, n, offset int, sys System) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"world state is %s, expected %s to register systems",
+			w.worldStage.Current(),
+			worldstage.Init,
+		)
+	}
+	if n <= 0 {
+		return eris.Errorf("failed to register system: interval must be positive, got %d", n)
+	}
+	if offset < 0 {
+		return eris.Errorf("failed to register system: offset must not be negative, got %d", offset)
+	}
+	opts := systemOptions{Timeout: 0, Priority: 0, Interval: uint64(n), Offset: uint64(offset)}
+	return w.SystemManager.registerSystemsWithOptions(false, opts, sys)
+}
+
 func RegisterInitSystems(w *World, sys ...System) error {
 	if w.worldStage.Current() != worldstage.Init {
 		return eris.Errorf(
@@ -58,23 +148,56 @@ func RegisterInitSystems(w *World, sys ...System) error {
 	return w.SystemManager.registerSystems(true, sys...)
 }
 
+// RegisterComponent registers the component type T. The returned error names the Go type of T (e.g. "comp.Location"),
+// so that the failing registration can be identified when the errors of several registrations are joined.
 func RegisterComponent[T types.Component](w *World) error {
+	return registerComponent[T](w)
+}
+
+// RegisterComponentWithDefault registers the component type T like RegisterComponent, with the given factory making
+// the initial value of the component. The factory is called for every entity the component is added to with
+// AddComponentTo, and for every entity that is created with a zero value of the component with Create or CreateMany.
+// An explicit zero value can't be told apart from a missing one, so it is replaced by the default value as well; set
+// the component with SetComponent after creating the entity to store a zero value.
+func RegisterComponentWithDefault[T types.Component](w *World, factory func() T) error {
+	if factory == nil {
+		return eris.New("failed to register component: default value factory must not be nil")
+	}
+	return registerComponent[T](w, component.WithDefaultFunc(factory))
+}
+
+// Codec encodes and decodes the values of a component. See RegisterComponentWithCodec.
+type Codec[T types.Component] component.Codec[T]
+
+// RegisterComponentWithCodec registers the component type T like RegisterComponent, with its values encoded by the
+// given codec instead of JSON wherever they are stored, including in snapshots and the deltas computed by Diff.
+// Components are still returned as JSON to clients.
+func RegisterComponentWithCodec[T types.Component](w *World, codec Codec[T]) error {
+	if codec == nil {
+		return eris.New("failed to register component: codec must not be nil")
+	}
+	return registerComponent[T](w, component.WithCodec[T](codec))
+}
+
+func registerComponent[T types.Component](w *World, opts ...component.Option[T]) error {
+	typeName := reflect.TypeOf((*T)(nil)).Elem().String()
 	if w.worldStage.Current() != worldstage.Init {
 		return eris.Errorf(
-			"world state is %s, expected %s to register component",
+			"failed to register component %s: world state is %s, expected %s to register component",
+			typeName,
 			w.worldStage.Current(),
 			worldstage.Init,
 		)
 	}
 
-	compMetadata, err := component.NewComponentMetadata[T]()
+	compMetadata, err := component.NewComponentMetadata[T](opts...)
 	if err != nil {
-		return err
+		return eris.Wrapf(err, "failed to register component %s", typeName)
 	}
 
 	err = w.RegisterComponent(compMetadata)
 	if err != nil {
-		return err
+		return eris.Wrapf(err, "failed to register component %s (%q)", typeName, compMetadata.Name())
 	}
 
 	return nil
@@ -87,29 +210,55 @@ func MustRegisterComponent[T types.Component](w *World) {
 	}
 }
 
+// EachMessage calls fn for every message of the type In in the current tick. The Out value returned by fn is saved as
+// the result of the message's receipt, and a returned error as an error of the receipt.
 func EachMessage[In any, Out any](wCtx WorldContext, fn func(TxData[In]) (Out, error)) error {
+	msgType, err := getMessageType[In, Out](wCtx)
+	if err != nil {
+		return err
+	}
+	msgType.Each(wCtx, fn)
+	return nil
+}
+
+// SetMessageResult sets the result of the receipt of the message with the given transaction hash, replacing the result
+// that was returned from EachMessage, if any. It lets a system that runs after the one that handled the message fill in
+// or update its result, which clients then receive with the receipt.
+func SetMessageResult[In any, Out any](wCtx WorldContext, hash types.TxHash, result Out) error {
+	msgType, err := getMessageType[In, Out](wCtx)
+	if err != nil {
+		return err
+	}
+	msgType.SetResult(wCtx, hash, result)
+	return nil
+}
+
+// getMessageType returns the registered message with the input type In and the output type Out.
+func getMessageType[In any, Out any](wCtx WorldContext) (*MessageType[In, Out], error) {
 	var msg MessageType[In, Out]
 	msgType := reflect.TypeOf(msg)
 	tempRes, ok := wCtx.getMessageByType(msgType)
 	if !ok {
-		return eris.Errorf("Could not find %s, Message may not be registered.", msg.Name())
+		return nil, eris.Errorf("Could not find %s, Message may not be registered.", msg.Name())
 	}
 	var _ types.Message = &msg
 	res, ok := tempRes.(*MessageType[In, Out])
 	if !ok {
-		return eris.New("wrong type")
+		return nil, eris.New("wrong type")
 	}
-	res.Each(wCtx, fn)
-	return nil
+	return res, nil
 }
 
-// RegisterMessage registers a message to the world. Cardinal will automatically set up HTTP routes that map to each
-// registered message. Message URLs are take the form of "group.name". A default group, "game", is used
-// unless the WithCustomMessageGroup option is used. Example: game.throw-rock
+// RegisterMessage registers a message with the given name, and In and Out as its input and output types. Cardinal will
+// automatically set up HTTP routes that map to each registered message. Message URLs take the form of "group.name",
+// e.g. game.throw-rock. A default group, "game", is used unless the WithCustomMessageGroup option is used. The
+// returned error names the message and its input type, so that the failing registration can be identified when the
+// errors of several registrations are joined.
 func RegisterMessage[In any, Out any](world *World, name string, opts ...MessageOption[In, Out]) error {
 	if world.worldStage.Current() != worldstage.Init {
 		return eris.Errorf(
-			"world state is %s, expected %s to register messages",
+			"failed to register message %q: world state is %s, expected %s to register messages",
+			name,
 			world.worldStage.Current(),
 			worldstage.Init,
 		)
@@ -121,12 +270,26 @@ func RegisterMessage[In any, Out any](world *World, name string, opts ...Message
 	// Register the message with the manager
 	err := world.RegisterMessage(msgType, reflect.TypeOf(*msgType))
 	if err != nil {
-		return err
+		return eris.Wrapf(err, "failed to register message %q (%s)", name, reflect.TypeOf((*In)(nil)).Elem())
 	}
 
 	return nil
 }
 
+// RegisterMessageWithCodec registers the message like RegisterMessage, with its bodies encoded and decoded by the given
+// codec instead of JSON, including when they are read back from the base shard.
+func RegisterMessageWithCodec[In any, Out any](
+	world *World,
+	name string,
+	codec MessageCodec[In],
+	opts ...MessageOption[In, Out],
+) error {
+	if codec == nil {
+		return eris.Errorf("failed to register message %q: codec must not be nil", name)
+	}
+	return RegisterMessage[In, Out](world, name, append(opts, WithMessageCodec[In, Out](codec))...)
+}
+
 func RegisterQuery[Request any, Reply any](
 	w *World,
 	name string,
@@ -135,7 +298,8 @@ func RegisterQuery[Request any, Reply any](
 ) (err error) {
 	if w.worldStage.Current() != worldstage.Init {
 		return eris.Errorf(
-			"world state is %s, expected %s to register query",
+			"failed to register query %q: world state is %s, expected %s to register query",
+			name,
 			w.worldStage.Current(),
 			worldstage.Init,
 		)
@@ -143,11 +307,13 @@ func RegisterQuery[Request any, Reply any](
 
 	q, err := newQueryType[Request, Reply](name, handler, opts...)
 	if err != nil {
-		return err
+		return eris.Wrapf(err, "failed to register query %q", name)
 	}
 
-	res := w.RegisterQuery(q)
-	return res
+	if err := w.RegisterQuery(q); err != nil {
+		return eris.Wrapf(err, "failed to register query %q", name)
+	}
+	return nil
 }
 
 // Create creates a single entity in the world, and returns the id of the newly created entity.
@@ -162,7 +328,8 @@ func Create(wCtx WorldContext, components ...types.Component) (_ types.EntityID,
 }
 
 // CreateMany creates multiple entities in the world, and returns the slice of ids for the newly created
-// entities. At least 1 component must be provided.
+// entities. At least 1 component must be provided. Components that are given as their zero value and have a default
+// value (see RegisterComponentWithDefault) are set to their default value instead.
 func CreateMany(wCtx WorldContext, num int, components ...types.Component) (entityIDs []types.EntityID, err error) {
 	defer func() { panicOnFatalError(wCtx, err) }()
 
@@ -185,25 +352,36 @@ func CreateMany(wCtx WorldContext, num int, components ...types.Component) (enti
 		acc = append(acc, c)
 	}
 
-	// Create the entities
-	entityIDs, err = wCtx.storeManager().CreateManyEntities(num, acc...)
+	// Create the entities. The store sorts the component metadata it is given, so it gets a copy to keep acc in the
+	// order of the components.
+	entityIDs, err = wCtx.storeManager().CreateManyEntities(num, slices.Clone(acc)...)
 	if err != nil {
 		return nil, err
 	}
 
-	// Store the components for the entities
+	// Components that are given as their zero value are replaced by their default value, if the component has one.
+	// Whether that is the case is the same for every entity, so it is only worked out once per component.
+	useDefault := make([]bool, len(components))
+	for i, comp := range components {
+		if _, ok := acc[i].Default(); ok {
+			useDefault[i] = reflect.ValueOf(comp).IsZero()
+		}
+	}
+
+	// Store the components for the entities.
 	for _, id := range entityIDs {
-		for _, comp := range components {
-			var c types.ComponentMetadata
-			c, err = wCtx.getComponentByName(comp.Name())
-			if err != nil {
-				return nil, eris.Wrap(err, "failed to create entity because component is not registered")
+		for i, comp := range components {
+			c := acc[i]
+			if useDefault[i] {
+				// The default value is made again for every entity, so that entities don't share it.
+				comp, _ = c.Default()
 			}
 
 			err = wCtx.storeManager().SetComponentForEntity(c, id, comp)
 			if err != nil {
 				return nil, err
 			}
+			wCtx.getSpatialIndexes().componentSet(comp.Name(), id, comp)
 		}
 	}
 
@@ -231,6 +409,7 @@ func SetComponent[T types.Component](wCtx WorldContext, id types.EntityID, compo
 	if err != nil {
 		return err
 	}
+	wCtx.getSpatialIndexes().componentSet(c.Name(), id, component)
 
 	// Log
 	wCtx.Logger().Debug().
@@ -264,7 +443,7 @@ func GetComponent[T types.Component](wCtx WorldContext, id types.EntityID) (comp
 	if !ok {
 		comp, ok = compValue.(*T)
 		if !ok {
-			return nil, err
+			return nil, eris.Errorf("component %q of entity %d has unexpected type %T", c.Name(), id, compValue)
 		}
 	} else {
 		comp = &t
@@ -273,6 +452,105 @@ func GetComponent[T types.Component](wCtx WorldContext, id types.EntityID) (comp
 	return comp, nil
 }
 
+// ComponentNotOnEntityError is returned by TryGetComponent when the entity does not have the requested component. It
+// wraps ErrComponentNotOnEntity.
+type ComponentNotOnEntityError struct {
+	ComponentName string
+	EntityID      types.EntityID
+}
+
+func (e *ComponentNotOnEntityError) Error() string {
+	return fmt.Sprintf("entity %d does not have component %q", e.EntityID, e.ComponentName)
+}
+
+func (e *ComponentNotOnEntityError) Unwrap() error {
+	return ErrComponentNotOnEntity
+}
+
+// TryGetComponent returns a copy of the component of type T of the entity, like GetComponent. If the entity exists
+// but does not have the component, a *ComponentNotOnEntityError is returned, in read-only world contexts as well, so
+// that a system that is not sure whether an entity has a component can check for it and carry on. Use GetComponent
+// for components that the entity is known to have.
+func TryGetComponent[T types.Component](wCtx WorldContext, id types.EntityID) (comp T, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if err := requireComponents(wCtx, id, comp.Name()); err != nil {
+		return comp, err
+	}
+	return getComponentValue[T](wCtx, id)
+}
+
+// GetComponents2 returns copies of the components of types A and B of the entity, which must have both of them. The
+// components of the entity are looked up once for both. If the entity does not have one of the components, a
+// *ComponentNotOnEntityError is returned, as with TryGetComponent.
+func GetComponents2[A, B types.Component](wCtx WorldContext, id types.EntityID) (a A, b B, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if err = requireComponents(wCtx, id, a.Name(), b.Name()); err != nil {
+		return a, b, err
+	}
+	if a, err = getComponentValue[A](wCtx, id); err != nil {
+		return a, b, err
+	}
+	b, err = getComponentValue[B](wCtx, id)
+	return a, b, err
+}
+
+// GetComponents3 returns copies of the components of types A, B, and C of the entity, like GetComponents2.
+func GetComponents3[A, B, C types.Component](wCtx WorldContext, id types.EntityID) (a A, b B, c C, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	if err = requireComponents(wCtx, id, a.Name(), b.Name(), c.Name()); err != nil {
+		return a, b, c, err
+	}
+	if a, err = getComponentValue[A](wCtx, id); err != nil {
+		return a, b, c, err
+	}
+	if b, err = getComponentValue[B](wCtx, id); err != nil {
+		return a, b, c, err
+	}
+	c, err = getComponentValue[C](wCtx, id)
+	return a, b, c, err
+}
+
+// requireComponents returns a *ComponentNotOnEntityError for the first of the named components that the entity does
+// not have.
+func requireComponents(wCtx WorldContext, id types.EntityID, names ...string) error {
+	for _, name := range names {
+		if _, err := wCtx.getComponentByName(name); err != nil {
+			return err
+		}
+		ok, err := hasComponent(wCtx, id, name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return eris.Wrap(&ComponentNotOnEntityError{ComponentName: name, EntityID: id}, "")
+		}
+	}
+	return nil
+}
+
+// hasComponent reports whether the entity has the named component.
+func hasComponent(wCtx WorldContext, id types.EntityID, name string) (bool, error) {
+	// Read-only contexts can't tell a missing component apart from other errors, so look at the entity's components.
+	comps, err := wCtx.storeReader().GetComponentTypesForEntity(id)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(comps, func(c types.ComponentMetadata) bool { return c.Name() == name }), nil
+}
+
+// getComponentValue returns a copy of the component of type T of the entity.
+func getComponentValue[T types.Component](wCtx WorldContext, id types.EntityID) (T, error) {
+	value, err := GetComponent[T](wCtx, id)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *value, nil
+}
+
 func UpdateComponent[T types.Component](wCtx WorldContext, id types.EntityID, fn func(*T) *T) (err error) {
 	defer func() { panicOnFatalError(wCtx, err) }()
 
@@ -320,6 +598,17 @@ func AddComponentTo[T types.Component](wCtx WorldContext, id types.EntityID) (er
 		return err
 	}
 
+	// Initialize the component with its default value, so that the factory of the default value runs once per entity.
+	var value any = t
+	if defaultVal, ok := c.Default(); ok {
+		value = defaultVal
+		err = wCtx.storeManager().SetComponentForEntity(c, id, value)
+		if err != nil {
+			return err
+		}
+	}
+	wCtx.getSpatialIndexes().componentSet(c.Name(), id, value)
+
 	return nil
 }
 
@@ -339,16 +628,25 @@ func RemoveComponentFrom[T types.Component](wCtx WorldContext, id types.EntityID
 		return err
 	}
 
+	hooks := wCtx.getComponentRemovalHooks()
+	removed, err := hooks.lastValues(wCtx, id, []types.ComponentMetadata{c})
+	if err != nil {
+		return err
+	}
+
 	// Remove the component from entity
 	err = wCtx.storeManager().RemoveComponentFromEntity(c, id)
 	if err != nil {
 		return err
 	}
+	wCtx.getSpatialIndexes().componentRemoved(c.Name(), id)
+	hooks.fire(wCtx, id, removed)
 
 	return nil
 }
 
-// Remove removes the given Entity from the world.
+// Remove removes the given Entity from the world. The removal hooks of its components are called with their last
+// values (see RegisterComponentRemovalHook).
 func Remove(wCtx WorldContext, id types.EntityID) (err error) {
 	defer func() { panicOnFatalError(wCtx, err) }()
 
@@ -357,10 +655,84 @@ func Remove(wCtx WorldContext, id types.EntityID) (err error) {
 		return ErrEntityMutationOnReadOnly
 	}
 
+	hooks := wCtx.getComponentRemovalHooks()
+	var removed []removedComponent
+	if len(hooks) > 0 {
+		var comps []types.ComponentMetadata
+		if comps, err = wCtx.storeReader().GetComponentTypesForEntity(id); err != nil {
+			return err
+		}
+		if removed, err = hooks.lastValues(wCtx, id, comps); err != nil {
+			return err
+		}
+	}
+
 	err = wCtx.storeManager().RemoveEntity(id)
 	if err != nil {
 		return err
 	}
+	wCtx.getSpatialIndexes().entityRemoved(id)
+	hooks.fire(wCtx, id, removed)
 
 	return nil
 }
+
+// RemoveMany removes the given entities from the world and returns how many were removed. Entities that do not exist,
+// e.g. because they were already removed, are skipped, as are repeated IDs. The entities are grouped by archetype so
+// that each archetype is only updated once, which makes this much faster than calling Remove for each of a large
+// number of entities. Like Remove, it calls the removal hooks of the removed components.
+func RemoveMany(wCtx WorldContext, ids []types.EntityID) (_ int, err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	// Error if the context is read only
+	if wCtx.isReadOnly() {
+		return 0, ErrEntityMutationOnReadOnly
+	}
+
+	hooks := wCtx.getComponentRemovalHooks()
+	seen := make(map[types.EntityID]struct{}, len(ids))
+	live := make([]types.EntityID, 0, len(ids))
+	removed := make(map[types.EntityID][]removedComponent)
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		var comps []types.ComponentMetadata
+		comps, err = wCtx.storeReader().GetComponentTypesForEntity(id)
+		if eris.Is(err, ErrEntityDoesNotExist) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		live = append(live, id)
+		if len(hooks) == 0 {
+			continue
+		}
+		if removed[id], err = hooks.lastValues(wCtx, id, comps); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = wCtx.storeManager().RemoveEntities(live...); err != nil {
+		return 0, err
+	}
+	for _, id := range live {
+		wCtx.getSpatialIndexes().entityRemoved(id)
+		hooks.fire(wCtx, id, removed[id])
+	}
+	return len(live), nil
+}
+
+// CompactEntityStorage releases memory held by the in-memory entity lists of archetypes that had most of their
+// entities removed during the current tick. It is meant to be called by a maintenance system after a mass removal.
+func CompactEntityStorage(wCtx WorldContext) (err error) {
+	defer func() { panicOnFatalError(wCtx, err) }()
+
+	// Error if the context is read only
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+
+	return wCtx.storeManager().Compact()
+}
@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -417,6 +419,32 @@ func TestCannotHaveDuplicateTransactionNames(t *testing.T) {
 	assert.IsError(t, err)
 }
 
+func TestRegistrationErrorsNameTheFailingType(t *testing.T) {
+	type QueryHealthRequest struct{}
+	type QueryHealthResponse struct{}
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Foo](world))
+	assert.NilError(t, cardinal.RegisterMessage[ModifyScoreMsg, EmptyMsgResult](world, "modify_score"))
+
+	err := errors.Join(
+		cardinal.RegisterComponent[Bar](world),
+		cardinal.RegisterComponent[Foo](world),
+		cardinal.RegisterMessage[ModifyScoreMsg, EmptyMsgResult](world, "modify_score"),
+	)
+	assert.ErrorContains(t, err, `failed to register component cardinal_test.Foo ("foo")`)
+	assert.ErrorContains(t, err, `failed to register message "modify_score" (cardinal_test.ModifyScoreMsg)`)
+	assert.Check(t, !strings.Contains(err.Error(), "cardinal_test.Bar"))
+
+	// Errors from registering too late name the type as well.
+	tf.StartWorld()
+	assert.ErrorContains(t, cardinal.RegisterComponent[Health](world), "failed to register component cardinal_test.Health")
+	assert.ErrorContains(t, cardinal.RegisterQuery[QueryHealthRequest, QueryHealthResponse](world, "query_health",
+		func(cardinal.WorldContext, *QueryHealthRequest) (*QueryHealthResponse, error) {
+			return &QueryHealthResponse{}, nil
+		}), `failed to register query "query_health"`)
+}
+
 func TestCanGetTransactionErrorsAndResults(t *testing.T) {
 	type MoveMsg struct {
 		DeltaX, DeltaY int
@@ -730,6 +758,170 @@ func TestNewWorldWithCustomNamespace(t *testing.T) {
 	assert.Equal(t, tf.World.Namespace(), "custom-namespace")
 }
 
+func TestComponentsDescribesRegisteredComponents(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Location](world))
+	assert.NilError(t, cardinal.RegisterComponent[Player](world))
+
+	fields := map[string]map[string]any{}
+	var lastID types.ComponentID
+	for _, info := range world.Components() {
+		assert.Check(t, info.ID > lastID, "components must be ordered by ID")
+		lastID = info.ID
+		fields[info.Name] = info.Fields
+	}
+	assert.DeepEqual(t, map[string]any{"X": "int", "Y": "int"}, fields[Location{}.Name()])
+	assert.DeepEqual(t, map[string]any{"player": "string"}, fields[Player{}.Name()])
+}
+
+type Waypoint struct {
+	X, Y int
+}
+
+type MoveInput struct {
+	Direction string
+	Target    Waypoint
+	Path      []Waypoint
+}
+
+type MoveOutput struct {
+	Arrived bool
+}
+
+func TestMessagesDescribesRegisteredMessages(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[MoveInput, MoveOutput](world, "move"))
+
+	var move *cardinal.MessageInfo
+	for _, info := range world.Messages() {
+		if info.Name == "move" {
+			move = &info
+		}
+	}
+	assert.Assert(t, move != nil, "move message was not described")
+	assert.Equal(t, "game.move", move.FullName)
+
+	type schema struct {
+		Properties map[string]struct {
+			Type  string `json:"type"`
+			Ref   string `json:"$ref"`
+			Items struct {
+				Ref string `json:"$ref"`
+			} `json:"items"`
+		} `json:"properties"`
+		Defs map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"$defs"`
+	}
+	var in schema
+	assert.NilError(t, json.Unmarshal(move.InSchema, &in))
+	assert.Equal(t, 3, len(in.Properties))
+	assert.Equal(t, "string", in.Properties["Direction"].Type)
+	assert.Equal(t, "#/$defs/Waypoint", in.Properties["Target"].Ref)
+	assert.Equal(t, "array", in.Properties["Path"].Type)
+	assert.Equal(t, "#/$defs/Waypoint", in.Properties["Path"].Items.Ref)
+	assert.Equal(t, 2, len(in.Defs["Waypoint"].Properties))
+
+	var out schema
+	assert.NilError(t, json.Unmarshal(move.OutSchema, &out))
+	assert.Equal(t, "boolean", out.Properties["Arrived"].Type)
+}
+
+func TestMoveReceiptCarriesTheMoveOutput(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[MoveInput, MoveOutput](world, "move"))
+	var moved []types.TxHash
+	assert.NilError(t, cardinal.RegisterSystems(world,
+		func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[MoveInput, MoveOutput](wCtx,
+				func(tx cardinal.TxData[MoveInput]) (MoveOutput, error) {
+					moved = append(moved, tx.Hash)
+					return MoveOutput{Arrived: false}, nil
+				})
+		},
+		// A later system completes the moves and updates their results.
+		func(wCtx cardinal.WorldContext) error {
+			for _, hash := range moved {
+				if err := cardinal.SetMessageResult[MoveInput, MoveOutput](wCtx, hash, MoveOutput{Arrived: true}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	))
+	tf.StartWorld()
+
+	hashes, err := world.SubmitBatch([]cardinal.PendingMessage{
+		{PersonaTag: "alice", MessageName: "move", Value: MoveInput{Direction: "up", Target: Waypoint{X: 1, Y: 2}}},
+	})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	rec, ok := world.ReceiptByTxHash(hashes[0])
+	assert.Assert(t, ok)
+	assert.Equal(t, len(rec.Errs), 0)
+	out, ok := rec.Result.(MoveOutput)
+	assert.Assert(t, ok, "expected the result to be a MoveOutput, got %T", rec.Result)
+	assert.Equal(t, out, MoveOutput{Arrived: true})
+
+	// The result is part of the receipt sent to clients.
+	bz, err := rec.MarshalJSON()
+	assert.NilError(t, err)
+	assert.Check(t, strings.Contains(string(bz), `"result":{"Arrived":true}`))
+}
+
+type JoinInput struct {
+	Ok bool
+}
+
+type JoinOutput struct {
+	Success bool
+}
+
+func TestExportSchemaDescribesComponentsAndMessages(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Location](world))
+	assert.NilError(t, cardinal.RegisterMessage[JoinInput, JoinOutput](world, "join"))
+
+	bz, err := world.ExportSchema()
+	assert.NilError(t, err)
+
+	type object struct {
+		Ref        string `json:"$ref"`
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	var doc struct {
+		Components map[string]object `json:"components"`
+		Messages   map[string]struct {
+			In  object `json:"in"`
+			Out object `json:"out"`
+		} `json:"messages"`
+		Defs map[string]object `json:"$defs"`
+	}
+	assert.NilError(t, json.Unmarshal(bz, &doc))
+
+	location, ok := doc.Components[Location{}.Name()]
+	assert.Assert(t, ok, "location component is missing from the schema")
+	assert.Equal(t, "#/$defs/Location", location.Ref)
+	locationDef := doc.Defs["Location"]
+	assert.Equal(t, "integer", locationDef.Properties["X"].Type)
+	assert.Equal(t, "integer", locationDef.Properties["Y"].Type)
+	assert.DeepEqual(t, []string{"X", "Y"}, locationDef.Required)
+
+	join, ok := doc.Messages["game.join"]
+	assert.Assert(t, ok, "join message is missing from the schema")
+	assert.Equal(t, "boolean", join.In.Properties["Ok"].Type)
+	assert.DeepEqual(t, []string{"Ok"}, join.In.Required)
+	assert.Equal(t, "boolean", join.Out.Properties["Success"].Type)
+}
+
 func TestCanQueryInsideSystem(t *testing.T) {
 	testutils.SetTestTimeout(t, 10*time.Second)
 
@@ -781,6 +973,43 @@ func TestRandomNumberGenerator(t *testing.T) {
 	}
 }
 
+func TestSeededWorldsRollTheSameNumbers(t *testing.T) {
+	type rollIn struct{ Sides int }
+	type rollOut struct{ Value int }
+	roll := func(seed int64) []int {
+		tf := cardinal.NewTestFixture(t, nil, cardinal.WithSeed(seed))
+		assert.NilError(t, cardinal.RegisterMessage[rollIn, rollOut](tf.World, "roll"))
+		assert.NilError(t, cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+			return cardinal.EachMessage[rollIn, rollOut](wCtx, func(tx cardinal.TxData[rollIn]) (rollOut, error) {
+				return rollOut{Value: wCtx.Rand().Intn(tx.Msg.Sides)}, nil
+			})
+		}))
+		tf.StartWorld()
+
+		var rolls []int
+		for i := 0; i < 10; i++ {
+			hashes, err := tf.World.SubmitBatch([]cardinal.PendingMessage{
+				{PersonaTag: "alice", MessageName: "roll", Value: rollIn{Sides: 1000}},
+				{PersonaTag: "bob", MessageName: "roll", Value: rollIn{Sides: 1000}},
+			})
+			assert.NilError(t, err)
+			// The timestamps of the ticks must not matter.
+			time.Sleep(time.Duration(i) * time.Millisecond)
+			tf.DoTick()
+			for _, hash := range hashes {
+				rec, ok := tf.World.ReceiptByTxHash(hash)
+				assert.Check(t, ok)
+				rolls = append(rolls, rec.Result.(rollOut).Value)
+			}
+		}
+		return rolls
+	}
+
+	first := roll(42)
+	assert.DeepEqual(t, first, roll(42))
+	assert.Check(t, !slices.Equal(first, roll(43)))
+}
+
 func TestCanGetTimestampFromWorldContext(t *testing.T) {
 	var ts uint64
 	tf := cardinal.NewTestFixture(t, nil)
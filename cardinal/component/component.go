@@ -28,6 +28,15 @@ type componentMetadata[T types.Component] struct {
 	name       string
 	schema     []byte
 	defaultVal types.Component
+	defaultFn  func() T
+	codec      Codec[T]
+}
+
+// Codec encodes and decodes the values of a component. Components use JSON unless they are registered with a custom
+// codec, which can be used to store hot components in a more compact or faster format.
+type Codec[T types.Component] interface {
+	Marshal(T) ([]byte, error)
+	Unmarshal([]byte) (T, error)
 }
 
 // NewComponentMetadata creates a new component type.
@@ -38,6 +47,11 @@ func NewComponentMetadata[T types.Component](opts ...Option[T]) (
 	var t T
 	compType := reflect.TypeOf(t)
 
+	// This must come first, since reflecting the schema of a type that can't be encoded panics.
+	if err := validateEncodable(compType, t.Name()); err != nil {
+		return nil, err
+	}
+
 	schema, err := jsonschema.ReflectFromType(compType).MarshalJSON()
 	if err != nil {
 		return nil, eris.Wrap(err, "component must be json serializable")
@@ -91,18 +105,65 @@ func (c *componentMetadata[T]) ID() types.ComponentID {
 }
 
 func (c *componentMetadata[T]) New() ([]byte, error) {
-	if c.defaultVal != nil {
-		return codec.Encode(c.defaultVal)
+	if defaultVal, ok := c.Default(); ok {
+		return c.Encode(defaultVal)
+	}
+	if c.codec != nil {
+		var zero T
+		return c.Encode(zero)
 	}
 	return codec.Encode(c.compType)
 }
 
+// Default returns the default value of the component, or false if the component has no default value. If the default
+// value is made by a factory, the factory is called on every call to Default.
+func (c *componentMetadata[T]) Default() (types.Component, bool) {
+	if c.defaultFn != nil {
+		return c.defaultFn(), true
+	}
+	if c.defaultVal != nil {
+		return c.defaultVal, true
+	}
+	return nil, false
+}
+
 func (c *componentMetadata[T]) Encode(v any) ([]byte, error) {
-	return codec.Encode(v)
+	if c.codec == nil {
+		return codec.Encode(v)
+	}
+	var value T
+	switch comp := v.(type) {
+	case T:
+		value = comp
+	case *T:
+		if comp == nil {
+			return nil, eris.Errorf("cannot encode a nil value of component %q", c.name)
+		}
+		value = *comp
+	default:
+		return nil, eris.Errorf("cannot encode a value of type %T as component %q", v, c.name)
+	}
+	bz, err := c.codec.Marshal(value)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to encode component %q", c.name)
+	}
+	return bz, nil
 }
 
 func (c *componentMetadata[T]) Decode(bz []byte) (types.Component, error) {
-	return codec.Decode[T](bz)
+	if c.codec == nil {
+		return codec.Decode[T](bz)
+	}
+	value, err := c.codec.Unmarshal(bz)
+	if err != nil {
+		return value, eris.Wrapf(err, "failed to decode component %q", c.name)
+	}
+	return value, nil
+}
+
+// HasCustomCodec reports whether the component is encoded with a custom codec instead of JSON.
+func (c *componentMetadata[T]) HasCustomCodec() bool {
+	return c.codec != nil
 }
 
 func (c *componentMetadata[T]) ValidateAgainstSchema(targetSchema []byte) error {
@@ -131,3 +192,19 @@ func WithDefault[T types.Component](defaultVal T) Option[T] {
 		c.validateDefaultVal()
 	}
 }
+
+// WithDefaultFunc updates the created componentMetadata so that its default values are made by the given factory.
+// The factory is called every time a default value is needed, so values are never shared between entities.
+func WithDefaultFunc[T types.Component](factory func() T) Option[T] {
+	return func(c *componentMetadata[T]) {
+		c.defaultFn = factory
+	}
+}
+
+// WithCodec updates the created componentMetadata so that its values are encoded and decoded with the given codec
+// instead of JSON.
+func WithCodec[T types.Component](c Codec[T]) Option[T] {
+	return func(meta *componentMetadata[T]) {
+		meta.codec = c
+	}
+}
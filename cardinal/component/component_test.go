@@ -1,10 +1,13 @@
 package component_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/component"
 	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/types"
@@ -320,6 +323,35 @@ func TestRegisterComponent_ErrorOnDuplicateComponentName(t *testing.T) {
 	assert.ErrorContains(t, cardinal.RegisterComponent[ValueComponent](world), "is already registered")
 }
 
+type CallbackComponent struct {
+	Val      int
+	OnChange func(int)
+}
+
+func (CallbackComponent) Name() string {
+	return "CallbackComponent"
+}
+
+type IgnoredCallbackComponent struct {
+	Val      int
+	OnChange func(int) `json:"-"`
+}
+
+func (IgnoredCallbackComponent) Name() string {
+	return "IgnoredCallbackComponent"
+}
+
+func TestRegisterComponent_ErrorOnFieldThatCannotBeEncoded(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	err := cardinal.RegisterComponent[CallbackComponent](world)
+	assert.Check(t, errors.Is(err, component.ErrComponentNotEncodable))
+	assert.Check(t, strings.Contains(err.Error(), "CallbackComponent.OnChange has type func(int)"), err.Error())
+
+	// Fields that are skipped by the codec don't need to be encodable.
+	assert.NilError(t, cardinal.RegisterComponent[IgnoredCallbackComponent](world))
+}
+
 type OldComponent struct {
 	Val int
 }
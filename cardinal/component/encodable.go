@@ -0,0 +1,86 @@
+package component
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrComponentNotEncodable = eris.New("component cannot be encoded")
+
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// validateEncodable checks that every field of the component type t that the codec encodes can actually be encoded,
+// so that a component that can't be stored is rejected when it is registered instead of when it is first set.
+// Unexported fields are not encoded, so their values are silently lost when the component is stored; a warning is
+// logged for each.
+func validateEncodable(t reflect.Type, name string) error {
+	return checkEncodable(t, name, map[reflect.Type]bool{})
+}
+
+func checkEncodable(t reflect.Type, path string, seen map[reflect.Type]bool) error {
+	if seen[t] || implementsMarshaler(t) {
+		return nil
+	}
+	seen[t] = true
+
+	//nolint:exhaustive // All other kinds are encodable as they are
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return eris.Wrapf(ErrComponentNotEncodable, "%s has type %s, which the codec can't encode", path, t)
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return checkEncodable(t.Elem(), path+"[]", seen)
+	case reflect.Map:
+		if !isEncodableMapKey(t.Key()) {
+			return eris.Wrapf(ErrComponentNotEncodable, "%s has map key type %s, which the codec can't encode", path, t.Key())
+		}
+		return checkEncodable(t.Elem(), path+"[]", seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Tag.Get("json") == "-" {
+				continue
+			}
+			fieldPath := path + "." + field.Name
+			// The exported fields of embedded structs are encoded as if they were fields of the outer struct, even if
+			// the embedded struct type itself is unexported.
+			if !field.IsExported() && !(field.Anonymous && derefType(field.Type).Kind() == reflect.Struct) {
+				log.Warn().Msgf("%s is unexported and will not be stored with the component", fieldPath)
+				continue
+			}
+			if err := checkEncodable(field.Type, fieldPath, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)
+}
+
+func isEncodableMapKey(t reflect.Type) bool {
+	//nolint:exhaustive // Only strings, integers, and text marshalers can be map keys
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
@@ -105,7 +105,7 @@ func (m *manager) GetComponentByName(name string) (types.ComponentMetadata, erro
 func (m *manager) isComponentNameUnique(compMetadata types.ComponentMetadata) error {
 	_, ok := m.registeredComponents[compMetadata.Name()]
 	if ok {
-		return eris.Errorf("message %q is already registered", compMetadata.Name())
+		return eris.Errorf("component %q is already registered", compMetadata.Name())
 	}
 	return nil
 }
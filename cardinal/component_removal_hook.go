@@ -0,0 +1,78 @@
+package cardinal
+
+import (
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// componentRemovalHook is a hook registered with RegisterComponentRemovalHook, with the component value untyped.
+type componentRemovalHook func(wCtx WorldContext, id types.EntityID, last any)
+
+// componentRemovalHooks are the hooks registered on a world, keyed by the name of the component they watch.
+type componentRemovalHooks map[string][]componentRemovalHook
+
+// RegisterComponentRemovalHook registers a hook that is called whenever an entity loses its component T, either because
+// the component is removed with RemoveComponentFrom or because the entity is removed with Remove. The hook is called
+// after the removal with the last value of the component, so it can run cleanup logic such as releasing a handle or
+// decrementing a counter. Hooks of the same component are called in order of registration. The component must already
+// be registered, and the hook must be registered before the world starts.
+func RegisterComponentRemovalHook[T types.Component](
+	w *World, hook func(wCtx WorldContext, id types.EntityID, last T),
+) error {
+	var t T
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"failed to register removal hook for %q: world state is %s, expected %s",
+			t.Name(), w.worldStage.Current(), worldstage.Init,
+		)
+	}
+	if _, err := w.GetComponentByName(t.Name()); err != nil {
+		return eris.Wrapf(err, "failed to register removal hook for %q", t.Name())
+	}
+	w.componentRemovalHooks[t.Name()] = append(w.componentRemovalHooks[t.Name()],
+		func(wCtx WorldContext, id types.EntityID, last any) {
+			switch v := last.(type) {
+			case T:
+				hook(wCtx, id, v)
+			case *T:
+				hook(wCtx, id, *v)
+			}
+		})
+	return nil
+}
+
+// removedComponent is the last value of a component that is about to be removed from an entity.
+type removedComponent struct {
+	name  string
+	value any
+}
+
+// lastValues returns the values of the given components of an entity that have removal hooks, so the hooks can be
+// called with them once the components are removed.
+func (h componentRemovalHooks) lastValues(
+	wCtx WorldContext, id types.EntityID, comps []types.ComponentMetadata,
+) ([]removedComponent, error) {
+	var removed []removedComponent
+	for _, comp := range comps {
+		if len(h[comp.Name()]) == 0 {
+			continue
+		}
+		value, err := wCtx.storeReader().GetComponentForEntity(comp, id)
+		if err != nil {
+			return nil, err
+		}
+		removed = append(removed, removedComponent{name: comp.Name(), value: value})
+	}
+	return removed, nil
+}
+
+// fire calls the hooks of the given removed components of an entity.
+func (h componentRemovalHooks) fire(wCtx WorldContext, id types.EntityID, removed []removedComponent) {
+	for _, r := range removed {
+		for _, hook := range h[r.name] {
+			hook(wCtx, id, r.value)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package cardinal_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type Handle struct {
+	Slot int
+}
+
+func (Handle) Name() string {
+	return "handle"
+}
+
+func TestComponentRemovalHookSeesTheLastValue(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Handle](world))
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+
+	released := map[types.EntityID]int{}
+	assert.NilError(t, cardinal.RegisterComponentRemovalHook[Handle](world,
+		func(_ cardinal.WorldContext, id types.EntityID, last Handle) {
+			released[id] = last.Slot
+		}))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	detached, err := cardinal.Create(wCtx, Handle{Slot: 1}, Health{})
+	assert.NilError(t, err)
+	destroyed, err := cardinal.Create(wCtx, Handle{Slot: 2})
+	assert.NilError(t, err)
+	_, err = cardinal.Create(wCtx, Handle{Slot: 3}, Health{})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	// The hook sees the value the component had right before it was removed.
+	assert.NilError(t, cardinal.SetComponent[Handle](wCtx, detached, &Handle{Slot: 10}))
+	assert.NilError(t, cardinal.RemoveComponentFrom[Handle](wCtx, detached))
+	assert.NilError(t, cardinal.SetComponent[Handle](wCtx, destroyed, &Handle{Slot: 20}))
+	assert.NilError(t, cardinal.Remove(wCtx, destroyed))
+	// Removing an entity without the component does not call the hook.
+	withoutHandle, err := cardinal.Create(wCtx, Health{})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.Remove(wCtx, withoutHandle))
+	tf.DoTick()
+
+	assert.DeepEqual(t, released, map[types.EntityID]int{detached: 10, destroyed: 20})
+}
+
+func TestComponentRemovalHookMustBeRegisteredBeforeTheWorldStarts(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Handle](tf.World))
+	tf.StartWorld()
+
+	err := cardinal.RegisterComponentRemovalHook[Handle](tf.World, func(cardinal.WorldContext, types.EntityID, Handle) {})
+	assert.ErrorContains(t, err, "world state is")
+}
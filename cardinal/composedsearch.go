@@ -19,6 +19,12 @@ type NotSearch struct {
 	search Searchable
 }
 
+func (orSearch *OrSearch) Reset() {
+	for _, search := range orSearch.searches {
+		search.Reset()
+	}
+}
+
 func (orSearch *OrSearch) evaluateSearch(wCtx WorldContext) []types.ArchetypeID {
 	acc := make([]types.ArchetypeID, 0)
 	for _, search := range orSearch.searches {
@@ -56,6 +62,23 @@ func (orSearch *OrSearch) Each(wCtx WorldContext, callback CallbackFn) error {
 	return nil
 }
 
+func (orSearch *OrSearch) EachReverse(wCtx WorldContext, callback CallbackFn) error {
+	ids, err := orSearch.Collect(wCtx)
+	if err != nil {
+		return err
+	}
+	eachReverse(ids, callback)
+	return nil
+}
+
+func (orSearch *OrSearch) EachLimit(wCtx WorldContext, limit int, callback CallbackFn) error {
+	return eachLimit(wCtx, orSearch, limit, callback)
+}
+
+func (orSearch *OrSearch) Seq(wCtx WorldContext) func(yield func(types.EntityID, error) bool) {
+	return eachSeq(wCtx, orSearch)
+}
+
 func (orSearch *OrSearch) Collect(wCtx WorldContext) ([]types.EntityID, error) {
 	// deduplicate
 	idExists := make(map[types.EntityID]bool)
@@ -90,6 +113,17 @@ func (orSearch *OrSearch) First(wCtx WorldContext) (types.EntityID, error) {
 	return ids[0], nil
 }
 
+func (orSearch *OrSearch) Last(wCtx WorldContext) (types.EntityID, error) {
+	ids, err := orSearch.Collect(wCtx)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, eris.New("No search results")
+	}
+	return ids[len(ids)-1], nil
+}
+
 func (orSearch *OrSearch) MustFirst(wCtx WorldContext) types.EntityID {
 	id, err := orSearch.First(wCtx)
 	if err != nil {
@@ -106,6 +140,10 @@ func (orSearch *OrSearch) Count(wCtx WorldContext) (int, error) {
 	return len(ids), nil
 }
 
+func (orSearch *OrSearch) Any(wCtx WorldContext) (bool, error) {
+	return anyMatch(wCtx, orSearch)
+}
+
 func (andSearch *AndSearch) Each(wCtx WorldContext, callback CallbackFn) error {
 	// count
 	idCount := make(map[types.EntityID]int)
@@ -139,6 +177,23 @@ func (andSearch *AndSearch) Each(wCtx WorldContext, callback CallbackFn) error {
 	return nil
 }
 
+func (andSearch *AndSearch) EachReverse(wCtx WorldContext, callback CallbackFn) error {
+	ids, err := andSearch.Collect(wCtx)
+	if err != nil {
+		return err
+	}
+	eachReverse(ids, callback)
+	return nil
+}
+
+func (andSearch *AndSearch) EachLimit(wCtx WorldContext, limit int, callback CallbackFn) error {
+	return eachLimit(wCtx, andSearch, limit, callback)
+}
+
+func (andSearch *AndSearch) Seq(wCtx WorldContext) func(yield func(types.EntityID, error) bool) {
+	return eachSeq(wCtx, andSearch)
+}
+
 func (andSearch *AndSearch) Collect(wCtx WorldContext) ([]types.EntityID, error) {
 	// filter
 	results := make([]types.EntityID, 0)
@@ -167,6 +222,17 @@ func (andSearch *AndSearch) First(wCtx WorldContext) (types.EntityID, error) {
 	return ids[0], nil
 }
 
+func (andSearch *AndSearch) Last(wCtx WorldContext) (types.EntityID, error) {
+	ids, err := andSearch.Collect(wCtx)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, eris.New("No search results")
+	}
+	return ids[len(ids)-1], nil
+}
+
 func (andSearch *AndSearch) MustFirst(wCtx WorldContext) types.EntityID {
 	id, err := andSearch.First(wCtx)
 	if err != nil {
@@ -183,6 +249,16 @@ func (andSearch *AndSearch) Count(wCtx WorldContext) (int, error) {
 	return len(ids), nil
 }
 
+func (andSearch *AndSearch) Any(wCtx WorldContext) (bool, error) {
+	return anyMatch(wCtx, andSearch)
+}
+
+func (andSearch *AndSearch) Reset() {
+	for _, search := range andSearch.searches {
+		search.Reset()
+	}
+}
+
 func (andSearch *AndSearch) evaluateSearch(wCtx WorldContext) []types.ArchetypeID {
 	searchCounts := make(map[types.ArchetypeID]int)
 	for _, search := range andSearch.searches {
@@ -217,6 +293,23 @@ func (notSearch *NotSearch) Each(wCtx WorldContext, callback CallbackFn) error {
 	return nil
 }
 
+func (notSearch *NotSearch) EachReverse(wCtx WorldContext, callback CallbackFn) error {
+	ids, err := notSearch.Collect(wCtx)
+	if err != nil {
+		return err
+	}
+	eachReverse(ids, callback)
+	return nil
+}
+
+func (notSearch *NotSearch) EachLimit(wCtx WorldContext, limit int, callback CallbackFn) error {
+	return eachLimit(wCtx, notSearch, limit, callback)
+}
+
+func (notSearch *NotSearch) Seq(wCtx WorldContext) func(yield func(types.EntityID, error) bool) {
+	return eachSeq(wCtx, notSearch)
+}
+
 func (notSearch *NotSearch) Collect(wCtx WorldContext) ([]types.EntityID, error) {
 	// Get all ids
 	allsearch := NewSearch().Entity(filter.All())
@@ -260,6 +353,17 @@ func (notSearch *NotSearch) First(wCtx WorldContext) (types.EntityID, error) {
 	return ids[0], nil
 }
 
+func (notSearch *NotSearch) Last(wCtx WorldContext) (types.EntityID, error) {
+	ids, err := notSearch.Collect(wCtx)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, eris.New("No results found")
+	}
+	return ids[len(ids)-1], nil
+}
+
 func (notSearch *NotSearch) MustFirst(wCtx WorldContext) types.EntityID {
 	id, err := notSearch.First(wCtx)
 	if err != nil {
@@ -276,6 +380,14 @@ func (notSearch *NotSearch) Count(wCtx WorldContext) (int, error) {
 	return len(ids), nil
 }
 
+func (notSearch *NotSearch) Any(wCtx WorldContext) (bool, error) {
+	return anyMatch(wCtx, notSearch)
+}
+
+func (notSearch *NotSearch) Reset() {
+	notSearch.search.Reset()
+}
+
 func (notSearch *NotSearch) evaluateSearch(wCtx WorldContext) []types.ArchetypeID {
 	searchBuilder := NewSearch()
 	allResults := searchBuilder.Entity(filter.All()).evaluateSearch(wCtx)
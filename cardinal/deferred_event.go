@@ -0,0 +1,173 @@
+package cardinal
+
+import (
+	"cmp"
+	"encoding/json"
+	"reflect"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// deferredEvent is an internal component that holds an event emitted with EmitDeferredEvent until the next tick.
+// Keeping deferred events in the world's state means they are included in snapshots and survive restarts.
+type deferredEvent struct {
+	EmittedTick uint64
+	EventType   string
+	Body        []byte
+}
+
+func (deferredEvent) Name() string {
+	return "deferredEvent"
+}
+
+// deferredEventHandlers are the handlers registered for a type of deferred event, along with a function that decodes
+// the events of that type.
+type deferredEventHandlers struct {
+	decode   func([]byte) (any, error)
+	handlers []tickEventHandler
+}
+
+// deferredEventTypeName is the name under which deferred events of the given type are stored.
+func deferredEventTypeName(t reflect.Type) string {
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// RegisterDeferredEventHandler registers a handler that is called with every event of type T emitted with
+// EmitDeferredEvent. The events must be encodable as JSON.
+//
+// Events are delivered at the start of the tick after the one they were emitted in, before any system runs. This means
+// that they are handled before the messages of that tick are processed by systems. Events are delivered in the order
+// they were emitted, with the handlers of each event called in order of registration. Events emitted with
+// EmitDeferredEvent while delivering them are delivered in the following tick. An error returned by a handler fails
+// the tick, in the same way as an error returned by a system.
+//
+// Handlers must be registered before the world starts.
+func RegisterDeferredEventHandler[T any](w *World, handler func(wCtx WorldContext, evt T) error) error {
+	evtType := reflect.TypeOf((*T)(nil)).Elem()
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"failed to register deferred event handler for %s: world state is %s, expected %s",
+			evtType, w.worldStage.Current(), worldstage.Init,
+		)
+	}
+	if handler == nil {
+		return eris.Errorf("failed to register deferred event handler for %s: handler must not be nil", evtType)
+	}
+	name := deferredEventTypeName(evtType)
+	handlers, ok := w.tickEvents.deferred[name]
+	if !ok {
+		handlers = &deferredEventHandlers{
+			decode: func(bz []byte) (any, error) {
+				var evt T
+				if err := json.Unmarshal(bz, &evt); err != nil {
+					return nil, eris.Wrapf(err, "failed to decode deferred event %s", name)
+				}
+				return evt, nil
+			},
+			handlers: nil,
+		}
+		w.tickEvents.deferred[name] = handlers
+	}
+	handlers.handlers = append(handlers.handlers, func(wCtx WorldContext, evt any) error {
+		return handler(wCtx, evt.(T)) //nolint:errcheck // events are decoded into their registered type
+	})
+	return nil
+}
+
+// EmitDeferredEvent emits an event to the handlers registered for its type with RegisterDeferredEventHandler, to be
+// delivered at the start of the next tick. See RegisterDeferredEventHandler for the ordering guarantees. Pending
+// events are part of the world's state, so they are discarded along with the other changes of a failed tick, and they
+// are included in snapshots. Events without any handlers are dropped. Deferred events can only be emitted while
+// systems are running.
+func EmitDeferredEvent(wCtx WorldContext, evt any) error {
+	if evt == nil {
+		return eris.New("failed to emit deferred event: event must not be nil")
+	}
+	w := wCtx.getWorld()
+	if w == nil || wCtx.isReadOnly() || !w.SystemManager.isRunningSystems() {
+		return eris.New("failed to emit deferred event: deferred events can only be emitted by running systems")
+	}
+	name := deferredEventTypeName(reflect.TypeOf(evt))
+	if _, ok := w.tickEvents.deferred[name]; !ok {
+		return nil
+	}
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return eris.Wrapf(err, "failed to emit deferred event: failed to encode %s", name)
+	}
+	_, err = Create(wCtx, deferredEvent{EmittedTick: wCtx.CurrentTick(), EventType: name, Body: body})
+	if err != nil {
+		return eris.Wrap(err, "failed to emit deferred event")
+	}
+	return nil
+}
+
+// deliverDeferred removes the deferred events emitted before the current tick from the state and delivers them.
+func (b *tickEventBus) deliverDeferred(wCtx WorldContext) error {
+	type pending struct {
+		id  types.EntityID
+		evt *deferredEvent
+	}
+	var due []pending
+	var getErr error
+	err := NewSearch().Entity(filter.Exact(filter.Component[deferredEvent]())).Each(wCtx, func(id types.EntityID) bool {
+		evt, err := GetComponent[deferredEvent](wCtx, id)
+		if err != nil {
+			getErr = err
+			return false
+		}
+		if evt.EmittedTick < wCtx.CurrentTick() {
+			due = append(due, pending{id: id, evt: evt})
+		}
+		return true
+	})
+	if err = cmp.Or(getErr, err); err != nil {
+		return eris.Wrap(err, "failed to find the deferred events")
+	}
+	// Entity IDs are assigned in increasing order, so sorting by them orders the events as they were emitted.
+	slices.SortFunc(due, func(a, b pending) int {
+		return cmp.Compare(a.id, b.id)
+	})
+
+	for _, d := range due {
+		if err := Remove(wCtx, d.id); err != nil {
+			return eris.Wrap(err, "failed to remove a deferred event")
+		}
+		handlers, ok := b.deferred[d.evt.EventType]
+		if !ok {
+			continue
+		}
+		evt, err := handlers.decode(d.evt.Body)
+		if err != nil {
+			return err
+		}
+		for _, handler := range handlers.handlers {
+			if err := handler(wCtx, evt); err != nil {
+				return eris.Wrapf(err, "deferred event handler for %s generated an error", d.evt.EventType)
+			}
+		}
+	}
+	return nil
+}
+
+// deferredEventPlugin registers the component that holds the events emitted with EmitDeferredEvent.
+type deferredEventPlugin struct{}
+
+func newDeferredEventPlugin() *deferredEventPlugin {
+	return &deferredEventPlugin{}
+}
+
+func (*deferredEventPlugin) Register(w *World) error {
+	if err := RegisterComponent[deferredEvent](w); err != nil {
+		return eris.Wrap(err, "failed to register deferred event component")
+	}
+	return nil
+}
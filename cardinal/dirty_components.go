@@ -0,0 +1,26 @@
+package cardinal
+
+import (
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// ComponentChange is a component of an entity that was set during a tick.
+type ComponentChange struct {
+	EntityID      types.EntityID
+	ComponentName string
+}
+
+// DirtyComponents returns the components that were set during the current tick, or during the last tick if no tick is
+// in progress, in the order they were first set. Changes are cleared at the start of every tick, and when the pending
+// changes of a tick are discarded. It always returns nil unless the world was created with WithDirtyComponentTracking.
+func (w *World) DirtyComponents() []ComponentChange {
+	dirty := w.entityStore.DirtyComponents()
+	if dirty == nil {
+		return nil
+	}
+	changes := make([]ComponentChange, 0, len(dirty))
+	for _, change := range dirty {
+		changes = append(changes, ComponentChange{EntityID: change.EntityID, ComponentName: change.Component.Name()})
+	}
+	return changes
+}
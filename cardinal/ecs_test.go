@@ -213,6 +213,60 @@ func TestCanSetDefaultValue(t *testing.T) {
 	assert.Equal(t, newOwner.MyName, "Bob")
 }
 
+type Stats struct {
+	Health    int
+	Inventory []string
+}
+
+func (Stats) Name() string {
+	return "stats"
+}
+
+func TestRegisterComponentWithDefaultInitializesNewComponents(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+
+	assert.NilError(t, cardinal.RegisterComponent[Tuple](world))
+	assert.NilError(t, cardinal.RegisterComponentWithDefault[Stats](world, func() Stats {
+		return Stats{Health: 100, Inventory: []string{"sword"}}
+	}))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	created, err := cardinal.Create(wCtx, Stats{})
+	assert.NilError(t, err)
+	stats, err := cardinal.GetComponent[Stats](wCtx, created)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Stats{Health: 100, Inventory: []string{"sword"}}, *stats)
+
+	// Components that are given a value when the entity is created keep that value.
+	custom, err := cardinal.Create(wCtx, Stats{Health: 5})
+	assert.NilError(t, err)
+	stats, err = cardinal.GetComponent[Stats](wCtx, custom)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Stats{Health: 5}, *stats)
+
+	// An explicit zero value is replaced by the default value, but can still be set once the entity exists.
+	assert.NilError(t, cardinal.SetComponent[Stats](wCtx, custom, &Stats{}))
+	stats, err = cardinal.GetComponent[Stats](wCtx, custom)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Stats{}, *stats)
+
+	added, err := cardinal.Create(wCtx, Tuple{})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.AddComponentTo[Stats](wCtx, added))
+	stats, err = cardinal.GetComponent[Stats](wCtx, added)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, Stats{Health: 100, Inventory: []string{"sword"}}, *stats)
+
+	// Every entity gets its own default value, so changing one entity's component does not change another's.
+	stats.Inventory[0] = "shield"
+	assert.NilError(t, cardinal.SetComponent[Stats](wCtx, added, stats))
+	stats, err = cardinal.GetComponent[Stats](wCtx, created)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"sword"}, stats.Inventory)
+}
+
 type Tuple struct {
 	A, B int
 }
@@ -290,6 +344,28 @@ func TestCanRemoveEntity(t *testing.T) {
 	assert.Check(t, err != nil)
 }
 
+func TestCompactEntityStorageAfterMassRemoval(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Tuple](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	entities, err := cardinal.CreateMany(wCtx, 1000, Tuple{})
+	assert.NilError(t, err)
+	for _, id := range entities[:990] {
+		assert.NilError(t, cardinal.Remove(wCtx, id))
+	}
+	assert.NilError(t, cardinal.CompactEntityStorage(wCtx))
+
+	ids, err := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Tuple]())).Collect(wCtx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, entities[990:])
+
+	readOnlyCtx := cardinal.NewReadOnlyWorldContext(world)
+	assert.ErrorIs(t, cardinal.CompactEntityStorage(readOnlyCtx), cardinal.ErrEntityMutationOnReadOnly)
+}
+
 type CountComponent struct {
 	Val int
 }
@@ -491,6 +567,293 @@ func TestEntriesCanChangeTheirArchetype(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func alive(t *testing.T, world *cardinal.World, id types.EntityID) bool {
+	t.Helper()
+	ok, err := world.Alive(id)
+	assert.NilError(t, err)
+	return ok
+}
+
+func TestRemovedEntityIsNotAlive(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 3, Alpha{Name1: "old"})
+	assert.NilError(t, err)
+	tf.DoTick()
+	for _, id := range ids {
+		assert.Check(t, alive(t, world, id))
+	}
+
+	removed := ids[0]
+	assert.NilError(t, cardinal.Remove(wCtx, removed))
+	// The removal isn't committed until the next tick.
+	assert.Check(t, alive(t, world, removed))
+	tf.DoTick()
+	assert.Check(t, !alive(t, world, removed))
+	assert.Check(t, alive(t, world, ids[1]))
+
+	// A newly created entity never reuses the ID of a removed entity, so the old ID stays dead.
+	newID, err := cardinal.Create(wCtx, Alpha{Name1: "new"})
+	assert.NilError(t, err)
+	assert.Check(t, newID != removed)
+	assert.Check(t, !alive(t, world, removed))
+	_, err = cardinal.GetComponent[Alpha](wCtx, removed)
+	assert.Check(t, errors.Is(err, cardinal.ErrEntityDoesNotExist))
+}
+
+func TestRemovingADeadEntityReturnsAnError(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 2, Alpha{Name1: "a"})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	search := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Alpha]()))
+	assert.NilError(t, cardinal.Remove(wCtx, ids[0]))
+	// Removing the entity again, in the same tick or in a later one, fails without panicking.
+	assert.Check(t, errors.Is(cardinal.Remove(wCtx, ids[0]), cardinal.ErrEntityDoesNotExist))
+	tf.DoTick()
+	assert.Check(t, errors.Is(cardinal.Remove(wCtx, ids[0]), cardinal.ErrEntityDoesNotExist))
+	assert.Check(t, errors.Is(cardinal.Remove(wCtx, types.EntityID(1000)), cardinal.ErrEntityDoesNotExist))
+
+	// The removed entity no longer matches searches, and the other entity is untouched.
+	found, err := search.Collect(wCtx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, found, []types.EntityID{ids[1]})
+}
+
+func TestRemoveManySkipsDeadEntities(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	alphas, err := cardinal.CreateMany(wCtx, 3, Alpha{})
+	assert.NilError(t, err)
+	betas, err := cardinal.CreateMany(wCtx, 2, Beta{})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.Remove(wCtx, alphas[1]))
+	tf.DoTick()
+
+	unknown := types.EntityID(1000)
+	count, err := cardinal.RemoveMany(wCtx, []types.EntityID{alphas[0], alphas[1], betas[1], unknown, alphas[0]})
+	assert.NilError(t, err)
+	assert.Equal(t, 2, count)
+	tf.DoTick()
+
+	for _, id := range []types.EntityID{alphas[0], alphas[1], betas[1]} {
+		assert.Check(t, !alive(t, world, id))
+	}
+	found, err := cardinal.NewSearch().Entity(filter.All()).Collect(wCtx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, found, []types.EntityID{alphas[2], betas[0]})
+}
+
+func BenchmarkRemoveMany(b *testing.B) {
+	const numEntities = 5000
+	removals := map[string]func(wCtx cardinal.WorldContext, ids []types.EntityID){
+		"loop": func(wCtx cardinal.WorldContext, ids []types.EntityID) {
+			for _, id := range ids {
+				assert.NilError(b, cardinal.Remove(wCtx, id))
+			}
+		},
+		"bulk": func(wCtx cardinal.WorldContext, ids []types.EntityID) {
+			count, err := cardinal.RemoveMany(wCtx, ids)
+			assert.NilError(b, err)
+			assert.Equal(b, len(ids), count)
+		},
+	}
+	for name, remove := range removals {
+		b.Run(name, func(b *testing.B) {
+			tf := cardinal.NewTestFixture(b, nil)
+			assert.NilError(b, cardinal.RegisterComponent[Alpha](tf.World))
+			tf.StartWorld()
+			wCtx := cardinal.NewWorldContext(tf.World)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				ids, err := cardinal.CreateMany(wCtx, numEntities, Alpha{})
+				assert.NilError(b, err)
+				tf.DoTick()
+				b.StartTimer()
+				remove(wCtx, ids)
+			}
+		})
+	}
+}
+
+func TestCreateManyEntitiesAreSearchable(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 1000, Alpha{Name1: "spawned"})
+	assert.NilError(t, err)
+	assert.Len(t, ids, 1000)
+	tf.DoTick()
+
+	found := map[types.EntityID]bool{}
+	err = cardinal.NewSearch().Entity(filter.Exact(filter.Component[Alpha]())).Each(wCtx,
+		func(id types.EntityID) bool {
+			found[id] = true
+			return true
+		},
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, len(found), len(ids))
+	for _, id := range ids {
+		assert.Check(t, found[id])
+		alpha, err := cardinal.GetComponent[Alpha](wCtx, id)
+		assert.NilError(t, err)
+		assert.Equal(t, alpha.Name1, "spawned")
+	}
+}
+
+func TestChangingArchetypePreservesComponentData(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	id, err := cardinal.Create(wCtx, Alpha{Name1: "alpha"})
+	assert.NilError(t, err)
+	archCount := world.GameStateManager().ArchetypeCount()
+	tf.DoTick()
+
+	// No entity has had both Alpha and Beta yet, so adding Beta creates a new archetype for the entity.
+	assert.NilError(t, cardinal.AddComponentTo[Beta](wCtx, id))
+	assert.Equal(t, world.GameStateManager().ArchetypeCount(), archCount+1)
+	assert.NilError(t, cardinal.SetComponent[Beta](wCtx, id, &Beta{Name1: "beta"}))
+	tf.DoTick()
+
+	alpha, err := cardinal.GetComponent[Alpha](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, alpha.Name1, "alpha")
+	beta, err := cardinal.GetComponent[Beta](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, beta.Name1, "beta")
+
+	// Removing a component moves the entity again and keeps the data of the remaining component.
+	assert.NilError(t, cardinal.RemoveComponentFrom[Alpha](wCtx, id))
+	tf.DoTick()
+	beta, err = cardinal.GetComponent[Beta](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, beta.Name1, "beta")
+	_, err = cardinal.GetComponent[Alpha](wCtx, id)
+	assert.ErrorIs(t, err, cardinal.ErrComponentNotOnEntity)
+}
+
+func TestTryGetComponentReturnsAnErrorForAnAbsentComponent(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	var id types.EntityID
+	assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+		var err error
+		id, err = cardinal.Create(wCtx, Alpha{Name1: "alpha"})
+		return err
+	}))
+	var systemErr error
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		alpha, err := cardinal.TryGetComponent[Alpha](wCtx, id)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, alpha.Name1, "alpha")
+		_, systemErr = cardinal.TryGetComponent[Beta](wCtx, id)
+		return nil
+	}))
+	tf.StartWorld()
+	tf.DoTick()
+
+	var notOnEntity *cardinal.ComponentNotOnEntityError
+	assert.Check(t, errors.As(systemErr, &notOnEntity))
+	assert.Equal(t, notOnEntity.ComponentName, Beta{}.Name())
+	assert.Equal(t, notOnEntity.EntityID, id)
+	assert.Check(t, errors.Is(systemErr, cardinal.ErrComponentNotOnEntity))
+
+	// Read-only contexts report the absent component in the same way.
+	_, err := cardinal.TryGetComponent[Beta](cardinal.NewReadOnlyWorldContext(world), id)
+	assert.Check(t, errors.As(err, &notOnEntity))
+}
+
+func TestGetComponentsReturnsAllTheComponentsOrAnError(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Location](world))
+	assert.NilError(t, cardinal.RegisterComponent[Player](world))
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	// Player has no exported fields, so only its presence is checked.
+	hero, err := cardinal.Create(wCtx, Location{X: 1, Y: 2}, Player{}, Health{Value: 10})
+	assert.NilError(t, err)
+	rock, err := cardinal.Create(wCtx, Location{X: 3, Y: 4})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	loc, _, err := cardinal.GetComponents2[Location, Player](wCtx, hero)
+	assert.NilError(t, err)
+	assert.Equal(t, loc, Location{X: 1, Y: 2})
+	loc, _, health, err := cardinal.GetComponents3[Location, Player, Health](wCtx, hero)
+	assert.NilError(t, err)
+	assert.Equal(t, loc, Location{X: 1, Y: 2})
+	assert.Equal(t, health.Value, 10)
+
+	_, _, err = cardinal.GetComponents2[Location, Player](wCtx, rock)
+	var notOnEntity *cardinal.ComponentNotOnEntityError
+	assert.Check(t, errors.As(err, &notOnEntity))
+	assert.Equal(t, notOnEntity.ComponentName, Player{}.Name())
+	assert.Equal(t, notOnEntity.EntityID, rock)
+}
+
+func TestEntityBuilderCreatesTheEntityInItsFinalArchetype(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Location](world))
+	assert.NilError(t, cardinal.RegisterComponent[Player](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	archCount := world.GameStateManager().ArchetypeCount()
+	id, err := cardinal.NewEntity(wCtx).With(Location{X: 1, Y: 1}).With(Player{}).With(Location{X: 2, Y: 3}).Build()
+	assert.NilError(t, err)
+
+	// Only the archetype of both components is created, as the entity never has just one of them.
+	assert.Equal(t, world.GameStateManager().ArchetypeCount(), archCount+1)
+	var archetypes []cardinal.Archetype
+	assert.NilError(t, cardinal.EachArchetype(wCtx,
+		filter.Exact(filter.Component[Location](), filter.Component[Player]()),
+		func(arch cardinal.Archetype) bool {
+			archetypes = append(archetypes, arch)
+			return true
+		}))
+	assert.Equal(t, len(archetypes), 1)
+	assert.DeepEqual(t, archetypes[0].Entities, []types.EntityID{id})
+	loc, err := cardinal.GetComponent[Location](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, *loc, Location{X: 2, Y: 3})
+
+	_, err = cardinal.NewEntity(wCtx).Build()
+	assert.ErrorIs(t, err, cardinal.ErrEntityMustHaveAtLeastOneComponent)
+}
+
 type EnergyComponentAlpha struct {
 	Amt int64
 	Cap int64
@@ -1,8 +1,11 @@
 package cardinal_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,9 +13,11 @@ import (
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
 	"pkg.world.dev/world-engine/cardinal/router/mocks"
 	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -20,7 +25,9 @@ var _ iterator.Iterator = (*FakeIterator)(nil)
 
 // FakeIterator mimics the behavior of a real transaction iterator for testing purposes.
 type FakeIterator struct {
-	objects []Iterable
+	objects   []Iterable
+	cursor    uint64
+	hasCursor bool
 }
 
 type Iterable struct {
@@ -38,16 +45,59 @@ func NewFakeIterator(collection []Iterable) *FakeIterator {
 // Each simulates iterating over transactions based on the provided ranges.
 // It directly invokes the provided function with mock data for testing.
 func (f *FakeIterator) Each(fn func(batch []*iterator.TxBatch, tick, timestamp uint64) error, _ ...uint64) error {
+	return f.EachWithProgress(fn, nil)
+}
+
+// EachCtx is like Each, but stops before the next tick once ctx is cancelled.
+func (f *FakeIterator) EachCtx(
+	ctx context.Context,
+	fn func(batch []*iterator.TxBatch, tick, timestamp uint64) error,
+	_ ...uint64,
+) error {
+	return f.EachWithProgress(func(batch []*iterator.TxBatch, tick, timestamp uint64) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(batch, tick, timestamp)
+	}, nil)
+}
+
+// EachWithProgress is like Each, and treats the whole collection as a single page.
+func (f *FakeIterator) EachWithProgress(
+	fn func(batch []*iterator.TxBatch, tick, timestamp uint64) error,
+	progressFn func(currentTick uint64, hasMore bool),
+	_ ...uint64,
+) error {
 	for _, val := range f.objects {
 		// Invoke the callback function with the current batch, tick, and timestamp.
 		if err := fn(val.Batches, val.Tick, val.Timestamp); err != nil {
 			return err
 		}
+		f.cursor = val.Tick
+		f.hasCursor = true
+	}
+	if progressFn != nil {
+		progressFn(f.cursor, false)
 	}
 
 	return nil
 }
 
+// Validate reports every tick and transaction of the collection as successfully decoded.
+func (f *FakeIterator) Validate(_ ...uint64) (iterator.ValidationReport, error) {
+	report := iterator.ValidationReport{}
+	for _, val := range f.objects {
+		report.Ticks++
+		report.Transactions += len(val.Batches)
+	}
+	return report, nil
+}
+
+// Cursor returns the last tick that was successfully processed.
+func (f *FakeIterator) Cursor() (uint64, bool) {
+	return f.cursor, f.hasCursor
+}
+
 func TestCanWaitForNextTick(t *testing.T) {
 	tf := cardinal.NewTestFixture(t, nil)
 	world := tf.World
@@ -100,7 +150,7 @@ func TestWaitForNextTickReturnsFalseWhenEngineIsShutDown(t *testing.T) {
 	// Shutdown the engine at some point in the near future
 	time.AfterFunc(
 		100*time.Millisecond, func() {
-			world.Shutdown()
+			assert.Check(t, world.Shutdown(context.Background()) == nil)
 		},
 	)
 	// testTimeout will cause the test to fail if we have to wait too long for a WaitForNextTick failure
@@ -302,6 +352,342 @@ func TestSystemExecutionOrder(t *testing.T) {
 	}
 }
 
+func TestSubmitBatchProcessesAllMessagesInOneTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	type countIn struct{ I int }
+	type countOut struct{}
+	assert.NilError(t, cardinal.RegisterMessage[countIn, countOut](world, "count"))
+
+	var seen []int
+	var ticks []uint64
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[countIn, countOut](wCtx, func(tx cardinal.TxData[countIn]) (countOut, error) {
+			seen = append(seen, tx.Msg.I)
+			ticks = append(ticks, wCtx.CurrentTick())
+			return countOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	// A batch with an unknown message is rejected as a whole.
+	_, err := world.SubmitBatch([]cardinal.PendingMessage{
+		{PersonaTag: "alice", MessageName: "count", Value: countIn{I: 0}},
+		{PersonaTag: "alice", MessageName: "missing", Value: countIn{I: 1}},
+	})
+	assert.ErrorContains(t, err, `message "missing" is not registered`)
+
+	// A batch with more messages than there are distinct salts is rejected as a whole, instead of dropping duplicates.
+	tooLarge := make([]cardinal.PendingMessage, cardinal.MaxBatchSize+1)
+	for i := range tooLarge {
+		tooLarge[i] = cardinal.PendingMessage{PersonaTag: "alice", MessageName: "count", Value: countIn{I: 0}}
+	}
+	_, err = world.SubmitBatch(tooLarge)
+	assert.ErrorContains(t, err, "exceeds the limit")
+
+	numOfMsgs := 1000
+	batch := make([]cardinal.PendingMessage, 0, numOfMsgs)
+	for i := 0; i < numOfMsgs; i++ {
+		batch = append(batch, cardinal.PendingMessage{PersonaTag: "alice", MessageName: "game.count", Value: countIn{I: i}})
+	}
+	hashes, err := world.SubmitBatch(batch)
+	assert.NilError(t, err)
+	assert.Equal(t, len(hashes), numOfMsgs)
+
+	tf.DoTick()
+
+	assert.Equal(t, len(seen), numOfMsgs)
+	for i := range seen {
+		assert.Equal(t, seen[i], i)
+		assert.Equal(t, ticks[i], ticks[0])
+	}
+}
+
+func TestPreTickHookMessagesAreProcessedInTheSameTick(t *testing.T) {
+	type spawnIn struct{ Tick uint64 }
+	type spawnOut struct{}
+	var hookTicks []uint64
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithPreTickHook(func(tick uint64) []cardinal.PendingMessage {
+		hookTicks = append(hookTicks, tick)
+		if tick != 1 {
+			return nil
+		}
+		return []cardinal.PendingMessage{{MessageName: "spawn", Value: spawnIn{Tick: tick}}}
+	}))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[spawnIn, spawnOut](world, "spawn"))
+
+	type observed struct {
+		MsgTick, SystemTick uint64
+		Hash                types.TxHash
+	}
+	var seen []observed
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[spawnIn, spawnOut](wCtx, func(tx cardinal.TxData[spawnIn]) (spawnOut, error) {
+			seen = append(seen, observed{tx.Msg.Tick, wCtx.CurrentTick(), tx.Hash})
+			return spawnOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	tf.DoTick()
+	tf.DoTick()
+	tf.DoTick()
+
+	assert.DeepEqual(t, hookTicks, []uint64{0, 1, 2})
+	assert.Equal(t, len(seen), 1)
+	assert.Equal(t, seen[0].MsgTick, uint64(1))
+	assert.Equal(t, seen[0].SystemTick, uint64(1))
+	rec, ok := world.ReceiptByTxHash(seen[0].Hash)
+	assert.Check(t, ok)
+	assert.Check(t, rec.Internal)
+	assert.Equal(t, len(rec.Errs), 0)
+}
+
+type timerIn struct{ ID int }
+type timerOut struct{}
+
+type firedTimer struct {
+	ID   int
+	Tick uint64
+}
+
+// newMessageFixture returns a started fixture with a message registered under the given name, and a system that passes
+// each of its messages to handle.
+func newMessageFixture[In, Out any](
+	t *testing.T,
+	name string,
+	handle func(wCtx cardinal.WorldContext, msg In) (Out, error),
+	opts ...cardinal.WorldOption,
+) *cardinal.TestFixture {
+	tf := cardinal.NewTestFixture(t, nil, opts...)
+	assert.NilError(t, cardinal.RegisterMessage[In, Out](tf.World, name))
+	assert.NilError(t, cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[In, Out](wCtx, func(tx cardinal.TxData[In]) (Out, error) {
+			return handle(wCtx, tx.Msg)
+		})
+	}))
+	tf.StartWorld()
+	return tf
+}
+
+func TestScheduledMessagesFireOnTheirTick(t *testing.T) {
+	var fired []firedTimer
+	tf := newMessageFixture(t, "timer", func(wCtx cardinal.WorldContext, msg timerIn) (timerOut, error) {
+		fired = append(fired, firedTimer{ID: msg.ID, Tick: wCtx.CurrentTick()})
+		return timerOut{}, nil
+	})
+	wCtx := cardinal.NewWorldContext(tf.World)
+
+	timer := func(id int) cardinal.PendingMessage {
+		return cardinal.PendingMessage{MessageName: "timer", Value: timerIn{ID: id}}
+	}
+
+	assert.NilError(t, cardinal.ScheduleMessage(wCtx, 3, timer(1)))
+	assert.NilError(t, cardinal.ScheduleMessage(wCtx, 0, timer(2)))
+	err := cardinal.ScheduleMessage(wCtx, 0, cardinal.PendingMessage{MessageName: "missing", Value: timerIn{}})
+	assert.ErrorContains(t, err, `message "missing" is not registered`)
+
+	for i := 0; i < 6; i++ {
+		tf.DoTick()
+	}
+
+	assert.DeepEqual(t, fired, []firedTimer{{ID: 2, Tick: 0}, {ID: 1, Tick: 3}})
+}
+
+func TestMessagesScheduledFromASystemFireAfterTheRunningTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterMessage[timerIn, timerOut](tf.World, "timer"))
+	var fired []firedTimer
+	assert.NilError(t, cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+		if wCtx.CurrentTick() == 1 {
+			msg := cardinal.PendingMessage{MessageName: "timer", Value: timerIn{ID: 1}}
+			if err := cardinal.ScheduleMessage(wCtx, 0, msg); err != nil {
+				return err
+			}
+		}
+		return cardinal.EachMessage[timerIn, timerOut](wCtx, func(tx cardinal.TxData[timerIn]) (timerOut, error) {
+			fired = append(fired, firedTimer{ID: tx.Msg.ID, Tick: wCtx.CurrentTick()})
+			return timerOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	for i := 0; i < 4; i++ {
+		tf.DoTick()
+	}
+
+	assert.DeepEqual(t, fired, []firedTimer{{ID: 1, Tick: 2}})
+}
+
+func TestScheduledMessagesFireDespiteAnInvalidPreTickHookMessage(t *testing.T) {
+	invalid := cardinal.WithPreTickHook(func(uint64) []cardinal.PendingMessage {
+		return []cardinal.PendingMessage{{MessageName: "missing", Value: timerIn{}}}
+	})
+	var fired []firedTimer
+	tf := newMessageFixture(t, "timer", func(wCtx cardinal.WorldContext, msg timerIn) (timerOut, error) {
+		fired = append(fired, firedTimer{ID: msg.ID, Tick: wCtx.CurrentTick()})
+		return timerOut{}, nil
+	}, invalid)
+
+	wCtx := cardinal.NewWorldContext(tf.World)
+	for id := 1; id <= 2; id++ {
+		assert.NilError(t, cardinal.ScheduleMessage(wCtx, 1, cardinal.PendingMessage{
+			MessageName: "timer",
+			Value:       timerIn{ID: id},
+		}))
+	}
+	for i := 0; i < 3; i++ {
+		tf.DoTick()
+	}
+
+	assert.DeepEqual(t, fired, []firedTimer{{ID: 1, Tick: 1}, {ID: 2, Tick: 1}})
+}
+
+func TestScheduledMessagesSurviveSnapshotAndRestore(t *testing.T) {
+	var srcFired, dstFired []firedTimer
+	src := newMessageFixture(t, "timer", func(wCtx cardinal.WorldContext, msg timerIn) (timerOut, error) {
+		srcFired = append(srcFired, firedTimer{ID: msg.ID, Tick: wCtx.CurrentTick()})
+		return timerOut{}, nil
+	})
+	wCtx := cardinal.NewWorldContext(src.World)
+	msg := cardinal.PendingMessage{MessageName: "timer", Value: timerIn{ID: 7}}
+	assert.NilError(t, cardinal.ScheduleMessage(wCtx, 3, msg))
+	src.DoTick()
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	assert.Equal(t, len(srcFired), 0)
+
+	dst := newMessageFixture(t, "timer", func(wCtx cardinal.WorldContext, msg timerIn) (timerOut, error) {
+		dstFired = append(dstFired, firedTimer{ID: msg.ID, Tick: wCtx.CurrentTick()})
+		return timerOut{}, nil
+	})
+	assert.NilError(t, dst.World.Restore(data))
+	for i := 0; i < 5; i++ {
+		dst.DoTick()
+	}
+
+	assert.DeepEqual(t, dstFired, []firedTimer{{ID: 7, Tick: 3}})
+}
+
+func TestReceiptByTxHash(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	type doubleIn struct{ I int }
+	type doubleOut struct{ I int }
+	assert.NilError(t, cardinal.RegisterMessage[doubleIn, doubleOut](world, "double"))
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[doubleIn, doubleOut](wCtx, func(tx cardinal.TxData[doubleIn]) (doubleOut, error) {
+			return doubleOut{I: tx.Msg.I * 2}, nil
+		})
+	}))
+	tf.StartWorld()
+	doubleMsg, ok := world.GetMessageByFullName("game.double")
+	assert.Check(t, ok)
+
+	signedHash := tf.AddTransaction(doubleMsg.ID(), doubleIn{I: 2}, &sign.Transaction{PersonaTag: "alice"})
+	batchHashes, err := world.SubmitBatch([]cardinal.PendingMessage{
+		{PersonaTag: "alice", MessageName: "double", Value: doubleIn{I: 5}},
+	})
+	assert.NilError(t, err)
+	tf.DoTick()
+	tf.DoTick()
+
+	rec, ok := world.ReceiptByTxHash(signedHash)
+	assert.Check(t, ok)
+	assert.Equal(t, rec.TxHash, signedHash)
+	assert.Equal(t, rec.Result, doubleOut{I: 4})
+
+	rec, ok = world.ReceiptByTxHash(batchHashes[0])
+	assert.Check(t, ok)
+	assert.Equal(t, rec.Result, doubleOut{I: 10})
+
+	_, ok = world.ReceiptByTxHash("unknown")
+	assert.Check(t, !ok)
+}
+
+type failIn struct{ V int }
+type failOut struct{ V int }
+
+// checkPositive returns an error for negative values and panics for zero.
+func checkPositive(v int) error {
+	switch {
+	case v < 0:
+		return errors.New("value must not be negative")
+	case v == 0:
+		panic("value must not be zero")
+	}
+	return nil
+}
+
+func TestReceiptOfAFailedMessageHasTheErrorAndItsStackTrace(t *testing.T) {
+	var processed []int
+	tf := newMessageFixture(t, "fail", func(_ cardinal.WorldContext, msg failIn) (failOut, error) {
+		if err := checkPositive(msg.V); err != nil {
+			return failOut{}, err
+		}
+		processed = append(processed, msg.V)
+		return failOut{V: msg.V}, nil
+	})
+	hashes, err := tf.World.SubmitBatch([]cardinal.PendingMessage{
+		{MessageName: "fail", Value: failIn{V: -1}},
+		{MessageName: "fail", Value: failIn{V: 1}},
+	})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	assert.DeepEqual(t, processed, []int{1})
+	rec, ok := tf.World.ReceiptByTxHash(hashes[0])
+	assert.Check(t, ok)
+	assert.Equal(t, len(rec.Errs), 1)
+	assert.ErrorContains(t, rec.Errs[0], "value must not be negative")
+
+	bz, err := rec.MarshalJSON()
+	assert.NilError(t, err)
+	var decoded struct {
+		Errors      []string `json:"errors"`
+		StackTraces []string `json:"stackTraces"`
+	}
+	assert.NilError(t, json.Unmarshal(bz, &decoded))
+	assert.DeepEqual(t, decoded.Errors, []string{"value must not be negative"})
+	assert.Equal(t, len(decoded.StackTraces), 1)
+	assert.Check(t, strings.Contains(decoded.StackTraces[0], "value must not be negative"))
+	assert.Check(t, strings.Contains(decoded.StackTraces[0], "message.go"))
+}
+
+func TestPanickingMessageHandlerIsTurnedIntoAnErrorReceipt(t *testing.T) {
+	var processed []int
+	tf := newMessageFixture(t, "fail", func(_ cardinal.WorldContext, msg failIn) (failOut, error) {
+		if err := checkPositive(msg.V); err != nil {
+			return failOut{}, err
+		}
+		processed = append(processed, msg.V)
+		return failOut{V: msg.V}, nil
+	})
+	hashes, err := tf.World.SubmitBatch([]cardinal.PendingMessage{
+		{MessageName: "fail", Value: failIn{V: 0}},
+		{MessageName: "fail", Value: failIn{V: 2}},
+	})
+	assert.NilError(t, err)
+	tick := tf.World.CurrentTick()
+	tf.DoTick()
+
+	// The tick completed, and the other message was still processed.
+	assert.Equal(t, tf.World.CurrentTick(), tick+1)
+	assert.DeepEqual(t, processed, []int{2})
+	rec, ok := tf.World.ReceiptByTxHash(hashes[0])
+	assert.Check(t, ok)
+	assert.Equal(t, len(rec.Errs), 1)
+	assert.ErrorContains(t, rec.Errs[0], "message handler panicked: value must not be zero")
+	traces := receipt.ErrorStackTraces(rec.Errs)
+	assert.Equal(t, len(traces), 1)
+	// The stack trace leads to the function that panicked.
+	assert.Check(t, strings.Contains(traces[0], "checkPositive"))
+
+	tf.DoTick()
+	assert.Equal(t, tf.World.CurrentTick(), tick+2)
+}
+
 func TestSetNamespace(t *testing.T) {
 	namespace := "test"
 	t.Setenv("CARDINAL_NAMESPACE", namespace)
@@ -376,7 +762,8 @@ func TestTransactionsSentToRouterAfterTick(t *testing.T) {
 	tx := &sign.Transaction{PersonaTag: "ty"}
 	fooMessage, ok := world.GetMessageByFullName("game." + msgName)
 	assert.True(t, ok)
-	_, txHash := world.AddEVMTransaction(fooMessage.ID(), msg, tx, evmTxHash)
+	_, txHash, err := world.AddEVMTransaction(fooMessage.ID(), msg, tx, evmTxHash)
+	assert.NilError(t, err)
 
 	rtr.
 		EXPECT().
@@ -498,3 +885,392 @@ func TestRecoverFromChain(t *testing.T) {
 	// World should be ready for tick 16
 	assert.Equal(t, world.CurrentTick(), uint64(16))
 }
+
+type queuedIn struct{ X int }
+type queuedOut struct{}
+
+func TestMessagesAreRejectedOnceTheQueueIsFull(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithMaxQueuedMessages(2))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	tf.StartWorld()
+
+	for i := 0; i < 2; i++ {
+		_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: i}, &sign.Transaction{PersonaTag: strconv.Itoa(i)})
+		assert.NilError(t, err)
+	}
+	_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 2}, &sign.Transaction{PersonaTag: "2"})
+	assert.ErrorIs(t, err, cardinal.ErrMessageQueueFull)
+	_, err = world.SubmitBatch([]cardinal.PendingMessage{{PersonaTag: "3", MessageName: "foo", Value: queuedIn{X: 3}}})
+	assert.ErrorIs(t, err, cardinal.ErrMessageQueueFull)
+
+	// The tick takes the queued messages, which makes room for new ones.
+	tf.DoTick()
+	_, _, err = world.AddTransaction(fooMsg.ID(), queuedIn{X: 4}, &sign.Transaction{PersonaTag: "4"})
+	assert.NilError(t, err)
+}
+
+func TestMessagesWaitForRoomWhenTheQueueBlocks(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithMaxQueuedMessages(1), cardinal.WithBlockWhenQueueFull())
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	tf.StartWorld()
+
+	_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 0}, &sign.Transaction{PersonaTag: "0"})
+	assert.NilError(t, err)
+
+	added := make(chan error, 1)
+	go func() {
+		_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 1}, &sign.Transaction{PersonaTag: "1"})
+		added <- err
+	}()
+	select {
+	case <-added:
+		t.Fatal("message was added to a full queue")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	tf.DoTick()
+	select {
+	case err := <-added:
+		assert.NilError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not added after the tick made room for it")
+	}
+}
+
+func TestShutdownRejectsMessagesWaitingForRoomInAFullQueue(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithMaxQueuedMessages(1), cardinal.WithBlockWhenQueueFull())
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	tf.StartWorld()
+
+	_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 0}, &sign.Transaction{PersonaTag: "0"})
+	assert.NilError(t, err)
+
+	added := make(chan error, 1)
+	go func() {
+		_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 1}, &sign.Transaction{PersonaTag: "1"})
+		added <- err
+	}()
+
+	assert.NilError(t, world.Shutdown(context.Background()))
+	select {
+	case err := <-added:
+		assert.Check(t, errors.Is(err, cardinal.ErrMessageQueueClosed))
+	case <-time.After(5 * time.Second):
+		t.Fatal("message waiting for room was not rejected when the world shut down")
+	}
+
+	_, _, err = world.AddTransaction(fooMsg.ID(), queuedIn{X: 2}, &sign.Transaction{PersonaTag: "2"})
+	assert.Check(t, errors.Is(err, cardinal.ErrMessageQueueClosed))
+}
+
+func TestTxDedupDropsTransactionsThatWereAlreadyProcessed(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithTxDedup(10))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	processed := 0
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[queuedIn, queuedOut](wCtx, func(cardinal.TxData[queuedIn]) (queuedOut, error) {
+			processed++
+			return queuedOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	tx := &sign.Transaction{PersonaTag: "alice"}
+	firstTick, hash, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 1}, tx)
+	assert.NilError(t, err)
+	tf.DoTick()
+	secondTick, dupHash, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 1}, tx)
+	assert.NilError(t, err)
+	assert.Equal(t, dupHash, hash)
+	tf.DoTick()
+
+	assert.Equal(t, processed, 1)
+	receipts, err := world.GetTransactionReceiptsForTick(firstTick)
+	assert.NilError(t, err)
+	assert.Equal(t, len(receipts), 1)
+	assert.Check(t, !receipts[0].Duplicate)
+	receipts, err = world.GetTransactionReceiptsForTick(secondTick)
+	assert.NilError(t, err)
+	assert.Equal(t, len(receipts), 1)
+	assert.Equal(t, receipts[0].TxHash, hash)
+	assert.Check(t, receipts[0].Duplicate)
+}
+
+func TestMessagesOverTheTickBudgetAreDeferredToTheNextTick(t *testing.T) {
+	// The budget is exceeded as soon as the first message of each tick is processed.
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithTickBudget(time.Nanosecond))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	var processed []int
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[queuedIn, queuedOut](wCtx, func(tx cardinal.TxData[queuedIn]) (queuedOut, error) {
+			processed = append(processed, tx.Msg.X)
+			return queuedOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	const numMsgs = 5
+	var firstTick uint64
+	var hashes []types.TxHash
+	for i := uint16(0); i < numMsgs; i++ {
+		// The salt gives the otherwise identical transactions distinct hashes.
+		tick, hash, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: int(i)},
+			&sign.Transaction{PersonaTag: "alice", Salt: i})
+		assert.NilError(t, err)
+		firstTick = tick
+		hashes = append(hashes, hash)
+	}
+	firstHash := hashes[0]
+	tf.DoTick()
+
+	assert.DeepEqual(t, processed, []int{0})
+	receipts, err := world.GetTransactionReceiptsForTick(firstTick)
+	assert.NilError(t, err)
+	assert.Equal(t, len(receipts), numMsgs)
+	deferred := 0
+	for _, rec := range receipts {
+		if rec.Deferred {
+			deferred++
+			assert.Check(t, rec.Result == nil)
+		} else {
+			assert.Equal(t, rec.TxHash, firstHash)
+		}
+	}
+	assert.Equal(t, deferred, numMsgs-1)
+
+	// A message submitted after the others were deferred is processed after them.
+	_, lateHash, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: numMsgs}, &sign.Transaction{PersonaTag: "bob"})
+	assert.NilError(t, err)
+	hashes = append(hashes, lateHash)
+	for i := 0; i < numMsgs; i++ {
+		tf.DoTick()
+	}
+	assert.DeepEqual(t, processed, []int{0, 1, 2, 3, 4, 5})
+	for _, hash := range hashes {
+		rec, ok := world.ReceiptByTxHash(hash)
+		assert.True(t, ok)
+		assert.Check(t, !rec.Deferred)
+		assert.Equal(t, rec.Result, queuedOut{})
+	}
+}
+
+func TestDeferredMessagesSurviveSnapshotAndRestore(t *testing.T) {
+	// The tick budget is exceeded as soon as the first message of each tick is processed.
+	var srcProcessed, dstProcessed []int
+	src := newMessageFixture(t, "foo", func(_ cardinal.WorldContext, msg queuedIn) (queuedOut, error) {
+		srcProcessed = append(srcProcessed, msg.X)
+		return queuedOut{}, nil
+	}, cardinal.WithTickBudget(time.Nanosecond))
+	hashes, err := src.World.SubmitBatch([]cardinal.PendingMessage{
+		{PersonaTag: "alice", MessageName: "foo", Value: queuedIn{X: 0}},
+		{PersonaTag: "alice", MessageName: "foo", Value: queuedIn{X: 1}},
+		{PersonaTag: "alice", MessageName: "foo", Value: queuedIn{X: 2}},
+	})
+	assert.NilError(t, err)
+	src.DoTick()
+	assert.DeepEqual(t, srcProcessed, []int{0})
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+
+	dst := newMessageFixture(t, "foo", func(_ cardinal.WorldContext, msg queuedIn) (queuedOut, error) {
+		dstProcessed = append(dstProcessed, msg.X)
+		return queuedOut{}, nil
+	}, cardinal.WithTickBudget(time.Nanosecond))
+	assert.NilError(t, dst.World.Restore(data))
+	for i := 0; i < 3; i++ {
+		dst.DoTick()
+	}
+	assert.DeepEqual(t, dstProcessed, []int{1, 2})
+	// The deferred messages keep the hashes they were submitted with.
+	for _, hash := range hashes[1:] {
+		rec, ok := dst.World.ReceiptByTxHash(hash)
+		assert.True(t, ok)
+		assert.Equal(t, rec.Result, queuedOut{})
+	}
+}
+
+func TestShutdownProcessesDeferredMessages(t *testing.T) {
+	var processed []int
+	tf := newMessageFixture(t, "foo", func(_ cardinal.WorldContext, msg queuedIn) (queuedOut, error) {
+		processed = append(processed, msg.X)
+		return queuedOut{}, nil
+	}, cardinal.WithTickBudget(time.Nanosecond))
+	_, err := tf.World.SubmitBatch([]cardinal.PendingMessage{
+		{PersonaTag: "alice", MessageName: "foo", Value: queuedIn{X: 0}},
+		{PersonaTag: "alice", MessageName: "foo", Value: queuedIn{X: 1}},
+		{PersonaTag: "alice", MessageName: "foo", Value: queuedIn{X: 2}},
+	})
+	assert.NilError(t, err)
+	tf.DoTick()
+	assert.DeepEqual(t, processed, []int{0})
+
+	// The final tick run while shutting down processes all of the deferred messages.
+	assert.NilError(t, tf.World.Shutdown(context.Background()))
+	assert.DeepEqual(t, processed, []int{0, 1, 2})
+}
+
+func TestSystemCanRejectMessagesBasedOnTheirSignature(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	const trustedSignature = "aa11"
+	errUntrustedSignature := errors.New("untrusted signature")
+	var accepted []string
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[queuedIn, queuedOut](wCtx, func(tx cardinal.TxData[queuedIn]) (queuedOut, error) {
+			if tx.Signature() != trustedSignature {
+				return queuedOut{}, errUntrustedSignature
+			}
+			accepted = append(accepted, tx.PersonaTag())
+			return queuedOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	tick, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 1},
+		&sign.Transaction{PersonaTag: "alice", Signature: trustedSignature})
+	assert.NilError(t, err)
+	_, rejectedHash, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 2},
+		&sign.Transaction{PersonaTag: "mallory", Signature: "bb22"})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	assert.DeepEqual(t, accepted, []string{"alice"})
+	receipts, err := world.GetTransactionReceiptsForTick(tick)
+	assert.NilError(t, err)
+	assert.Equal(t, len(receipts), 2)
+	for _, rec := range receipts {
+		if rec.TxHash == rejectedHash {
+			assert.Equal(t, len(rec.Errs), 1)
+			assert.ErrorIs(t, rec.Errs[0], errUntrustedSignature)
+		} else {
+			assert.Equal(t, len(rec.Errs), 0)
+		}
+	}
+}
+
+func TestSignatureVerifierRejectsTransactionsBeforeTheyReachSystems(t *testing.T) {
+	const forgedSignature = "bb22"
+	errForged := errors.New("forged signature")
+	verifier := func(tx cardinal.SignedTx) error {
+		if tx.Signature == forgedSignature || tx.Signature == "" {
+			return errForged
+		}
+		return nil
+	}
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithSignatureVerifier(verifier))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	var processed []string
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		return cardinal.EachMessage[queuedIn, queuedOut](wCtx, func(tx cardinal.TxData[queuedIn]) (queuedOut, error) {
+			processed = append(processed, tx.PersonaTag())
+			return queuedOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: 1},
+		&sign.Transaction{PersonaTag: "alice", Signature: "aa11"})
+	assert.NilError(t, err)
+	// The forged transaction is rejected before it is queued.
+	_, _, err = world.AddTransaction(fooMsg.ID(), queuedIn{X: 2},
+		&sign.Transaction{PersonaTag: "mallory", Signature: forgedSignature})
+	assert.Check(t, errors.Is(err, cardinal.ErrSignatureRejected))
+	assert.Check(t, errors.Is(err, errForged))
+	// Unsigned messages queued in-process are not verified.
+	_, err = world.SubmitBatch([]cardinal.PendingMessage{
+		{PersonaTag: "bob", MessageName: "foo", Value: queuedIn{X: 3}},
+	})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	assert.DeepEqual(t, processed, []string{"alice", "bob"})
+}
+
+func TestNonceProtection(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithNonceProtection())
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	tf.StartWorld()
+	addWithNonce := func(personaTag string, nonce uint64) error {
+		_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: int(nonce)},
+			&sign.Transaction{PersonaTag: personaTag, Nonce: nonce})
+		return err
+	}
+
+	t.Run("in order nonces are accepted", func(t *testing.T) {
+		assert.NilError(t, addWithNonce("alice", 1))
+		assert.NilError(t, addWithNonce("alice", 2))
+		assert.NilError(t, addWithNonce("alice", 5))
+		// Each persona tag has its own nonces.
+		assert.NilError(t, addWithNonce("bob", 1))
+	})
+
+	t.Run("out of order nonces are rejected", func(t *testing.T) {
+		assert.NilError(t, addWithNonce("carol", 3))
+		assert.ErrorIs(t, addWithNonce("carol", 2), cardinal.ErrNonceNotIncreasing)
+		// A missing nonce is rejected as well, as nonces start at 1.
+		assert.ErrorIs(t, addWithNonce("dave", 0), cardinal.ErrNonceNotIncreasing)
+	})
+
+	t.Run("duplicate nonces are rejected", func(t *testing.T) {
+		assert.NilError(t, addWithNonce("erin", 1))
+		tf.DoTick()
+		assert.ErrorIs(t, addWithNonce("erin", 1), cardinal.ErrNonceNotIncreasing)
+	})
+}
+
+func TestRejectedTransactionsDoNotUseUpTheirNonce(t *testing.T) {
+	verifier := func(tx cardinal.SignedTx) error {
+		if tx.Signature == "" {
+			return errors.New("missing signature")
+		}
+		return nil
+	}
+	tf := cardinal.NewTestFixture(t, nil,
+		cardinal.WithNonceProtection(),
+		cardinal.WithSignatureVerifier(verifier),
+		cardinal.WithMaxQueuedMessages(1),
+	)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterMessage[queuedIn, queuedOut](world, "foo"))
+	fooMsg, ok := world.GetMessageByFullName("game.foo")
+	assert.True(t, ok)
+	tf.StartWorld()
+	add := func(personaTag, signature string, nonce uint64) error {
+		_, _, err := world.AddTransaction(fooMsg.ID(), queuedIn{X: int(nonce)},
+			&sign.Transaction{PersonaTag: personaTag, Signature: signature, Nonce: nonce})
+		return err
+	}
+
+	// A forged transaction with a huge nonce does not lock the persona tag out.
+	assert.Check(t, errors.Is(add("alice", "", 1_000_000), cardinal.ErrSignatureRejected))
+	assert.NilError(t, add("alice", "aa11", 1))
+
+	// A transaction that does not fit in the queue can be submitted again with the same nonce.
+	assert.Check(t, errors.Is(add("bob", "bb22", 1), cardinal.ErrMessageQueueFull))
+	tf.DoTick()
+	assert.NilError(t, add("bob", "bb22", 1))
+}
@@ -0,0 +1,44 @@
+package cardinal
+
+import (
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// EntityBuilder collects the components of an entity to create it with all of them at once. See NewEntity.
+type EntityBuilder struct {
+	wCtx       WorldContext
+	components []types.Component
+}
+
+// NewEntity starts building an entity, whose components are added with With and which is created with Build:
+//
+//	id, err := cardinal.NewEntity(wCtx).With(Location{}).With(Player{}).Build()
+//
+// The entity is created directly in the archetype of all of its components, like with Create, instead of being moved
+// from one archetype to the next as its components are added one by one with AddComponentTo.
+func NewEntity(wCtx WorldContext) *EntityBuilder {
+	return &EntityBuilder{wCtx: wCtx, components: nil}
+}
+
+// With adds a component to the entity. Adding a component of a type that was already added replaces its value.
+func (b *EntityBuilder) With(comp types.Component) *EntityBuilder {
+	for i, c := range b.components {
+		if c.Name() == comp.Name() {
+			b.components[i] = comp
+			return b
+		}
+	}
+	b.components = append(b.components, comp)
+	return b
+}
+
+// Build creates the entity with all of its components, and returns its ID. If no component was added,
+// ErrEntityMustHaveAtLeastOneComponent is returned.
+func (b *EntityBuilder) Build() (types.EntityID, error) {
+	if len(b.components) == 0 {
+		return 0, eris.Wrap(ErrEntityMustHaveAtLeastOneComponent, "failed to build entity")
+	}
+	return Create(b.wCtx, b.components...)
+}
@@ -14,3 +14,7 @@ func All() ComponentFilter {
 func (f *all) MatchesComponents(_ []types.Component) bool {
 	return true
 }
+
+func (f *all) Key() string {
+	return "all"
+}
@@ -8,6 +8,7 @@ type and struct {
 	filters []ComponentFilter
 }
 
+// And matches archetypes that match all the given filters.
 func And(filters ...ComponentFilter) ComponentFilter {
 	return &and{filters: filters}
 }
@@ -20,3 +21,11 @@ func (f *and) MatchesComponents(components []types.Component) bool {
 	}
 	return true
 }
+
+func (f *and) Key() string {
+	keys := make([]string, 0, len(f.filters))
+	for _, filter := range f.filters {
+		keys = append(keys, Key(filter))
+	}
+	return setKey("and", keys)
+}
@@ -26,3 +26,7 @@ func (f *contains) MatchesComponents(components []types.Component) bool {
 	}
 	return true
 }
+
+func (f *contains) Key() string {
+	return componentsKey("contains", f.components)
+}
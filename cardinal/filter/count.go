@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"fmt"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type componentCount struct {
+	minCount int
+	maxCount int
+}
+
+// ComponentCount matches archetypes that have at least minCount and at most maxCount components. A negative maxCount
+// means there is no upper bound, so ComponentCount(n, n) matches entities with exactly n components and
+// ComponentCount(n, -1) matches entities with at least n components.
+func ComponentCount(minCount, maxCount int) ComponentFilter {
+	return componentCount{
+		minCount: minCount,
+		maxCount: maxCount,
+	}
+}
+
+func (f componentCount) MatchesComponents(components []types.Component) bool {
+	if len(components) < f.minCount {
+		return false
+	}
+	return f.maxCount < 0 || len(components) <= f.maxCount
+}
+
+func (f componentCount) Key() string {
+	return fmt.Sprintf("count(%d,%d)", f.minCount, max(f.maxCount, -1))
+}
@@ -31,3 +31,7 @@ func (f exact) MatchesComponents(components []types.Component) bool {
 	}
 	return true
 }
+
+func (f exact) Key() string {
+	return componentsKey("exact", f.components)
+}
@@ -1,6 +1,10 @@
 package filter
 
 import (
+	"slices"
+	"strconv"
+	"strings"
+
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
@@ -10,6 +14,46 @@ type ComponentFilter interface {
 	MatchesComponents(components []types.Component) bool
 }
 
+// KeyedFilter is a ComponentFilter with a canonical key. Filters that match the same archetypes have the same key, so
+// searches with such filters can share the archetypes they matched. All filters of this package are keyed filters.
+type KeyedFilter interface {
+	ComponentFilter
+	// Key returns the canonical key of the filter, or an empty string if the filter has none.
+	Key() string
+}
+
+// Key returns the canonical key of the given filter, or an empty string if the filter is not a KeyedFilter or has no
+// key.
+func Key(f ComponentFilter) string {
+	keyed, ok := f.(KeyedFilter)
+	if !ok {
+		return ""
+	}
+	return keyed.Key()
+}
+
+// componentsKey returns the key of a filter over the given set of components. The order of the components does not
+// matter.
+func componentsKey(name string, components []types.Component) string {
+	names := make([]string, 0, len(components))
+	for _, c := range components {
+		names = append(names, strconv.Quote(c.Name()))
+	}
+	return setKey(name, names)
+}
+
+// setKey returns the key of a filter that combines the filters with the given keys, regardless of their order. An
+// empty string is returned if any of the keys is empty.
+func setKey(name string, keys []string) string {
+	if slices.Contains(keys, "") {
+		return ""
+	}
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	return name + "(" + strings.Join(keys, ",") + ")"
+}
+
 // ComponentWrapper wraps a Component type for filtering purposes.
 type ComponentWrapper struct {
 	Component types.Component
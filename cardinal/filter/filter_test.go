@@ -356,3 +356,234 @@ func helperArchetypeFilter(b *testing.B, relevantCount, ignoreCount int) {
 		assert.Equal(b, count, relevantCount)
 	}
 }
+
+func TestOrFilterReturnsUnionOfDisjointArchetypes(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	assert.NilError(t, cardinal.RegisterComponent[Gamma](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 10, Alpha{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 20, Beta{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 30, Gamma{})
+	assert.NilError(t, err)
+
+	q := cardinal.NewSearch().Entity(filter.Or(
+		filter.Contains(filter.Component[Alpha]()),
+		filter.Contains(filter.Component[Beta]()),
+	))
+	count, err := q.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 30)
+
+	// Archetypes created after the search was first evaluated are still picked up by the search's cache.
+	_, err = cardinal.CreateMany(wCtx, 5, Alpha{}, Gamma{})
+	assert.NilError(t, err)
+	count, err = q.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 35)
+}
+
+func TestNotFilterComposesWithAnd(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	assert.NilError(t, cardinal.RegisterComponent[Gamma](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 10, Alpha{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 20, Alpha{}, Beta{})
+	assert.NilError(t, err)
+
+	// Entities that have alpha but not beta.
+	q := cardinal.NewSearch().Entity(filter.And(
+		filter.Contains(filter.Component[Alpha]()),
+		filter.Not(filter.Contains(filter.Component[Beta]())),
+	))
+	count, err := q.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 10)
+
+	// A newly created archetype that matches the negated filter is picked up incrementally.
+	_, err = cardinal.CreateMany(wCtx, 5, Alpha{}, Gamma{})
+	assert.NilError(t, err)
+	count, err = q.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 15)
+
+	// Not(Exact) excludes only the archetype with exactly the given components.
+	count, err = cardinal.NewSearch().Entity(filter.Not(filter.Exact(filter.Component[Alpha]()))).Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 25)
+}
+
+func TestComponentCountMatchesArchetypesByLayoutSize(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	assert.NilError(t, cardinal.RegisterComponent[Gamma](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 1, Alpha{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 2, Beta{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 10, Alpha{}, Beta{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 100, Alpha{}, Beta{}, Gamma{})
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name     string
+		min, max int
+		want     int
+	}{
+		{name: "exactly one", min: 1, max: 1, want: 3},
+		{name: "exactly two", min: 2, max: 2, want: 10},
+		{name: "between one and two", min: 1, max: 2, want: 13},
+		{name: "at least two", min: 2, max: -1, want: 110},
+		{name: "unbounded", min: 0, max: -1, want: 113},
+		{name: "more than any archetype", min: 4, max: -1, want: 0},
+		{name: "empty range", min: 3, max: 2, want: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := cardinal.NewSearch().Entity(filter.ComponentCount(tc.min, tc.max)).Count(wCtx)
+			assert.NilError(t, err)
+			assert.Equal(t, tc.want, count)
+		})
+	}
+}
+
+func TestKeyIsCanonicalForEquivalentFilters(t *testing.T) {
+	alpha, beta := filter.Component[Alpha](), filter.Component[Beta]()
+
+	assert.Equal(t, filter.Key(filter.Contains(alpha, beta)), filter.Key(filter.Contains(beta, alpha)))
+	assert.Equal(t, filter.Key(filter.Exact(alpha, beta)), filter.Key(filter.Exact(beta, alpha)))
+	assert.Equal(t,
+		filter.Key(filter.Or(filter.Contains(alpha), filter.Not(filter.Exact(beta)))),
+		filter.Key(filter.Or(filter.Not(filter.Exact(beta)), filter.Contains(alpha))),
+	)
+	assert.Equal(t, filter.Key(filter.ComponentCount(1, -1)), filter.Key(filter.ComponentCount(1, -5)))
+
+	assert.Check(t, filter.Key(filter.Contains(alpha)) != filter.Key(filter.Exact(alpha)))
+	assert.Check(t, filter.Key(filter.Contains(alpha)) != filter.Key(filter.Contains(alpha, beta)))
+	assert.Check(t, filter.Key(filter.And(filter.Contains(alpha))) != filter.Key(filter.Or(filter.Contains(alpha))))
+
+	// Filters made from filters without a key have no key either.
+	assert.Equal(t, "", filter.Key(unkeyed{}))
+	assert.Equal(t, "", filter.Key(filter.Not(unkeyed{})))
+	assert.Equal(t, "", filter.Key(filter.And(filter.Contains(alpha), unkeyed{})))
+}
+
+type unkeyed struct{}
+
+func (unkeyed) MatchesComponents([]types.Component) bool { return true }
+
+func TestSearchesWithTheSameFilterKeySeeNewArchetypes(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](world))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](world))
+	assert.NilError(t, cardinal.RegisterComponent[Gamma](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 10, Alpha{})
+	assert.NilError(t, err)
+
+	first := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Alpha]()))
+	count, err := first.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 10)
+
+	// A search made after a new archetype was created picks up the new archetype from the shared matches, and so does
+	// the search that was evaluated before it was created.
+	_, err = cardinal.CreateMany(wCtx, 5, Alpha{}, Beta{})
+	assert.NilError(t, err)
+	second := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Alpha]()))
+	count, err = second.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 15)
+
+	_, err = cardinal.CreateMany(wCtx, 1, Alpha{}, Gamma{})
+	assert.NilError(t, err)
+	count, err = first.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 16)
+	count, err = second.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 16)
+}
+
+// countingFilter counts the archetypes that are scanned by searches with the filter. It has no key, so every search
+// with it scans the archetypes itself.
+type countingFilter struct {
+	filter.ComponentFilter
+	scans *int
+}
+
+func (f countingFilter) MatchesComponents(components []types.Component) bool {
+	*f.scans++
+	return f.ComponentFilter.MatchesComponents(components)
+}
+
+// keyedCountingFilter is a countingFilter with a key, so searches with it share the archetypes they matched.
+type keyedCountingFilter struct {
+	countingFilter
+}
+
+func (f keyedCountingFilter) Key() string {
+	return "counting:" + filter.Key(f.ComponentFilter)
+}
+
+// BenchmarkSearchesWithTheSameFilter compares the number of archetypes scanned by new searches with the same filter
+// when they share the matched archetypes through the filter key, and when they do not.
+func BenchmarkSearchesWithTheSameFilter(b *testing.B) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	const searchesPerOp = 10
+	for _, shared := range []bool{false, true} {
+		b.Run(fmt.Sprintf("shared:%t", shared), func(b *testing.B) {
+			tf := cardinal.NewTestFixture(b, nil)
+			world := tf.World
+			assert.NilError(b, cardinal.RegisterComponent[Alpha](world))
+			assert.NilError(b, cardinal.RegisterComponent[Beta](world))
+			assert.NilError(b, cardinal.RegisterComponent[Gamma](world))
+			tf.StartWorld()
+			wCtx := cardinal.NewWorldContext(world)
+			for _, comps := range [][]types.Component{
+				{Alpha{}}, {Beta{}}, {Gamma{}}, {Alpha{}, Beta{}}, {Alpha{}, Gamma{}}, {Beta{}, Gamma{}},
+				{Alpha{}, Beta{}, Gamma{}},
+			} {
+				_, err := cardinal.CreateMany(wCtx, 10, comps...)
+				assert.NilError(b, err)
+			}
+
+			scans := 0
+			var f filter.ComponentFilter = countingFilter{filter.Contains(filter.Component[Alpha]()), &scans}
+			if shared {
+				f = keyedCountingFilter{countingFilter{filter.Contains(filter.Component[Alpha]()), &scans}}
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Systems commonly make new searches on every tick.
+				for j := 0; j < searchesPerOp; j++ {
+					count, err := cardinal.NewSearch().Entity(f).Count(wCtx)
+					assert.NilError(b, err)
+					assert.Equal(b, count, 40)
+				}
+			}
+			b.ReportMetric(float64(scans)/float64(b.N), "archetype-scans/op")
+		})
+	}
+}
@@ -12,6 +12,15 @@ func (f *not) MatchesComponents(components []types.Component) bool {
 	return !f.filter.MatchesComponents(components)
 }
 
+func (f *not) Key() string {
+	key := Key(f.filter)
+	if key == "" {
+		return ""
+	}
+	return "not(" + key + ")"
+}
+
+// Not matches archetypes that do not match the given filter.
 func Not(filter ComponentFilter) ComponentFilter {
 	return &not{filter: filter}
 }
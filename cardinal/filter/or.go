@@ -8,6 +8,7 @@ type or struct {
 	filters []ComponentFilter
 }
 
+// Or matches archetypes that match at least one of the given filters.
 func Or(filters ...ComponentFilter) ComponentFilter {
 	return &or{filters: filters}
 }
@@ -20,3 +21,11 @@ func (f *or) MatchesComponents(components []types.Component) bool {
 	}
 	return false
 }
+
+func (f *or) Key() string {
+	keys := make([]string, 0, len(f.filters))
+	for _, filter := range f.filters {
+		keys = append(keys, Key(filter))
+	}
+	return setKey("or", keys)
+}
@@ -6,6 +6,13 @@ import (
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
+const (
+	// compactionRatio is the ratio of capacity to length above which an entity list is reallocated by compact.
+	compactionRatio = 4
+	// compactionHeadroom is the extra capacity, as a fraction of the length, kept after compacting an entity list.
+	compactionHeadroom = 8
+)
+
 // activeEntities represents a group of entities.
 type activeEntities struct {
 	ids      []types.EntityID
@@ -34,3 +41,47 @@ func (a *activeEntities) swapRemove(idToRemove types.EntityID) error {
 	a.ids = a.ids[:len(a.ids)-1]
 	return nil
 }
+
+// rangeIDs calls fn for each active entity in order, stopping early when fn returns false. The backing slice is never
+// handed to fn. Adding or removing entities from this list while ranging over it is undefined behavior.
+func (a *activeEntities) rangeIDs(fn func(id types.EntityID) bool) {
+	for _, id := range a.ids {
+		if !fn(id) {
+			return
+		}
+	}
+}
+
+// removeMany removes all the given entity ids from this list of active entities in a single pass. Unlike swapRemove,
+// the relative order of the remaining entities is preserved. The ids may be given in any order and may contain
+// duplicates. The ids that were actually removed are returned in the order they appeared in the list.
+func (a *activeEntities) removeMany(idsToRemove []types.EntityID) []types.EntityID {
+	toRemove := make(map[types.EntityID]struct{}, len(idsToRemove))
+	for _, id := range idsToRemove {
+		toRemove[id] = struct{}{}
+	}
+	removed := make([]types.EntityID, 0, len(toRemove))
+	kept := a.ids[:0]
+	for _, id := range a.ids {
+		if _, ok := toRemove[id]; ok {
+			removed = append(removed, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	a.ids = kept
+	return removed
+}
+
+// compact reallocates the backing slice of the entity list down to its current length, plus a small amount of
+// headroom, once the number of entities drops below 1/compactionRatio of its capacity. It reports whether the slice
+// was reallocated.
+func (a *activeEntities) compact() bool {
+	if cap(a.ids) <= len(a.ids)*compactionRatio {
+		return false
+	}
+	ids := make([]types.EntityID, len(a.ids), len(a.ids)+len(a.ids)/compactionHeadroom)
+	copy(ids, a.ids)
+	a.ids = ids
+	return true
+}
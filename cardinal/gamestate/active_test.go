@@ -0,0 +1,74 @@
+package gamestate
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestActiveEntitiesCompactShrinksCapacity(t *testing.T) {
+	active := activeEntities{}
+	for i := 0; i < 1000; i++ {
+		active.ids = append(active.ids, types.EntityID(i))
+	}
+	for i := 0; i < 990; i++ {
+		assert.NilError(t, active.swapRemove(types.EntityID(i)))
+	}
+	highWaterCapacity := cap(active.ids)
+	remaining := append([]types.EntityID(nil), active.ids...)
+
+	assert.True(t, active.compact())
+	assert.True(t, cap(active.ids) < highWaterCapacity)
+	assert.True(t, cap(active.ids) >= len(active.ids))
+	assert.DeepEqual(t, active.ids, remaining)
+
+	// A list that is already compact is left alone.
+	assert.False(t, active.compact())
+}
+
+func TestActiveEntitiesRemoveManyHandlesUnsortedAndDuplicateIDs(t *testing.T) {
+	active := activeEntities{ids: []types.EntityID{0, 1, 2, 3, 4, 5, 6, 7}}
+
+	removed := active.removeMany([]types.EntityID{6, 1, 6, 3, 99, 1})
+
+	assert.DeepEqual(t, removed, []types.EntityID{1, 3, 6})
+	assert.DeepEqual(t, active.ids, []types.EntityID{0, 2, 4, 5, 7})
+}
+
+func BenchmarkActiveEntitiesRemove(b *testing.B) {
+	const total = 10000
+	toRemove := make([]types.EntityID, 0, total/2)
+	for i := 0; i < total; i += 2 {
+		toRemove = append(toRemove, types.EntityID(i))
+	}
+	newActive := func() activeEntities {
+		active := activeEntities{ids: make([]types.EntityID, 0, total)}
+		for i := 0; i < total; i++ {
+			active.ids = append(active.ids, types.EntityID(i))
+		}
+		return active
+	}
+
+	b.Run("swapRemove loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			active := newActive()
+			b.StartTimer()
+			for _, id := range toRemove {
+				if err := active.swapRemove(id); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("removeMany", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			active := newActive()
+			b.StartTimer()
+			active.removeMany(toRemove)
+		}
+	})
+}
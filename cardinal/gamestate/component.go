@@ -15,6 +15,12 @@ type compKey struct {
 	entityID types.EntityID
 }
 
+// ComponentChange identifies a component of an entity that was set.
+type ComponentChange struct {
+	EntityID  types.EntityID
+	Component types.ComponentMetadata
+}
+
 // sortComponentSet re-orders the given components so their IDs are strictly increasing. If any component is duplicated
 // an error is returned.
 func sortComponentSet(components []types.ComponentMetadata) error {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
@@ -46,30 +47,60 @@ type EntityCommandBuffer struct {
 	archIDToComps  VolatileStorage[types.ArchetypeID, []types.ComponentMetadata]
 	pendingArchIDs []types.ArchetypeID
 
+	// archetypeInitialCapacity is the capacity of the entity list of an archetype that has no entities yet.
+	archetypeInitialCapacity int
+	// archetypeCreatedHook is called whenever a new archetype is created.
+	archetypeCreatedHook ArchetypeCreatedHook
+
+	// dirtyComponentSet is nil unless dirty component tracking is enabled. dirtyComponents holds the same changes in the
+	// order they were first made.
+	dirtyComponentSet map[compKey]struct{}
+	dirtyComponents   []ComponentChange
+
+	// savepoint is nil unless a savepoint is taken (see Savepoint). journals are the storages that record the changes
+	// made since the savepoint.
+	savepoint *savepoint
+	journals  []journal
+
 	// OpenTelemetry tracer
 	tracer trace.Tracer
 }
 
 // NewEntityCommandBuffer creates a new command buffer manager that is able to queue up a series of states changes and
 // atomically commit them to the underlying redis dbStorage layer.
-func NewEntityCommandBuffer(storage PrimitiveStorage[string]) (*EntityCommandBuffer, error) {
+func NewEntityCommandBuffer(storage PrimitiveStorage[string], opts ...Option) (*EntityCommandBuffer, error) {
+	compValues := newJournaledStorage[compKey, any](NewMapStorage[compKey, any]())
+	compValuesToDelete := newJournaledStorage[compKey, bool](NewMapStorage[compKey, bool]())
+	active := newJournaledStorage[types.ArchetypeID, activeEntities](NewMapStorage[types.ArchetypeID, activeEntities]())
+	entityIDToArchID := newJournaledStorage[types.EntityID, types.ArchetypeID](
+		NewMapStorage[types.EntityID, types.ArchetypeID]())
+	entityIDToOriginArchID := newJournaledStorage[types.EntityID, types.ArchetypeID](
+		NewMapStorage[types.EntityID, types.ArchetypeID]())
+
 	m := &EntityCommandBuffer{
 		dbStorage:          storage,
-		compValues:         NewMapStorage[compKey, any](),
-		compValuesToDelete: NewMapStorage[compKey, bool](),
+		compValues:         compValues,
+		compValuesToDelete: compValuesToDelete,
 
-		activeEntities: NewMapStorage[types.ArchetypeID, activeEntities](),
+		activeEntities: active,
 		archIDToComps:  NewMapStorage[types.ArchetypeID, []types.ComponentMetadata](),
 
-		entityIDToArchID:       NewMapStorage[types.EntityID, types.ArchetypeID](),
-		entityIDToOriginArchID: NewMapStorage[types.EntityID, types.ArchetypeID](),
+		entityIDToArchID:       entityIDToArchID,
+		entityIDToOriginArchID: entityIDToOriginArchID,
 
 		// This field cannot be set until RegisterComponents is called
 		typeToComponent: nil,
 
+		// Archetypes are never rolled back, so archIDToComps is not journaled.
+		journals: []journal{compValues, compValuesToDelete, active, entityIDToArchID, entityIDToOriginArchID},
+
 		tracer: otel.Tracer("ecb"),
 	}
 
+	for _, opt := range opts {
+		opt(m)
+	}
+
 	return m, nil
 }
 
@@ -85,8 +116,17 @@ func (m *EntityCommandBuffer) RegisterComponents(comps []types.ComponentMetadata
 	return m.loadArchIDs()
 }
 
-// DiscardPending discards any pending state changes.
+// DiscardPending discards any pending state changes, and forgets about the components that were set so far (see
+// DirtyComponents).
 func (m *EntityCommandBuffer) DiscardPending() error {
+	m.ClearDirtyComponents()
+	return m.clearPending()
+}
+
+// clearPending clears the in-memory state changes of the current tick, which are either discarded or were just
+// committed to storage.
+func (m *EntityCommandBuffer) clearPending() error {
+	m.ReleaseSavepoint()
 	err := m.compValues.Clear()
 	if err != nil {
 		return err
@@ -131,7 +171,7 @@ func (m *EntityCommandBuffer) RemoveEntity(idToRemove types.EntityID) error {
 	if err != nil {
 		return err
 	}
-	active, err := m.getActiveEntities(archID)
+	active, err := m.getActiveEntitiesToModify(archID)
 	if err != nil {
 		return err
 	}
@@ -144,13 +184,56 @@ func (m *EntityCommandBuffer) RemoveEntity(idToRemove types.EntityID) error {
 	if err != nil {
 		return err
 	}
+
+	return m.clearRemovedEntity(archID, idToRemove)
+}
+
+// RemoveEntities removes all the given entities from the ECS data model. Entities are grouped by archetype so that
+// each archetype's list of active entities is only updated once. If any of the entities does not exist, an error is
+// returned and no entity is removed.
+func (m *EntityCommandBuffer) RemoveEntities(ids ...types.EntityID) error {
+	idsByArchID := map[types.ArchetypeID][]types.EntityID{}
+	archIDs := make([]types.ArchetypeID, 0)
+	for _, id := range ids {
+		archID, err := m.getArchetypeForEntity(id)
+		if err != nil {
+			return err
+		}
+		if _, ok := idsByArchID[archID]; !ok {
+			archIDs = append(archIDs, archID)
+		}
+		idsByArchID[archID] = append(idsByArchID[archID], id)
+	}
+
+	for _, archID := range archIDs {
+		active, err := m.getActiveEntitiesToModify(archID)
+		if err != nil {
+			return err
+		}
+		removed := active.removeMany(idsByArchID[archID])
+		if err = m.setActiveEntities(archID, active); err != nil {
+			return err
+		}
+		for _, id := range removed {
+			if err = m.clearRemovedEntity(archID, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// clearRemovedEntity clears the archetype mapping and queues the deletion of the component values of an entity that
+// has been removed from the given archetype's list of active entities.
+func (m *EntityCommandBuffer) clearRemovedEntity(archID types.ArchetypeID, idToRemove types.EntityID) error {
 	if _, err := m.entityIDToOriginArchID.Get(idToRemove); err != nil {
 		err = m.entityIDToOriginArchID.Set(idToRemove, archID)
 		if err != nil {
 			return err
 		}
 	}
-	err = m.entityIDToArchID.Delete(idToRemove)
+	err := m.entityIDToArchID.Delete(idToRemove)
 	if err != nil {
 		return err
 	}
@@ -183,7 +266,8 @@ func (m *EntityCommandBuffer) CreateEntity(comps ...types.ComponentMetadata) (ty
 	return ids[0], nil
 }
 
-// CreateManyEntities creates many entities with the given set of components.
+// CreateManyEntities creates many entities with the given set of components. The archetype is only looked up once, so
+// this is faster than calling CreateEntity num times.
 func (m *EntityCommandBuffer) CreateManyEntities(num int, comps ...types.ComponentMetadata) ([]types.EntityID, error) {
 	archID, err := m.getOrMakeArchIDForComponents(comps)
 	if err != nil {
@@ -195,6 +279,8 @@ func (m *EntityCommandBuffer) CreateManyEntities(num int, comps ...types.Compone
 	if err != nil {
 		return nil, err
 	}
+	// Grow the archetype's entity list once instead of on every append below.
+	active.ids = slices.Grow(active.ids, num)
 	for i := range ids {
 		currID, err := m.nextEntityID()
 		if err != nil {
@@ -234,7 +320,40 @@ func (m *EntityCommandBuffer) SetComponentForEntity(
 	}
 
 	key := compKey{cType.ID(), id}
-	return m.compValues.Set(key, value)
+	if err := m.compValues.Set(key, value); err != nil {
+		return err
+	}
+	m.markDirty(key, cType)
+	return nil
+}
+
+// DirtyComponents returns the components that were set on entities since ClearDirtyComponents was last called, in the
+// order they were first set. It always returns nil if dirty component tracking is not enabled.
+func (m *EntityCommandBuffer) DirtyComponents() []ComponentChange {
+	if m.dirtyComponentSet == nil {
+		return nil
+	}
+	return slices.Clone(m.dirtyComponents)
+}
+
+// ClearDirtyComponents forgets about all components that were set so far.
+func (m *EntityCommandBuffer) ClearDirtyComponents() {
+	if m.dirtyComponentSet == nil {
+		return
+	}
+	clear(m.dirtyComponentSet)
+	m.dirtyComponents = m.dirtyComponents[:0]
+}
+
+func (m *EntityCommandBuffer) markDirty(key compKey, cType types.ComponentMetadata) {
+	if m.dirtyComponentSet == nil {
+		return
+	}
+	if _, ok := m.dirtyComponentSet[key]; ok {
+		return
+	}
+	m.dirtyComponentSet[key] = struct{}{}
+	m.dirtyComponents = append(m.dirtyComponents, ComponentChange{EntityID: key.entityID, Component: cType})
 }
 
 // GetComponentForEntity returns the saved component data for the given entity.
@@ -285,7 +404,7 @@ func (m *EntityCommandBuffer) GetComponentForEntityInRawJSON(cType types.Compone
 	if err != nil {
 		return nil, err
 	}
-	return cType.Encode(value)
+	return codec.Encode(value)
 }
 
 // AddComponentToEntity adds the given component to the given entity. An error is returned if the entity
@@ -406,6 +525,29 @@ func (m *EntityCommandBuffer) GetEntitiesForArchID(archID types.ArchetypeID) ([]
 	return active.ids, nil
 }
 
+// RangeEntitiesForArchID calls fn for each entity that currently belongs to the given archetype EntityID, stopping
+// early when fn returns false. Unlike GetEntitiesForArchID, the underlying entity list is never exposed to the caller,
+// and no allocation is made. Adding or removing entities from the archetype during the range is undefined behavior.
+func (m *EntityCommandBuffer) RangeEntitiesForArchID(archID types.ArchetypeID, fn func(id types.EntityID) bool) error {
+	active, err := m.getActiveEntities(archID)
+	if err != nil {
+		return err
+	}
+	active.rangeIDs(fn)
+	return nil
+}
+
+// EntityCountForArchID returns the number of entities that currently belong to the given archetype EntityID. The
+// entity list of each archetype is kept up to date as entities are created, moved and removed, so its length is the
+// count and no entity list is copied.
+func (m *EntityCommandBuffer) EntityCountForArchID(archID types.ArchetypeID) (int, error) {
+	active, err := m.getActiveEntities(archID)
+	if err != nil {
+		return 0, err
+	}
+	return len(active.ids), nil
+}
+
 // SearchFrom returns an ArchetypeIterator based on a component filter. The iterator will iterate over all archetypes
 // that match the given filter.
 func (m *EntityCommandBuffer) SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator {
@@ -423,6 +565,30 @@ func (m *EntityCommandBuffer) SearchFrom(filter filter.ComponentFilter, start in
 	return itr
 }
 
+// Compact releases the memory held by the entity lists of archetypes that had most of their entities removed during
+// the current tick. Entity lists are reloaded from storage at the start of every tick, so this only matters for ticks
+// that create and then remove a large number of entities.
+func (m *EntityCommandBuffer) Compact() error {
+	archIDs, err := m.activeEntities.Keys()
+	if err != nil {
+		return err
+	}
+	for _, archID := range archIDs {
+		active, err := m.activeEntities.Get(archID)
+		if err != nil {
+			return err
+		}
+		if !active.compact() {
+			continue
+		}
+		// The set of entities did not change, so there is no need to mark the list as modified.
+		if err := m.activeEntities.Set(archID, active); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ArchetypeCount returns the number of archetypes that have been generated.
 func (m *EntityCommandBuffer) ArchetypeCount() int {
 	return m.archIDToComps.Len()
@@ -448,6 +614,11 @@ func (m *EntityCommandBuffer) getArchetypeForEntity(id types.EntityID) (types.Ar
 	if err == nil {
 		return archID, nil
 	}
+	// An entity with an origin archetype but no current archetype has been removed during this tick. It must not be
+	// loaded from storage again, or the removal would be undone.
+	if _, err = m.entityIDToOriginArchID.Get(id); err == nil {
+		return 0, eris.Wrap(redis.Nil, ErrEntityDoesNotExist.Error())
+	}
 	key := storageArchetypeIDForEntityID(id)
 	num, err := m.dbStorage.GetInt(context.Background(), key)
 	if err != nil {
@@ -467,29 +638,36 @@ func (m *EntityCommandBuffer) getArchetypeForEntity(id types.EntityID) (types.Ar
 
 // nextEntityID returns the next available entity EntityID.
 func (m *EntityCommandBuffer) nextEntityID() (types.EntityID, error) {
-	if !m.isEntityIDLoaded {
-		// The next valid entity EntityID needs to be loaded from dbStorage.
-		ctx := context.Background()
-		nextID, err := m.dbStorage.GetUInt64(ctx, storageNextEntityIDKey())
-		err = eris.Wrap(err, "")
-		if err != nil {
-			// todo: make redis.Nil a general error on storage.
-			if !eris.Is(eris.Cause(err), redis.Nil) {
-				return 0, err
-			}
-			// redis.Nil means there's no value at this key. Start with an EntityID of 0
-			nextID = 0
-		}
-		m.nextEntityIDSaved = nextID
-		m.pendingEntityIDs = 0
-		m.isEntityIDLoaded = true
+	if err := m.loadNextEntityID(); err != nil {
+		return 0, err
 	}
-
 	id := m.nextEntityIDSaved + m.pendingEntityIDs
 	m.pendingEntityIDs++
 	return types.EntityID(id), nil
 }
 
+// loadNextEntityID loads the next valid entity EntityID from dbStorage if it hasn't been loaded yet.
+func (m *EntityCommandBuffer) loadNextEntityID() error {
+	if m.isEntityIDLoaded {
+		return nil
+	}
+	ctx := context.Background()
+	nextID, err := m.dbStorage.GetUInt64(ctx, storageNextEntityIDKey())
+	err = eris.Wrap(err, "")
+	if err != nil {
+		// todo: make redis.Nil a general error on storage.
+		if !eris.Is(eris.Cause(err), redis.Nil) {
+			return err
+		}
+		// redis.Nil means there's no value at this key. Start with an EntityID of 0
+		nextID = 0
+	}
+	m.nextEntityIDSaved = nextID
+	m.pendingEntityIDs = 0
+	m.isEntityIDLoaded = true
+	return nil
+}
+
 // getOrMakeArchIDForComponents converts the given set of components into an archetype EntityID.
 // If the set of components has already been assigned an archetype EntityID, that EntityID is returned.
 // If this is a new set of components, an archetype EntityID is generated.
@@ -511,6 +689,11 @@ func (m *EntityCommandBuffer) getOrMakeArchIDForComponents(
 		return 0, err
 	}
 	log.Debug().Int("archetype_id", int(id)).Msg("created")
+	if m.archetypeCreatedHook != nil {
+		if err := m.archetypeCreatedHook(id, slices.Clone(comps)); err != nil {
+			log.Warn().Err(err).Int("archetype_id", int(id)).Msg("archetype created hook failed")
+		}
+	}
 	return id, nil
 }
 
@@ -532,6 +715,9 @@ func (m *EntityCommandBuffer) getActiveEntities(archID types.ArchetypeID) (activ
 		if !eris.Is(eris.Cause(err), redis.Nil) {
 			return active, err
 		}
+		if m.archetypeInitialCapacity > 0 {
+			ids = make([]types.EntityID, 0, m.archetypeInitialCapacity)
+		}
 	} else {
 		ids, err = codec.Decode[[]types.EntityID](bz)
 		if err != nil {
@@ -549,6 +735,21 @@ func (m *EntityCommandBuffer) getActiveEntities(archID types.ArchetypeID) (activ
 	return result, nil
 }
 
+// getActiveEntitiesToModify returns the entities of the given archetype like getActiveEntities, for a change that
+// modifies the list in place. While a savepoint is taken, the list is copied the first time it is modified, so that
+// the list recorded by the savepoint stays intact.
+func (m *EntityCommandBuffer) getActiveEntitiesToModify(archID types.ArchetypeID) (activeEntities, error) {
+	active, err := m.getActiveEntities(archID)
+	if err != nil || m.savepoint == nil {
+		return active, err
+	}
+	if _, ok := m.savepoint.copiedActiveEntities[archID]; !ok {
+		active.ids = slices.Clone(active.ids)
+		m.savepoint.copiedActiveEntities[archID] = struct{}{}
+	}
+	return active, nil
+}
+
 // setActiveEntities sets the entities that are associated with the given archetype EntityID and marks
 // the information as modified so it can later be pushed to the dbStorage layer.
 func (m *EntityCommandBuffer) setActiveEntities(archID types.ArchetypeID, active activeEntities) error {
@@ -569,7 +770,7 @@ func (m *EntityCommandBuffer) moveEntityByArchetype(fromArchID, toArchID types.A
 		return err
 	}
 
-	active, err := m.getActiveEntities(fromArchID)
+	active, err := m.getActiveEntitiesToModify(fromArchID)
 	if err != nil {
 		return err
 	}
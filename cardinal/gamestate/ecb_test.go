@@ -133,6 +133,68 @@ func TestDiscardedComponentChangeRevertsToOriginalValue(t *testing.T) {
 	assert.Equal(t, wantValue, gotValue)
 }
 
+func TestDiscardPendingForgetsDirtyComponents(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr(), Password: "", DB: 0})
+	storage := gamestate.NewRedisPrimitiveStorage(client)
+	manager, err := gamestate.NewEntityCommandBuffer(&storage, gamestate.WithDirtyComponentTracking())
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RegisterComponents(allComponents))
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{1}))
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+	// Committing the tick keeps the changes around until they are cleared.
+	assert.Equal(t, len(manager.DirtyComponents()), 1)
+
+	manager.ClearDirtyComponents()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, id, Foo{2}))
+	assert.Equal(t, len(manager.DirtyComponents()), 1)
+	assert.NilError(t, manager.DiscardPending())
+	assert.Equal(t, len(manager.DirtyComponents()), 0)
+}
+
+func TestRollbackToSavepointOnlyUndoesTheChangesSinceTheSavepoint(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+	fooArchID, err := manager.GetArchIDForComponents([]types.ComponentMetadata{fooComp})
+	assert.NilError(t, err)
+
+	// Changes made before the savepoint are kept.
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{5}))
+	manager.Savepoint()
+	assert.NilError(t, manager.SetComponentForEntity(fooComp, ids[0], Foo{6}))
+	assert.NilError(t, manager.RemoveEntity(ids[1]))
+	assert.NilError(t, manager.AddComponentToEntity(barComp, ids[2]))
+	created, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RollbackToSavepoint())
+
+	gotValue, err := manager.GetComponentForEntity(fooComp, ids[0])
+	assert.NilError(t, err)
+	assert.Equal(t, gotValue, Foo{5})
+	gotIDs, err := manager.GetEntitiesForArchID(fooArchID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gotIDs, ids)
+	comps, err := manager.GetComponentTypesForEntity(ids[2])
+	assert.NilError(t, err)
+	assert.Equal(t, len(comps), 1)
+	_, err = manager.GetComponentTypesForEntity(created)
+	assert.Check(t, err != nil)
+
+	// The entity IDs handed out since the savepoint are not handed out again.
+	next, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.Check(t, next > created)
+
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+	gotIDs, err = manager.GetEntitiesForArchID(fooArchID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gotIDs, append(ids, next))
+}
+
 func TestDiscardedEntityIDsWillBeAssignedAgain(t *testing.T) {
 	manager := newCmdBufferForTest(t)
 	ctx := context.Background()
@@ -439,6 +501,119 @@ func TestEntityCanBeRemoved(t *testing.T) {
 	}
 }
 
+func TestManyEntitiesCanBeRemovedAtOnce(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+
+	fooIDs, err := manager.CreateManyEntities(5, fooComp)
+	assert.NilError(t, err)
+	fooBarIDs, err := manager.CreateManyEntities(5, fooComp, barComp)
+	assert.NilError(t, err)
+
+	// Unsorted ids spanning two archetypes, with a duplicate.
+	toRemove := []types.EntityID{fooBarIDs[3], fooIDs[4], fooIDs[0], fooBarIDs[3], fooBarIDs[1]}
+	assert.NilError(t, manager.RemoveEntities(toRemove...))
+
+	fooArchID, err := manager.GetArchIDForComponents([]types.ComponentMetadata{fooComp})
+	assert.NilError(t, err)
+	gotIDs, err := manager.GetEntitiesForArchID(fooArchID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gotIDs, []types.EntityID{fooIDs[1], fooIDs[2], fooIDs[3]})
+
+	fooBarArchID, err := manager.GetArchIDForComponents([]types.ComponentMetadata{fooComp, barComp})
+	assert.NilError(t, err)
+	gotIDs, err = manager.GetEntitiesForArchID(fooBarArchID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gotIDs, []types.EntityID{fooBarIDs[0], fooBarIDs[2], fooBarIDs[4]})
+
+	for _, id := range toRemove {
+		_, err = manager.GetComponentTypesForEntity(id)
+		assert.Check(t, err != nil)
+	}
+	assert.NilError(t, manager.FinalizeTick(context.Background()))
+}
+
+func TestRemoveManyEntitiesFailsWithoutRemovingAnythingWhenAnEntityIsMissing(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+
+	err = manager.RemoveEntities(ids[0], types.EntityID(9999))
+	assert.Check(t, err != nil)
+
+	for _, id := range ids {
+		_, err = manager.GetComponentTypesForEntity(id)
+		assert.NilError(t, err)
+	}
+}
+
+func TestReadingACommittedEntityAfterRemovingItDoesNotRestoreIt(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	id, err := manager.CreateEntity(fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	assert.NilError(t, manager.RemoveEntity(id))
+	_, err = manager.GetComponentTypesForEntity(id)
+	assert.Check(t, err != nil)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	_, err = manager.GetComponentTypesForEntity(id)
+	assert.Check(t, err != nil)
+}
+
+func TestRemovedEntityIDsAreNeverReused(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+	ctx := context.Background()
+
+	oldIDs, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	assert.NilError(t, manager.RemoveEntities(oldIDs...))
+	assert.NilError(t, manager.FinalizeTick(ctx))
+
+	newIDs, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	assert.NilError(t, manager.FinalizeTick(ctx))
+	for _, oldID := range oldIDs {
+		for _, newID := range newIDs {
+			assert.Check(t, oldID != newID)
+		}
+		_, err = manager.GetComponentTypesForEntity(oldID)
+		assert.Check(t, err != nil)
+		_, err = manager.GetComponentForEntity(fooComp, oldID)
+		assert.Check(t, err != nil)
+		assert.Check(t, manager.SetComponentForEntity(fooComp, oldID, Foo{}) != nil)
+	}
+}
+
+func TestRangeEntitiesForArchIDStopsEarly(t *testing.T) {
+	manager := newCmdBufferForTest(t)
+
+	ids, err := manager.CreateManyEntities(10, fooComp)
+	assert.NilError(t, err)
+	archID, err := manager.GetArchIDForComponents([]types.ComponentMetadata{fooComp})
+	assert.NilError(t, err)
+
+	var visited []types.EntityID
+	err = manager.RangeEntitiesForArchID(archID, func(id types.EntityID) bool {
+		visited = append(visited, id)
+		return len(visited) < 3
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, visited, ids[:3])
+
+	visited = visited[:0]
+	err = manager.RangeEntitiesForArchID(archID, func(id types.EntityID) bool {
+		visited = append(visited, id)
+		return true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, visited, ids)
+}
+
 func TestMovedEntitiesCanBeFoundInNewArchetype(t *testing.T) {
 	manager := newCmdBufferForTest(t)
 
@@ -647,3 +822,58 @@ func TestFinalizeTickPerformanceIsConsistent(t *testing.T) {
 	assert.Assert(t, averageAlloc < maxAlloc,
 		"FinalizeTick allocated an average of %v but must be less than %v", averageAlloc, maxAlloc)
 }
+
+func BenchmarkCreateEntitiesInOneArchetype(b *testing.B) {
+	const numOfEntities = 10000
+	benchmarkCreate := func(b *testing.B, opts ...gamestate.Option) {
+		s := miniredis.RunT(b)
+		client := redis.NewClient(&redis.Options{
+			Addr:     s.Addr(),
+			Password: "", // no password set
+			DB:       0,  // use default DB
+		})
+		storage := gamestate.NewRedisPrimitiveStorage(client)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			manager, err := gamestate.NewEntityCommandBuffer(&storage, opts...)
+			assert.NilError(b, err)
+			assert.NilError(b, manager.RegisterComponents(allComponents))
+			b.StartTimer()
+			for j := 0; j < numOfEntities; j++ {
+				_, err = manager.CreateEntity(fooComp)
+				assert.NilError(b, err)
+			}
+		}
+	}
+
+	b.Run("default capacity", func(b *testing.B) {
+		benchmarkCreate(b)
+	})
+	b.Run("tuned capacity", func(b *testing.B) {
+		benchmarkCreate(b, gamestate.WithArchetypeInitialCapacity(numOfEntities))
+	})
+}
+
+func TestArchetypeInitialCapacityDoesNotAffectEntities(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr:     s.Addr(),
+		Password: "", // no password set
+		DB:       0,  // use default DB
+	})
+	storage := gamestate.NewRedisPrimitiveStorage(client)
+	manager, err := gamestate.NewEntityCommandBuffer(&storage, gamestate.WithArchetypeInitialCapacity(64))
+	assert.NilError(t, err)
+	assert.NilError(t, manager.RegisterComponents(allComponents))
+
+	ids, err := manager.CreateManyEntities(3, fooComp)
+	assert.NilError(t, err)
+	archID, err := manager.GetArchIDForComponents([]types.ComponentMetadata{fooComp})
+	assert.NilError(t, err)
+	gotIDs, err := manager.GetEntitiesForArchID(archID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gotIDs, ids)
+	assert.Equal(t, 64, cap(gotIDs))
+}
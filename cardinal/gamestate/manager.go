@@ -22,6 +22,8 @@ type Reader interface {
 
 	// One Archetype Many Entities
 	GetEntitiesForArchID(archID types.ArchetypeID) ([]types.EntityID, error)
+	RangeEntitiesForArchID(archID types.ArchetypeID, fn func(id types.EntityID) bool) error
+	EntityCountForArchID(archID types.ArchetypeID) (int, error)
 
 	// Misc
 	SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator
@@ -32,6 +34,9 @@ type Writer interface {
 	// One Entity
 	RemoveEntity(id types.EntityID) error
 
+	// Many Entities
+	RemoveEntities(ids ...types.EntityID) error
+
 	// Many Components
 	CreateEntity(comps ...types.ComponentMetadata) (types.EntityID, error)
 	CreateManyEntities(num int, comps ...types.ComponentMetadata) ([]types.EntityID, error)
@@ -44,6 +49,7 @@ type Writer interface {
 	// Misc
 	Close() error
 	RegisterComponents([]types.ComponentMetadata) error
+	Compact() error
 }
 
 type TickStorage interface {
@@ -51,11 +57,33 @@ type TickStorage interface {
 	FinalizeTick(ctx context.Context) error
 }
 
+// ChangeTracker reports which components were written to since the changes were last cleared.
+type ChangeTracker interface {
+	DirtyComponents() []ComponentChange
+	ClearDirtyComponents()
+}
+
+// Snapshotter copies the whole state into a Snapshot and recreates it from one.
+type Snapshotter interface {
+	Snapshot() (*Snapshot, error)
+	Restore(snap *Snapshot) error
+}
+
+// Savepointer undoes the pending changes made since a savepoint, such as those of a system that failed halfway.
+type Savepointer interface {
+	Savepoint()
+	RollbackToSavepoint() error
+	ReleaseSavepoint()
+}
+
 // Manager represents all the methods required to track Component, Entity, and Archetype information
 // which powers the ECS dbStorage layer.
 type Manager interface {
 	TickStorage
 	Reader
 	Writer
+	ChangeTracker
+	Snapshotter
+	Savepointer
 	ToReadOnly() Reader
 }
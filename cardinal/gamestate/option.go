@@ -0,0 +1,47 @@
+package gamestate
+
+import (
+	"go.opentelemetry.io/otel/trace"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type Option func(*EntityCommandBuffer)
+
+// ArchetypeCreatedHook is called with the id and the components of an archetype when it is created.
+type ArchetypeCreatedHook func(archID types.ArchetypeID, components []types.ComponentMetadata) error
+
+// WithArchetypeInitialCapacity sets the capacity that the entity list of an archetype starts with when the archetype
+// has no entities yet. By default, entity lists start empty and grow on demand. Games that put a large number of
+// entities into a single archetype can use a larger capacity to avoid repeated reallocations as the list grows.
+func WithArchetypeInitialCapacity(capacity int) Option {
+	return func(m *EntityCommandBuffer) {
+		m.archetypeInitialCapacity = capacity
+	}
+}
+
+// WithArchetypeCreatedHook sets a hook that is called synchronously whenever an entity is given a set of components
+// that no existing archetype has. An error returned by the hook is logged and does not prevent the archetype from
+// being created. Archetypes created during a tick that is later discarded are created again, so the hook may be called
+// more than once for the same set of components in that case.
+func WithArchetypeCreatedHook(hook ArchetypeCreatedHook) Option {
+	return func(m *EntityCommandBuffer) {
+		m.archetypeCreatedHook = hook
+	}
+}
+
+// WithDirtyComponentTracking makes the command buffer record which components of which entities are written to, so that
+// they can be retrieved with DirtyComponents. Tracking is off by default to avoid its overhead.
+func WithDirtyComponentTracking() Option {
+	return func(m *EntityCommandBuffer) {
+		m.dirtyComponentSet = map[compKey]struct{}{}
+	}
+}
+
+// WithTracerProvider sets the provider of the tracer that the spans of the command buffer are started with. By default,
+// the global tracer provider is used.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(m *EntityCommandBuffer) {
+		m.tracer = tp.Tracer("ecb")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/cardinal/codec"
@@ -49,9 +50,9 @@ func (r *readOnlyManager) refreshArchIDToCompTypes() error {
 func (r *readOnlyManager) GetComponentForEntity(
 	cType types.ComponentMetadata, id types.EntityID,
 ) (any, error) {
-	bz, err := r.GetComponentForEntityInRawJSON(cType, id)
+	bz, err := r.storage.GetBytes(context.Background(), storageComponentKey(cType.ID(), id))
 	if err != nil {
-		return nil, err
+		return nil, eris.Wrap(err, "")
 	}
 	return cType.Decode(bz)
 }
@@ -62,7 +63,14 @@ func (r *readOnlyManager) GetComponentForEntityInRawJSON(
 	ctx := context.Background()
 	key := storageComponentKey(cType.ID(), id)
 	res, err := r.storage.GetBytes(ctx, key)
-	return res, eris.Wrap(err, "")
+	if err != nil || !cType.HasCustomCodec() {
+		return res, eris.Wrap(err, "")
+	}
+	value, err := cType.Decode(res)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(value)
 }
 
 func (r *readOnlyManager) getComponentsForArchID(archID types.ArchetypeID) ([]types.ComponentMetadata, error) {
@@ -85,6 +93,10 @@ func (r *readOnlyManager) GetComponentTypesForEntity(id types.EntityID) ([]types
 	archIDKey := storageArchetypeIDForEntityID(id)
 	num, err := r.storage.GetInt(ctx, archIDKey)
 	if err != nil {
+		// todo: Make redis.Nil a general error on storage
+		if errors.Is(err, redis.Nil) {
+			return nil, eris.Wrap(redis.Nil, ErrEntityDoesNotExist.Error())
+		}
 		return nil, eris.Wrap(err, "")
 	}
 	archID := types.ArchetypeID(num)
@@ -148,6 +160,27 @@ func (r *readOnlyManager) GetEntitiesForArchID(archID types.ArchetypeID) ([]type
 	return ids, nil
 }
 
+func (r *readOnlyManager) RangeEntitiesForArchID(archID types.ArchetypeID, fn func(id types.EntityID) bool) error {
+	ids, err := r.GetEntitiesForArchID(archID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !fn(id) {
+			break
+		}
+	}
+	return nil
+}
+
+func (r *readOnlyManager) EntityCountForArchID(archID types.ArchetypeID) (int, error) {
+	ids, err := r.GetEntitiesForArchID(archID)
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
 func (r *readOnlyManager) SearchFrom(filter filter.ComponentFilter, start int) *ArchetypeIterator {
 	itr := &ArchetypeIterator{}
 	if err := r.refreshArchIDToCompTypes(); err != nil {
@@ -0,0 +1,156 @@
+package gamestate
+
+import (
+	"errors"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// savepoint holds what is needed to undo the pending changes made since it was taken, on top of the values recorded by
+// the journaled storages.
+type savepoint struct {
+	dirtyComponents int
+	pendingArchIDs  int
+	// copiedActiveEntities are the archetypes whose list of entities has been copied since the savepoint was taken, so
+	// that it can be changed in place without changing the list recorded by the savepoint.
+	copiedActiveEntities map[types.ArchetypeID]struct{}
+}
+
+// Savepoint starts recording the pending changes, so that they can be undone with RollbackToSavepoint. Taking a
+// savepoint while another one is taken replaces it. Archetypes and entity IDs are never given back, so archetypes
+// created since the savepoint remain, and entity IDs handed out since the savepoint are not handed out again.
+func (m *EntityCommandBuffer) Savepoint() {
+	m.savepoint = &savepoint{
+		dirtyComponents:      len(m.dirtyComponents),
+		pendingArchIDs:       len(m.pendingArchIDs),
+		copiedActiveEntities: map[types.ArchetypeID]struct{}{},
+	}
+	for _, j := range m.journals {
+		j.begin()
+	}
+}
+
+// RollbackToSavepoint undoes the pending changes made since the savepoint was taken, and releases the savepoint. It is
+// a no-op if no savepoint is taken.
+func (m *EntityCommandBuffer) RollbackToSavepoint() error {
+	if m.savepoint == nil {
+		return nil
+	}
+	errs := make([]error, 0, len(m.journals))
+	for _, j := range m.journals {
+		errs = append(errs, j.rollback())
+	}
+	// The dirty components may have been cleared since the savepoint was taken.
+	if n := m.savepoint.dirtyComponents; m.dirtyComponentSet != nil && n <= len(m.dirtyComponents) {
+		for _, c := range m.dirtyComponents[n:] {
+			delete(m.dirtyComponentSet, compKey{c.Component.ID(), c.EntityID})
+		}
+		m.dirtyComponents = m.dirtyComponents[:n]
+	}
+	// The archetypes created since the savepoint are kept, but without any of their entities.
+	if n := m.savepoint.pendingArchIDs; n <= len(m.pendingArchIDs) {
+		for _, archID := range m.pendingArchIDs[n:] {
+			errs = append(errs, m.setActiveEntities(archID, activeEntities{ids: nil, modified: true}))
+		}
+	}
+	m.savepoint = nil
+	if err := errors.Join(errs...); err != nil {
+		return eris.Wrap(err, "failed to roll back to savepoint")
+	}
+	return nil
+}
+
+// ReleaseSavepoint keeps the pending changes made since the savepoint was taken, and stops recording them.
+func (m *EntityCommandBuffer) ReleaseSavepoint() {
+	for _, j := range m.journals {
+		j.release()
+	}
+	m.savepoint = nil
+}
+
+// journal is a storage that can record the changes made to it since a savepoint.
+type journal interface {
+	begin()
+	rollback() error
+	release()
+}
+
+var _ VolatileStorage[string, any] = &journaledStorage[string, any]{}
+
+// journaledStorage wraps a VolatileStorage, recording the value each key had at the savepoint the first time the key is
+// changed after it.
+type journaledStorage[K comparable, V any] struct {
+	VolatileStorage[K, V]
+	// undo is nil unless a savepoint is taken.
+	undo map[K]undoEntry[V]
+}
+
+type undoEntry[V any] struct {
+	value   V
+	existed bool
+}
+
+func newJournaledStorage[K comparable, V any](storage VolatileStorage[K, V]) *journaledStorage[K, V] {
+	return &journaledStorage[K, V]{VolatileStorage: storage, undo: nil}
+}
+
+func (s *journaledStorage[K, V]) Set(key K, value V) error {
+	s.record(key)
+	return s.VolatileStorage.Set(key, value)
+}
+
+func (s *journaledStorage[K, V]) Delete(key K) error {
+	s.record(key)
+	return s.VolatileStorage.Delete(key)
+}
+
+func (s *journaledStorage[K, V]) Clear() error {
+	if s.undo != nil {
+		keys, err := s.Keys()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			s.record(key)
+		}
+	}
+	return s.VolatileStorage.Clear()
+}
+
+func (s *journaledStorage[K, V]) record(key K) {
+	if s.undo == nil {
+		return
+	}
+	if _, ok := s.undo[key]; ok {
+		return
+	}
+	value, err := s.Get(key)
+	s.undo[key] = undoEntry[V]{value: value, existed: err == nil}
+}
+
+func (s *journaledStorage[K, V]) begin() {
+	s.undo = map[K]undoEntry[V]{}
+}
+
+func (s *journaledStorage[K, V]) rollback() error {
+	undo := s.undo
+	s.undo = nil
+	for key, entry := range undo {
+		var err error
+		if entry.existed {
+			err = s.VolatileStorage.Set(key, entry.value)
+		} else {
+			err = s.VolatileStorage.Delete(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *journaledStorage[K, V]) release() {
+	s.undo = nil
+}
@@ -0,0 +1,195 @@
+package gamestate
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/codec"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+var ErrStateNotEmpty = eris.New("a snapshot can only be restored into a state without any archetypes or entities")
+
+// Snapshot is a copy of all archetypes, entities, and component values. Archetypes are listed in the order of their
+// IDs, and the entities of each archetype are listed in the order they are stored in.
+type Snapshot struct {
+	NextEntityID uint64              `json:"nextEntityID"`
+	Archetypes   []ArchetypeSnapshot `json:"archetypes"`
+}
+
+// ArchetypeSnapshot is a copy of an archetype's components (by name) and entities.
+type ArchetypeSnapshot struct {
+	Components []string         `json:"components"`
+	Entities   []EntitySnapshot `json:"entities"`
+}
+
+// EntitySnapshot is a copy of an entity's component values, encoded with each component's codec. The values are in the
+// same order as the components of the entity's archetype.
+type EntitySnapshot struct {
+	ID         types.EntityID    `json:"id"`
+	Components []json.RawMessage `json:"components"`
+}
+
+// Snapshot returns a copy of the whole state, including any pending changes.
+func (m *EntityCommandBuffer) Snapshot() (*Snapshot, error) {
+	if err := m.loadNextEntityID(); err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{
+		NextEntityID: m.nextEntityIDSaved + m.pendingEntityIDs,
+		Archetypes:   make([]ArchetypeSnapshot, 0, m.ArchetypeCount()),
+	}
+	for i := 0; i < m.ArchetypeCount(); i++ {
+		archID := types.ArchetypeID(i)
+		comps, err := m.GetComponentTypesForArchID(archID)
+		if err != nil {
+			return nil, err
+		}
+		ids, err := m.GetEntitiesForArchID(archID)
+		if err != nil {
+			return nil, err
+		}
+		arch := ArchetypeSnapshot{
+			Components: make([]string, 0, len(comps)),
+			Entities:   make([]EntitySnapshot, 0, len(ids)),
+		}
+		for _, comp := range comps {
+			arch.Components = append(arch.Components, comp.Name())
+		}
+		for _, id := range ids {
+			entity := EntitySnapshot{ID: id, Components: make([]json.RawMessage, 0, len(comps))}
+			for _, comp := range comps {
+				bz, err := m.encodeForSnapshot(comp, id)
+				if err != nil {
+					return nil, err
+				}
+				entity.Components = append(entity.Components, bz)
+			}
+			arch.Entities = append(arch.Entities, entity)
+		}
+		snap.Archetypes = append(snap.Archetypes, arch)
+	}
+	return snap, nil
+}
+
+// Restore recreates the archetypes, entities, and component values of the given snapshot as pending changes, which are
+// committed by the next call to FinalizeTick. Archetypes and entities keep their IDs, so the state must not have any
+// archetypes or entities yet. Component values are decoded with each component's codec.
+func (m *EntityCommandBuffer) Restore(snap *Snapshot) error {
+	if err := m.loadNextEntityID(); err != nil {
+		return err
+	}
+	if m.ArchetypeCount() > 0 || m.nextEntityIDSaved+m.pendingEntityIDs > 0 {
+		return eris.Wrap(ErrStateNotEmpty, "")
+	}
+
+	compsByName := map[string]types.ComponentMetadata{}
+	ids, err := m.typeToComponent.Keys()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		comp, err := m.typeToComponent.Get(id)
+		if err != nil {
+			return err
+		}
+		compsByName[comp.Name()] = comp
+	}
+
+	for i, arch := range snap.Archetypes {
+		// The snapshot's component order is needed to decode the values, but archetypes are made from sorted sets.
+		snapComps := make([]types.ComponentMetadata, 0, len(arch.Components))
+		for _, name := range arch.Components {
+			comp, ok := compsByName[name]
+			if !ok {
+				return eris.Wrapf(ErrComponentMismatchWithSavedState, "component %q is not registered", name)
+			}
+			snapComps = append(snapComps, comp)
+		}
+		comps := slices.Clone(snapComps)
+		if err := sortComponentSet(comps); err != nil {
+			return err
+		}
+		archID, err := m.getOrMakeArchIDForComponents(comps)
+		if err != nil {
+			return err
+		}
+		if archID != types.ArchetypeID(i) {
+			return eris.Errorf("archetype %d of the snapshot has the same components as archetype %d", i, archID)
+		}
+		if err := m.restoreEntities(archID, snapComps, arch.Entities); err != nil {
+			return err
+		}
+	}
+	m.pendingEntityIDs = snap.NextEntityID
+	return nil
+}
+
+// restoreEntities adds the given entities to an archetype. comps are the archetype's components in the order of the
+// entities' component values.
+func (m *EntityCommandBuffer) restoreEntities(
+	archID types.ArchetypeID,
+	comps []types.ComponentMetadata,
+	entities []EntitySnapshot,
+) error {
+	active, err := m.getActiveEntities(archID)
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if len(entity.Components) != len(comps) {
+			return eris.Errorf("entity %d has %d component values but its archetype has %d components",
+				entity.ID, len(entity.Components), len(comps))
+		}
+		if err := m.entityIDToArchID.Set(entity.ID, archID); err != nil {
+			return err
+		}
+		if err := m.entityIDToOriginArchID.Set(entity.ID, doesNotExistArchetypeID); err != nil {
+			return err
+		}
+		for i, comp := range comps {
+			value, err := decodeFromSnapshot(comp, entity.Components[i])
+			if err != nil {
+				return err
+			}
+			if err := m.compValues.Set(compKey{comp.ID(), entity.ID}, value); err != nil {
+				return err
+			}
+		}
+		active.ids = append(active.ids, entity.ID)
+	}
+	return m.setActiveEntities(archID, active)
+}
+
+// encodeForSnapshot encodes the value of the given component of an entity with the component's codec. Values encoded
+// by custom codecs are not necessarily JSON, so they are stored as base64 JSON strings.
+func (m *EntityCommandBuffer) encodeForSnapshot(comp types.ComponentMetadata, id types.EntityID) (
+	json.RawMessage, error,
+) {
+	value, err := m.GetComponentForEntity(comp, id)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := comp.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	if !comp.HasCustomCodec() {
+		return bz, nil
+	}
+	return codec.Encode(bz)
+}
+
+// decodeFromSnapshot decodes a component value encoded by encodeForSnapshot.
+func decodeFromSnapshot(comp types.ComponentMetadata, raw json.RawMessage) (any, error) {
+	if !comp.HasCustomCodec() {
+		return comp.Decode(raw)
+	}
+	bz, err := codec.Decode[[]byte](raw)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to decode the value of component %q", comp.Name())
+	}
+	return comp.Decode(bz)
+}
@@ -59,10 +59,10 @@ func (m *EntityCommandBuffer) FinalizeTick(ctx context.Context) error {
 
 	m.pendingArchIDs = nil
 
-	if err := m.DiscardPending(); err != nil {
+	if err := m.clearPending(); err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
 		span.RecordError(err)
-		return eris.Wrap(err, "failed to discard pending state changes")
+		return eris.Wrap(err, "failed to clear pending state changes")
 	}
 
 	return nil
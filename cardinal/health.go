@@ -0,0 +1,45 @@
+package cardinal
+
+import (
+	"time"
+)
+
+// DefaultHealthStallThreshold is how long the tick loop may go without completing a tick before Health reports the
+// world as unhealthy, unless it is changed with WithHealthStallThreshold.
+const DefaultHealthStallThreshold = 10 * time.Second
+
+// HealthStatus is a snapshot of the liveness of the world. It can be encoded as the JSON body of a health check.
+type HealthStatus struct {
+	// Healthy is true if the game is running, the tick loop is advancing, and the base shard connection is up if
+	// the world is connected to a base shard.
+	Healthy bool `json:"healthy"`
+	// Tick is the tick that will be run next.
+	Tick uint64 `json:"tick"`
+	// TimeSinceLastTick is how long ago the last tick completed, or the game started if no tick has completed since.
+	TimeSinceLastTick time.Duration `json:"timeSinceLastTick"`
+	// PendingMessages is the number of messages waiting to be processed in the next tick.
+	PendingMessages int `json:"pendingMessages"`
+	// ShardConnected is true if the connection to the base shard is up. It is always false when the world is not
+	// connected to a base shard.
+	ShardConnected bool `json:"shardConnected"`
+}
+
+// Health reports whether the tick loop is alive and advancing. The tick loop is considered to have stalled if no tick
+// has completed within the health stall threshold (see WithHealthStallThreshold).
+func (w *World) Health() HealthStatus {
+	status := HealthStatus{
+		Healthy:           false,
+		Tick:              w.CurrentTick(),
+		TimeSinceLastTick: 0,
+		PendingMessages:   w.txPool.GetAmountOfTxs(),
+		ShardConnected:    w.router != nil && w.router.IsConnected(),
+	}
+	if lastTickAt := w.lastTickAt.Load(); lastTickAt != 0 {
+		status.TimeSinceLastTick = time.Since(time.Unix(0, lastTickAt))
+	}
+
+	status.Healthy = w.IsGameRunning() &&
+		status.TimeSinceLastTick <= w.healthStallThreshold &&
+		(w.router == nil || status.ShardConnected)
+	return status
+}
@@ -0,0 +1,65 @@
+package cardinal
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+
+	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
+)
+
+// Logger is a structured logger that the logs of the world can be routed to with WithLogger. keysAndValues holds the
+// fields of a log event as alternating keys and values, with the keys sorted.
+type Logger interface {
+	Debug(msg string, keysAndValues ...any)
+	Info(msg string, keysAndValues ...any)
+	Warn(msg string, keysAndValues ...any)
+	Error(msg string, keysAndValues ...any)
+}
+
+var _ zerolog.LevelWriter = loggerWriter{}
+
+// loggerWriter decodes the JSON log events written by a zerolog logger and passes them on to a Logger.
+type loggerWriter struct {
+	logger Logger
+}
+
+func (w loggerWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w loggerWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	fields := map[string]any{}
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return 0, eris.Wrap(err, "failed to decode log event")
+	}
+
+	msg, _ := fields[zerolog.MessageFieldName].(string)
+	delete(fields, zerolog.MessageFieldName)
+	delete(fields, zerolog.LevelFieldName)
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	keysAndValues := make([]any, 0, 2*len(keys)) //nolint:mnd // a key and a value per field
+	for _, key := range keys {
+		keysAndValues = append(keysAndValues, key, fields[key])
+	}
+
+	//nolint:exhaustive // All other levels are logged as info
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		w.logger.Debug(msg, keysAndValues...)
+	case zerolog.WarnLevel:
+		w.logger.Warn(msg, keysAndValues...)
+	case zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel:
+		w.logger.Error(msg, keysAndValues...)
+	default:
+		w.logger.Info(msg, keysAndValues...)
+	}
+	return len(p), nil
+}
@@ -1,12 +1,15 @@
 package cardinal
 
 import (
+	"cmp"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 
 	ethereumAbi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/invopop/jsonschema"
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/cardinal/abi"
@@ -24,14 +27,59 @@ var (
 	messageRegexp = regexp.MustCompile("^[a-zA-Z0-9][a-zA-Z0-9_-]*[a-zA-Z0-9]$")
 )
 
+// TxData is a message that is being processed along with the transaction it was submitted in. Tx is the transaction as
+// it was submitted, including its signature and persona tag, so that systems can apply their own checks to it. Messages
+// from the EVM base shard carry only a persona tag, as they were authorized by the EVM sender instead of a signature.
 type TxData[In any] struct {
 	Hash types.TxHash
 	Msg  In
 	Tx   *sign.Transaction
 }
 
+// Signature returns the hex encoded signature of the transaction the message was submitted in.
+func (t TxData[In]) Signature() string {
+	if t.Tx == nil {
+		return ""
+	}
+	return t.Tx.Signature
+}
+
+// PersonaTag returns the persona tag that signed the transaction the message was submitted in.
+func (t TxData[In]) PersonaTag() string {
+	if t.Tx == nil {
+		return ""
+	}
+	return t.Tx.PersonaTag
+}
+
 type MessageOption[In, Out any] func(mt *MessageType[In, Out])
 
+// MessageCodec encodes and decodes the bodies of a message. Messages use JSON unless they are registered with a
+// custom codec, which can be used to submit message bodies in a more compact format such as msgpack or protobuf.
+type MessageCodec[In any] interface {
+	Marshal(In) ([]byte, error)
+	Unmarshal([]byte) (In, error)
+}
+
+// MessageVersionMismatchError is returned when decoding the body of a versioned message that was encoded with a
+// different version of the message.
+type MessageVersionMismatchError struct {
+	MessageName     string
+	ExpectedVersion int
+	ActualVersion   int
+}
+
+func (e *MessageVersionMismatchError) Error() string {
+	return fmt.Sprintf("message %q has version %d, but version %d was expected",
+		e.MessageName, e.ActualVersion, e.ExpectedVersion)
+}
+
+// versionedMessageBody is the encoding of the body of a versioned message.
+type versionedMessageBody struct {
+	Version int             `json:"version"`
+	Body    json.RawMessage `json:"body"`
+}
+
 // MessageType manages a user defined state transition message struct.
 type MessageType[In, Out any] struct {
 	id         types.MessageID
@@ -40,6 +88,12 @@ type MessageType[In, Out any] struct {
 	group      string
 	inEVMType  *ethereumAbi.Type
 	outEVMType *ethereumAbi.Type
+	// version is the version of the message's In type. Zero means the message is not versioned.
+	version int
+	// codec encodes the message's In type. Nil means the message is encoded as JSON.
+	codec MessageCodec[In]
+	// priority is the priority lane of the message. See WithMessagePriority.
+	priority int
 }
 
 // NewMessageType creates a new message type. It accepts two generic type parameters: the first for the message input,
@@ -129,9 +183,42 @@ func (t *MessageType[In, Out]) GetReceipt(wCtx WorldContext, hash types.TxHash)
 	return value, errs, true
 }
 
+// Each calls fn for every message of this type in the current tick. The result returned by fn is saved as the result of
+// the message's receipt; an error returned by fn, or a panic in fn, is saved as an error of the receipt instead, along
+// with its stack trace, and does not stop the other messages from being processed. If the world has a tick budget (see
+// WithTickBudget) that is exceeded, the messages that are left are deferred to the next tick instead of calling fn.
 func (t *MessageType[In, Out]) Each(wCtx WorldContext, fn func(TxData[In]) (Out, error)) {
+	_ = t.each(wCtx, fn, false)
+}
+
+// each is Each, but if isolated is set, each message is handled under its own savepoint, so that the changes made
+// while handling a message are undone if fn returns an error or panics (see runRecovering). Messages handled by a
+// system are not isolated, as they are already handled under the savepoint of the system. An error is returned if the
+// changes of a message could not be undone.
+func (t *MessageType[In, Out]) each(wCtx WorldContext, fn func(TxData[In]) (Out, error), isolated bool) error {
+	budget := tickBudgetOf(wCtx)
+	var deferred []types.TxHash
 	for _, txData := range t.In(wCtx) {
-		if result, err := fn(txData); err != nil {
+		if budget.overBudget(txData.Hash) {
+			deferred = append(deferred, txData.Hash)
+			continue
+		}
+		var result Out
+		var err error
+		if isolated {
+			var panicErr, undoErr error
+			err, panicErr, undoErr = runRecovering(wCtx, "message handler", true, func() (fnErr error) {
+				result, fnErr = fn(txData)
+				return fnErr
+			})
+			if undoErr != nil {
+				return undoErr
+			}
+			err = cmp.Or(panicErr, err)
+		} else {
+			result, err = callMessageHandler(wCtx, fn, txData)
+		}
+		if err != nil {
 			err = eris.Wrap(err, "")
 			wCtx.Logger().Err(err).Msgf("tx %s from %s encountered an error with message=%+v and stack trace:\n %s",
 				txData.Hash,
@@ -144,6 +231,35 @@ func (t *MessageType[In, Out]) Each(wCtx WorldContext, fn func(TxData[In]) (Out,
 			t.SetResult(wCtx, txData.Hash, result)
 		}
 	}
+	if len(deferred) > 0 {
+		wCtx.getWorld().deferMessages(wCtx.getTxPool(), deferred)
+	}
+	return nil
+}
+
+// callMessageHandler calls fn with the given message, turning a panic in fn into an error. Like in
+// runSystemRecovering, panics caused by fatal errors are not recovered, and neither are any panics if the world was
+// created with WithStrictSystemPanics.
+func callMessageHandler[In, Out any](
+	wCtx WorldContext, fn func(TxData[In]) (Out, error), txData TxData[In],
+) (result Out, err error) {
+	w := wCtx.getWorld()
+	if w == nil || w.strictSystemPanics {
+		return fn(txData)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if w.fatalPanic.Load() {
+				panic(r)
+			}
+			if panicErr, ok := r.(error); ok {
+				err = eris.Wrap(panicErr, "message handler panicked")
+			} else {
+				err = eris.Errorf("message handler panicked: %v", r)
+			}
+		}
+	}()
+	return fn(txData)
 }
 
 // In extracts all the TxData in the tx pool that match this MessageType's ID.
@@ -162,12 +278,84 @@ func (t *MessageType[In, Out]) In(wCtx WorldContext) []TxData[In] {
 	return txs
 }
 
+// Encode encodes the given value as JSON. This is the same representation that is used for message bodies submitted
+// over HTTP and for the message bodies stored on the base shard, so no separate JSON encoding is needed.
+// If the message has a custom codec (see WithMessageCodec), the value is encoded with the codec instead, and the
+// encoded bytes are stored as a base64 JSON string.
+// If the message is versioned (see WithMessageVersion), the value is wrapped in an object that also holds the version:
+// {"version": <version>, "body": <value>}.
 func (t *MessageType[In, Out]) Encode(a any) ([]byte, error) {
-	return codec.Encode(a)
+	body, err := t.encodeBody(a)
+	if err != nil || t.version == 0 {
+		return body, err
+	}
+	return codec.Encode(versionedMessageBody{Version: t.version, Body: body})
 }
 
+// Decode decodes a JSON message body into the message's In type. Field names are matched using the In type's json
+// struct tags, or the field names themselves when there are no tags. If the message has a custom codec, the body must
+// be a base64 JSON string holding the bytes encoded by the codec.
+// If the message is versioned, a *MessageVersionMismatchError is returned when the body was encoded with a different
+// version of the message.
 func (t *MessageType[In, Out]) Decode(bytes []byte) (any, error) {
-	return codec.Decode[In](bytes)
+	if t.version == 0 {
+		return t.decodeBody(bytes)
+	}
+	versioned, err := codec.Decode[versionedMessageBody](bytes)
+	if err != nil {
+		return nil, err
+	}
+	if versioned.Version != t.version {
+		return nil, &MessageVersionMismatchError{
+			MessageName:     t.FullName(),
+			ExpectedVersion: t.version,
+			ActualVersion:   versioned.Version,
+		}
+	}
+	return t.decodeBody(versioned.Body)
+}
+
+func (t *MessageType[In, Out]) encodeBody(a any) ([]byte, error) {
+	if t.codec == nil {
+		return codec.Encode(a)
+	}
+	var in In
+	switch v := a.(type) {
+	case In:
+		in = v
+	case *In:
+		if v == nil {
+			return nil, eris.Errorf("cannot encode a nil body for message %q", t.FullName())
+		}
+		in = *v
+	default:
+		return nil, eris.Errorf("cannot encode a value of type %T as message %q", a, t.FullName())
+	}
+	bz, err := t.codec.Marshal(in)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to encode message %q", t.FullName())
+	}
+	return codec.Encode(bz)
+}
+
+func (t *MessageType[In, Out]) decodeBody(body []byte) (any, error) {
+	if t.codec == nil {
+		return codec.Decode[In](body)
+	}
+	bz, err := codec.Decode[[]byte](body)
+	if err != nil {
+		return nil, eris.Wrapf(err, "body of message %q must be a base64 string", t.FullName())
+	}
+	in, err := t.codec.Unmarshal(bz)
+	if err != nil {
+		return nil, eris.Wrapf(err, "failed to decode message %q", t.FullName())
+	}
+	return in, nil
+}
+
+// Version returns the version of the message, or zero if the message is not versioned.
+func (t *MessageType[In, Out]) Version() int {
+	return t.version
 }
 
 // ABIEncode encodes the input to the message's matching evm type. If the input is not either of the message's
@@ -220,6 +408,27 @@ func (t *MessageType[In, Out]) GetInFieldInformation() map[string]any {
 	return types.GetFieldInformation(reflect.TypeOf(new(In)).Elem())
 }
 
+// GetInSchema returns the JSON schema of the message's "In" type.
+func (t *MessageType[In, Out]) GetInSchema() ([]byte, error) {
+	return reflectJSONSchema[In]()
+}
+
+// GetOutSchema returns the JSON schema of the message's "Out" type.
+func (t *MessageType[In, Out]) GetOutSchema() ([]byte, error) {
+	return reflectJSONSchema[Out]()
+}
+
+// reflectJSONSchema returns the JSON schema of T. The fields of T are described at the top level of the schema, and
+// the struct types it refers to are described in its definitions.
+func reflectJSONSchema[T any]() ([]byte, error) {
+	reflector := jsonschema.Reflector{ExpandedStruct: true}
+	schema, err := reflector.ReflectFromType(reflect.TypeOf(new(T)).Elem()).MarshalJSON()
+	if err != nil {
+		return nil, eris.Wrap(err, "message type must be json serializable")
+	}
+	return schema, nil
+}
+
 // -------------------------- Options --------------------------
 
 func WithMsgEVMSupport[In, Out any]() MessageOption[In, Out] {
@@ -249,6 +458,25 @@ func WithCustomMessageGroup[In, Out any](group string) MessageOption[In, Out] {
 	}
 }
 
+// WithMessageVersion versions the message so that bodies encoded with an older version of the message's In type are
+// rejected with a *MessageVersionMismatchError instead of being silently decoded into the new type. Versioned message
+// bodies must be submitted in the form {"version": <version>, "body": <message>}. The version must be positive.
+func WithMessageVersion[In, Out any](version int) MessageOption[In, Out] {
+	return func(mt *MessageType[In, Out]) {
+		if version <= 0 {
+			panic(fmt.Sprintf("Invalid MessageType: %q: version must be positive, got %d", mt.name, version))
+		}
+		mt.version = version
+	}
+}
+
+// WithMessageCodec encodes and decodes the message's bodies with the given codec instead of JSON.
+func WithMessageCodec[In, Out any](c MessageCodec[In]) MessageOption[In, Out] {
+	return func(mt *MessageType[In, Out]) {
+		mt.codec = c
+	}
+}
+
 // -------------------------- Helpers --------------------------
 
 func isStruct[T any]() bool {
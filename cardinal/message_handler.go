@@ -0,0 +1,97 @@
+package cardinal
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// messageHandler is a handler registered with RegisterMessageHandler, along with the priority of its message type.
+type messageHandler struct {
+	msgID    types.MessageID
+	msgName  string
+	priority int
+	handle   func(wCtx WorldContext) error
+}
+
+// WithMessagePriority puts the message in a priority lane. The messages of the lanes with a higher priority are handled
+// first by the handlers registered with RegisterMessageHandler, so that critical messages, e.g. disconnects, are
+// handled before routine ones. Messages registered without a priority have a priority of 0.
+func WithMessagePriority[In, Out any](priority int) MessageOption[In, Out] {
+	return func(mt *MessageType[In, Out]) {
+		mt.priority = priority
+	}
+}
+
+// RegisterMessageHandler registers a handler that is called with every message of type In in each tick, in the same
+// way as with EachMessage, with its result or error saved in the message's receipt. Unlike with EachMessage in a
+// system, Cardinal decides when the handler runs: at the start of every tick, after the deferred events are delivered
+// and, on tick 0, the init systems have run, and before any other system runs, the messages of all the message types
+// with handlers are drained one priority lane at a time, starting with the lane with the highest priority (see
+// WithMessagePriority). Each message is handled under its own savepoint: if the handler returns an error or panics,
+// the changes it made while handling that message are undone and the tick events it emitted are dropped, as for a
+// system that panics.
+//
+// Within a lane, the messages of a type are handled in the order they were submitted, and the message types in the
+// order they were registered. This order only depends on the messages of the tick, so it is the same when the tick is
+// recovered or replayed. Each message type can have at most one handler, which must be registered before the world
+// starts, after the message itself.
+func RegisterMessageHandler[In, Out any](
+	w *World, handler func(wCtx WorldContext, tx TxData[In]) (Out, error),
+) error {
+	var msg MessageType[In, Out]
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"failed to register message handler for %s: world state is %s, expected %s",
+			reflect.TypeOf(msg), w.worldStage.Current(), worldstage.Init,
+		)
+	}
+	if handler == nil {
+		return eris.Errorf("failed to register message handler for %s: handler must not be nil", reflect.TypeOf(msg))
+	}
+	registered, ok := w.GetMessageByType(reflect.TypeOf(msg))
+	if !ok {
+		return eris.Errorf("failed to register message handler: message %s is not registered", reflect.TypeOf(msg))
+	}
+	msgType, ok := registered.(*MessageType[In, Out])
+	if !ok {
+		return eris.Errorf("failed to register message handler: message %s has the wrong type", reflect.TypeOf(msg))
+	}
+	if slices.ContainsFunc(w.messageHandlers, func(h messageHandler) bool { return h.msgID == msgType.ID() }) {
+		return eris.Errorf("message %q already has a handler", msgType.FullName())
+	}
+	w.messageHandlers = append(w.messageHandlers, messageHandler{
+		msgID:    msgType.ID(),
+		msgName:  msgType.FullName(),
+		priority: msgType.priority,
+		handle: func(wCtx WorldContext) error {
+			return msgType.each(wCtx, func(tx TxData[In]) (Out, error) {
+				return handler(wCtx, tx)
+			}, true)
+		},
+	})
+	// Message IDs are assigned in order of registration.
+	slices.SortStableFunc(w.messageHandlers, func(a, b messageHandler) int {
+		return cmp.Or(cmp.Compare(b.priority, a.priority), cmp.Compare(a.msgID, b.msgID))
+	})
+	return nil
+}
+
+// runMessageHandlers drains the messages of the current tick that have handlers, one priority lane at a time.
+// An error is returned if the changes of a message that failed could not be undone.
+func (w *World) runMessageHandlers(wCtx WorldContext) error {
+	logger := wCtx.Logger()
+	defer wCtx.setLogger(*logger)
+	for _, h := range w.messageHandlers {
+		wCtx.setLogger(logger.With().Str("message_handler", h.msgName).Logger())
+		if err := h.handle(wCtx); err != nil {
+			return eris.Wrapf(err, "message handler for %q failed", h.msgName)
+		}
+	}
+	return nil
+}
@@ -3,17 +3,22 @@ package cardinal
 import (
 	"errors"
 	"reflect"
+	"strings"
 
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/types"
 )
 
+var ErrMessageAlreadyRegistered = errors.New("message is already registered")
+
 type MessageManager interface {
 	RegisterMessage(msgType types.Message, msgReflectType reflect.Type) error
 	GetRegisteredMessages() []types.Message
 	GetMessageByID(id types.MessageID) types.Message
 	GetMessageByFullName(fullName string) (types.Message, bool)
+	GetMessageByName(name string) (types.Message, bool)
 	GetMessageByType(mType reflect.Type) (types.Message, bool)
 }
 
@@ -21,6 +26,7 @@ type messageManager struct {
 	// registeredMessages maps message FullNames to a types.Message.
 	registeredMessages       map[string]types.Message
 	registeredMessagesByType map[reflect.Type]types.Message
+	registeredMessagesByID   map[types.MessageID]types.Message
 	nextMessageID            types.MessageID
 }
 
@@ -28,6 +34,7 @@ func newMessageManager() MessageManager {
 	return &messageManager{
 		registeredMessages:       map[string]types.Message{},
 		registeredMessagesByType: map[reflect.Type]types.Message{},
+		registeredMessagesByID:   map[types.MessageID]types.Message{},
 		nextMessageID:            1,
 	}
 }
@@ -48,6 +55,7 @@ func (m *messageManager) RegisterMessage(msgType types.Message, msgReflectType r
 
 	m.registeredMessages[fullName] = msgType
 	m.registeredMessagesByType[msgReflectType] = msgType
+	m.registeredMessagesByID[msgType.ID()] = msgType
 	m.nextMessageID++
 
 	return nil
@@ -62,15 +70,9 @@ func (m *messageManager) GetRegisteredMessages() []types.Message {
 	return msgs
 }
 
-// GetMessageByID iterates over the all registered messages and returns the types.Message associated with the
-// MessageID.
+// GetMessageByID returns the types.Message associated with the MessageID, or nil if there is none.
 func (m *messageManager) GetMessageByID(id types.MessageID) types.Message {
-	for _, msg := range m.registeredMessages {
-		if id == msg.ID() {
-			return msg
-		}
-	}
-	return nil
+	return m.registeredMessagesByID[id]
 }
 
 // GetMessageByFullName returns the message with the given full name, if it exists.
@@ -79,16 +81,40 @@ func (m *messageManager) GetMessageByFullName(fullName string) (types.Message, b
 	return msg, ok
 }
 
+// GetMessageByName returns the message with the given name (without its group), if it exists. Since messages in
+// different groups may share a name, false is also returned when more than one message has the name. Use
+// GetMessageByFullName to look up such messages.
+func (m *messageManager) GetMessageByName(name string) (types.Message, bool) {
+	var found types.Message
+	for _, msg := range m.registeredMessages {
+		if msg.Name() != name {
+			continue
+		}
+		if found != nil {
+			return nil, false
+		}
+		found = msg
+	}
+	return found, found != nil
+}
+
 func (m *messageManager) GetMessageByType(mType reflect.Type) (types.Message, bool) {
 	msg, ok := m.registeredMessagesByType[mType]
 	return msg, ok
 }
 
-// isMessageFullNameUnique checks if the message name already exist in messages map.
+// isMessageFullNameUnique checks if the message name already exist in messages map. The check is case-sensitive, but
+// a warning is logged if a message whose name only differs in case is already registered since that is likely a typo.
 func (m *messageManager) isMessageFullNameUnique(fullName string) error {
 	_, ok := m.registeredMessages[fullName]
 	if ok {
-		return eris.Errorf("message %q is already registered", fullName)
+		return eris.Wrapf(ErrMessageAlreadyRegistered, "message %q is already registered", fullName)
+	}
+	for registeredName := range m.registeredMessages {
+		if strings.EqualFold(registeredName, fullName) {
+			log.Warn().Msgf("message %q is being registered, but a message named %q that only differs in case "+
+				"is already registered", fullName, registeredName)
+		}
 	}
 	return nil
 }
@@ -2,10 +2,14 @@ package cardinal
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
 
@@ -52,6 +56,151 @@ func TestCanEncodeDecodeEVMTransactions(t *testing.T) {
 	assert.DeepEqual(t, f, msg)
 }
 
+func TestMessageBodyRoundTripsThroughJSON(t *testing.T) {
+	type MoveInput struct {
+		X         int
+		Direction string `json:"direction"`
+	}
+	msg := NewMessageType[MoveInput, EmptyMsgResult]("move")
+
+	// A body written by hand, as a web client would submit it, can be decoded.
+	decoded, err := msg.Decode([]byte(`{"X": 3, "direction": "north"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, decoded, MoveInput{X: 3, Direction: "north"})
+
+	// Encoding produces the same JSON representation.
+	bz, err := msg.Encode(MoveInput{X: 3, Direction: "north"})
+	assert.NilError(t, err)
+	assert.Equal(t, string(bz), `{"X":3,"direction":"north"}`)
+	decoded, err = msg.Decode(bz)
+	assert.NilError(t, err)
+	assert.Equal(t, decoded, MoveInput{X: 3, Direction: "north"})
+}
+
+func TestVersionedMessageRejectsOtherVersions(t *testing.T) {
+	type MoveInputV1 struct {
+		X int
+	}
+	type MoveInputV2 struct {
+		X int
+		Y int
+	}
+	msgV1 := NewMessageType[MoveInputV1, EmptyMsgResult]("move", WithMessageVersion[MoveInputV1, EmptyMsgResult](1))
+	msgV2 := NewMessageType[MoveInputV2, EmptyMsgResult]("move", WithMessageVersion[MoveInputV2, EmptyMsgResult](2))
+	assert.Equal(t, 1, msgV1.Version())
+
+	bz, err := msgV1.Encode(MoveInputV1{X: 3})
+	assert.NilError(t, err)
+	assert.Equal(t, string(bz), `{"version":1,"body":{"X":3}}`)
+	decoded, err := msgV1.Decode(bz)
+	assert.NilError(t, err)
+	assert.Equal(t, decoded, MoveInputV1{X: 3})
+
+	_, err = msgV2.Decode(bz)
+	var mismatchErr *MessageVersionMismatchError
+	assert.Check(t, errors.As(err, &mismatchErr))
+	assert.Equal(t, mismatchErr.ExpectedVersion, 2)
+	assert.Equal(t, mismatchErr.ActualVersion, 1)
+
+	assert.Panics(t, func() {
+		NewMessageType[MoveInputV1, EmptyMsgResult]("move", WithMessageVersion[MoveInputV1, EmptyMsgResult](0))
+	})
+}
+
+func TestGetMessageByNameAndID(t *testing.T) {
+	type MoveInput struct{}
+	type OtherMoveInput struct{}
+	type AttackInput struct{}
+	manager := newMessageManager()
+	move := NewMessageType[MoveInput, EmptyMsgResult]("move")
+	otherMove := NewMessageType[OtherMoveInput, EmptyMsgResult](
+		"move", WithCustomMessageGroup[OtherMoveInput, EmptyMsgResult]("other"))
+	attack := NewMessageType[AttackInput, EmptyMsgResult]("attack")
+	for _, msg := range []types.Message{move, otherMove, attack} {
+		assert.NilError(t, manager.RegisterMessage(msg, reflect.TypeOf(msg).Elem()))
+	}
+
+	got, ok := manager.GetMessageByName("attack")
+	assert.Check(t, ok)
+	assert.Equal(t, got, types.Message(attack))
+
+	_, ok = manager.GetMessageByName("jump")
+	assert.Check(t, !ok)
+
+	// "move" exists in two groups, so it can only be looked up by its full name.
+	_, ok = manager.GetMessageByName("move")
+	assert.Check(t, !ok)
+	got, ok = manager.GetMessageByFullName("other.move")
+	assert.Check(t, ok)
+	assert.Equal(t, got, types.Message(otherMove))
+
+	// Every message gets its own ID, even when names collide.
+	assert.Check(t, move.ID() != otherMove.ID())
+	for _, msg := range []types.Message{move, otherMove, attack} {
+		assert.Equal(t, manager.GetMessageByID(msg.ID()), msg)
+	}
+	assert.Check(t, manager.GetMessageByID(types.MessageID(1000)) == nil)
+}
+
+func TestMessageNamesMustBeUnique(t *testing.T) {
+	type MoveInput struct{}
+	type OtherMoveInput struct{}
+	type CapitalMoveInput struct{}
+	manager := newMessageManager()
+	move := NewMessageType[MoveInput, EmptyMsgResult]("move")
+	assert.NilError(t, manager.RegisterMessage(move, reflect.TypeOf(*move)))
+
+	otherMove := NewMessageType[OtherMoveInput, EmptyMsgResult]("move")
+	err := manager.RegisterMessage(otherMove, reflect.TypeOf(*otherMove))
+	assert.ErrorIs(t, err, ErrMessageAlreadyRegistered)
+	assert.ErrorContains(t, err, `message "game.move" is already registered`)
+
+	// Names are case-sensitive.
+	capitalMove := NewMessageType[CapitalMoveInput, EmptyMsgResult]("Move")
+	assert.NilError(t, manager.RegisterMessage(capitalMove, reflect.TypeOf(*capitalMove)))
+}
+
+func TestHighPriorityMessagesAreHandledFirst(t *testing.T) {
+	type MoveMsg struct{ Player string }
+	type DisconnectMsg struct{ Player string }
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterMessage[MoveMsg, EmptyMsgResult](world, "move"))
+	assert.NilError(t, RegisterMessage[DisconnectMsg, EmptyMsgResult](world, "disconnect",
+		WithMessagePriority[DisconnectMsg, EmptyMsgResult](10)))
+	var handled []string
+	assert.NilError(t, RegisterMessageHandler(world,
+		func(_ WorldContext, tx TxData[MoveMsg]) (EmptyMsgResult, error) {
+			handled = append(handled, "move "+tx.Msg.Player)
+			return EmptyMsgResult{}, nil
+		}))
+	assert.NilError(t, RegisterMessageHandler(world,
+		func(_ WorldContext, tx TxData[DisconnectMsg]) (EmptyMsgResult, error) {
+			handled = append(handled, "disconnect "+tx.Msg.Player)
+			return EmptyMsgResult{}, nil
+		}))
+	assert.ErrorContains(t, RegisterMessageHandler(world,
+		func(WorldContext, TxData[MoveMsg]) (EmptyMsgResult, error) {
+			return EmptyMsgResult{}, nil
+		}), "already has a handler")
+	tf.StartWorld()
+
+	moveMsg, ok := world.GetMessageByFullName("game.move")
+	assert.True(t, ok)
+	disconnectMsg, ok := world.GetMessageByFullName("game.disconnect")
+	assert.True(t, ok)
+	_, _, err := world.AddTransaction(moveMsg.ID(), MoveMsg{Player: "alice"}, &sign.Transaction{PersonaTag: "alice"})
+	assert.NilError(t, err)
+	_, _, err = world.AddTransaction(disconnectMsg.ID(), DisconnectMsg{Player: "bob"},
+		&sign.Transaction{PersonaTag: "bob"})
+	assert.NilError(t, err)
+	_, _, err = world.AddTransaction(moveMsg.ID(), MoveMsg{Player: "carol"}, &sign.Transaction{PersonaTag: "carol"})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	assert.DeepEqual(t, handled, []string{"disconnect bob", "move alice", "move carol"})
+}
+
 func TestCannotDecodeEVMBeforeSetEVM(t *testing.T) {
 	type foo struct{}
 	msg := NewMessageType[foo, EmptyMsgResult]("foo")
@@ -192,3 +341,59 @@ func TestIsValidMessageText(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageHandlersRunAfterInitSystemsAndUndoFailedMessages(t *testing.T) {
+	type SpawnMsg struct{ Outcome string }
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[ScalarComponentStatic](world))
+	assert.NilError(t, RegisterMessage[SpawnMsg, EmptyMsgResult](world, "spawn"))
+	assert.NilError(t, RegisterInitSystems(world, func(wCtx WorldContext) error {
+		_, err := Create(wCtx, ScalarComponentStatic{Val: 1})
+		return err
+	}))
+	var seenByHandler []int
+	assert.NilError(t, RegisterMessageHandler(world,
+		func(wCtx WorldContext, tx TxData[SpawnMsg]) (EmptyMsgResult, error) {
+			count, err := NewSearch().Entity(filter.All()).Count(wCtx)
+			if err != nil {
+				return EmptyMsgResult{}, err
+			}
+			seenByHandler = append(seenByHandler, count)
+			if _, err := Create(wCtx, ScalarComponentStatic{Val: 2}); err != nil {
+				return EmptyMsgResult{}, err
+			}
+			switch tx.Msg.Outcome {
+			case "error":
+				return EmptyMsgResult{}, errors.New("spawn failed")
+			case "panic":
+				panic("spawn panicked")
+			}
+			return EmptyMsgResult{}, nil
+		}))
+	tf.StartWorld()
+
+	spawnMsg, ok := world.GetMessageByFullName("game.spawn")
+	assert.True(t, ok)
+	var hashes []types.TxHash
+	for i, outcome := range []string{"error", "panic", "ok"} {
+		_, hash, err := world.AddTransaction(spawnMsg.ID(), SpawnMsg{Outcome: outcome},
+			&sign.Transaction{PersonaTag: "alice", Salt: uint16(i)}) //nolint:gosec // i is small
+		assert.NilError(t, err)
+		hashes = append(hashes, hash)
+	}
+	tf.DoTick()
+
+	// The handler sees the entity of the init system, but none of the entities of the messages that failed.
+	assert.DeepEqual(t, seenByHandler, []int{1, 1, 1})
+	count, err := NewSearch().Entity(filter.All()).Count(NewReadOnlyWorldContext(world))
+	assert.NilError(t, err)
+	assert.Equal(t, count, 2)
+
+	for i, want := range []string{"spawn failed", "message handler panicked: spawn panicked"} {
+		rec, ok := world.ReceiptByTxHash(hashes[i])
+		assert.True(t, ok)
+		assert.Equal(t, len(rec.Errs), 1)
+		assert.ErrorContains(t, rec.Errs[0], want)
+	}
+}
@@ -0,0 +1,81 @@
+// Package metrics exports measurements of a cardinal world's game loop to Prometheus. It is a separate package so that
+// games that don't use Prometheus don't depend on it.
+//
+// Usage:
+//
+//	recorder, err := metrics.NewRecorder(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	world, err := cardinal.NewWorld(cardinal.WithMetricsRecorder(recorder))
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+const namespace = "cardinal"
+
+var _ cardinal.MetricsRecorder = &Recorder{}
+
+// Recorder is a cardinal.MetricsRecorder that records the measurements of the game loop with Prometheus collectors.
+type Recorder struct {
+	tickDuration      prometheus.Histogram
+	messagesProcessed prometheus.Counter
+	queueDepth        prometheus.Gauge
+	systemDuration    *prometheus.HistogramVec
+}
+
+// NewRecorder creates a Recorder and registers its collectors with the given registerer. An error is returned if the
+// collectors can't be registered, e.g. because they are already registered. Pass the Recorder to the world with
+// cardinal.WithMetricsRecorder.
+func NewRecorder(registerer prometheus.Registerer) (*Recorder, error) {
+	r := &Recorder{
+		tickDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "tick_duration_seconds",
+			Help:      "Time it takes to run a tick, including committing its changes.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		messagesProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_processed_total",
+			Help:      "Number of messages that have been processed by ticks.",
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "message_queue_depth",
+			Help:      "Number of messages that were waiting to be processed at the start of the latest tick.",
+		}),
+		systemDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "system_duration_seconds",
+			Help:      "Time it takes to run a system in a tick.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"system"}),
+	}
+	for _, c := range []prometheus.Collector{r.tickDuration, r.messagesProcessed, r.queueDepth, r.systemDuration} {
+		if err := registerer.Register(c); err != nil {
+			return nil, eris.Wrap(err, "failed to register cardinal metrics")
+		}
+	}
+	return r, nil
+}
+
+func (r *Recorder) SetQueueDepth(depth int) {
+	r.queueDepth.Set(float64(depth))
+}
+
+func (r *Recorder) ObserveTick(duration time.Duration, messages int) {
+	r.tickDuration.Observe(duration.Seconds())
+	r.messagesProcessed.Add(float64(messages))
+}
+
+func (r *Recorder) ObserveSystem(name string, duration time.Duration) {
+	r.systemDuration.WithLabelValues(name).Observe(duration.Seconds())
+}
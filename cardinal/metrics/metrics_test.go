@@ -0,0 +1,52 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/metrics"
+)
+
+func TestRecorderObservesTicks(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	recorder, err := metrics.NewRecorder(registry)
+	assert.NilError(t, err)
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithMetricsRecorder(recorder))
+	assert.NilError(t, cardinal.RegisterSystem(tf.World, "noop", func(cardinal.WorldContext) error {
+		return nil
+	}))
+	tf.StartWorld()
+	tf.DoTick()
+
+	families, err := registry.Gather()
+	assert.NilError(t, err)
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+		switch family.GetName() {
+		case "cardinal_tick_duration_seconds":
+			assert.Check(t, family.GetMetric()[0].GetHistogram().GetSampleCount() >= 1)
+		case "cardinal_system_duration_seconds":
+			systems := map[string]bool{}
+			for _, m := range family.GetMetric() {
+				systems[m.GetLabel()[0].GetValue()] = true
+			}
+			assert.Check(t, systems["noop"])
+		}
+	}
+	assert.Check(t, found["cardinal_tick_duration_seconds"])
+	assert.Check(t, found["cardinal_system_duration_seconds"])
+	assert.Check(t, found["cardinal_messages_processed_total"])
+	assert.Check(t, found["cardinal_message_queue_depth"])
+}
+
+func TestNewRecorderFailsWhenAlreadyRegistered(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := metrics.NewRecorder(registry)
+	assert.NilError(t, err)
+	_, err = metrics.NewRecorder(registry)
+	assert.IsError(t, err)
+}
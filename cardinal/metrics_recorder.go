@@ -0,0 +1,25 @@
+package cardinal
+
+import "time"
+
+// MetricsRecorder receives measurements of the game loop, e.g. to export them as metrics. See WithMetricsRecorder, and
+// the metrics package for a recorder that exports them to Prometheus.
+type MetricsRecorder interface {
+	// SetQueueDepth is called at the start of each tick with the number of messages that are waiting to be processed.
+	SetQueueDepth(depth int)
+	// ObserveTick is called after each tick with the time it took and the number of messages it processed.
+	ObserveTick(duration time.Duration, messages int)
+	// ObserveSystem is called after each tick for each system that ran in it, with the time the system took.
+	ObserveSystem(name string, duration time.Duration)
+}
+
+// recordTickMetrics passes the measurements of the tick that just completed to the metrics recorder, if one is set.
+func (w *World) recordTickMetrics(duration time.Duration, messages int) {
+	if w.metrics == nil {
+		return
+	}
+	w.metrics.ObserveTick(duration, messages)
+	for name, systemDuration := range w.SystemTimings() {
+		w.metrics.ObserveSystem(name, systemDuration)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/alicebob/miniredis/v2"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/receipt"
@@ -16,9 +17,10 @@ import (
 
 // WorldOption represents an option that can be used to augment how the cardinal.World will be run.
 type WorldOption struct {
-	serverOption   server.Option
-	routerOption   router.Option
-	cardinalOption Option
+	serverOption    server.Option
+	routerOption    router.Option
+	cardinalOption  Option
+	gamestateOption gamestate.Option
 }
 
 type Option func(*World)
@@ -40,6 +42,130 @@ func WithReceiptHistorySize(size int) WorldOption {
 	}
 }
 
+// WithArchetypeInitialCapacity sets the capacity that the entity list of a new archetype starts with. By default,
+// entity lists start empty and grow on demand. Games that put a large number of entities into a single archetype can
+// use a larger capacity to avoid repeated reallocations as the archetype fills up.
+func WithArchetypeInitialCapacity(capacity int) WorldOption {
+	return WorldOption{
+		gamestateOption: gamestate.WithArchetypeInitialCapacity(capacity),
+	}
+}
+
+// WithArchetypeCreatedHook sets a hook that is called whenever a new combination of components (an archetype) is
+// formed in the world. This can be used to detect a growing number of archetypes, which hurts search performance.
+// An error returned by the hook is logged and does not prevent the archetype from being created.
+func WithArchetypeCreatedHook(hook gamestate.ArchetypeCreatedHook) WorldOption {
+	return WorldOption{
+		gamestateOption: gamestate.WithArchetypeCreatedHook(hook),
+	}
+}
+
+// WithDirtyComponentTracking makes the world record which components of which entities are set during each tick, so
+// that they can be retrieved with World.DirtyComponents. Tracking is off by default to avoid its overhead.
+func WithDirtyComponentTracking() WorldOption {
+	return WorldOption{
+		gamestateOption: gamestate.WithDirtyComponentTracking(),
+	}
+}
+
+// WithReceiptSink sets a sink that receipts are saved to when they age out of the in-memory receipt history (see
+// WithReceiptHistorySize). Receipts are saved from a background goroutine so that the tick loop is not blocked, unless
+// the sink falls far enough behind that the buffer of pending receipts fills up.
+func WithReceiptSink(sink receipt.Sink) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.receiptSink = sink
+		},
+	}
+}
+
+// WithStableIteration makes searches visit entities in ascending order of their IDs, instead of in the order they are
+// stored in their archetypes, which changes as entities are removed and depends on the history of the world. This is
+// needed when several nodes must run the same ticks in lockstep and visit entities in the same order. Searches sort
+// the matched entities every time they iterate, so this has a cost proportional to the number of matched entities.
+func WithStableIteration() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.stableIteration = true
+		},
+	}
+}
+
+// WithStrictSystemPanics makes a panic in a system crash the game loop, as is useful during development. By default, a
+// panicking system is logged and skipped for the rest of the tick, and the other systems and the following ticks still
+// run. Changes the system made to the world's state before it panicked are rolled back.
+// This also applies to message handlers run with EachMessage, whose panics are otherwise turned into an error receipt.
+// Panics caused by fatal errors, such as failing to read from or write to the world's state, always crash the loop.
+func WithStrictSystemPanics() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.strictSystemPanics = true
+		},
+	}
+}
+
+// WithSeed seeds the random number generator returned by WorldContext.Rand. With a seed, the generator of each tick is
+// derived from the seed and the tick number only, so replaying the same ticks with the same messages produces the same
+// random numbers. The seed and the tick number of the generator are included in snapshots, so a world restored from
+// a snapshot rolls the same numbers as the world the snapshot was taken from. Without a seed, the generator is seeded
+// with the timestamp of the tick.
+func WithSeed(seed int64) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.seed = &seed
+		},
+	}
+}
+
+// WithPreTickHook registers a hook that is run right before each tick. The messages it returns are processed in that
+// tick, after the messages that were already waiting, and their receipts are marked as internal. Messages are resolved
+// like in World.SubmitBatch and are not signed. If any of the returned messages is invalid, the error is logged and
+// none of the messages of that tick's hooks are processed. Hooks are not run for ticks that are recovered from the base
+// shard, since those ticks already include the messages that were injected when they first ran.
+func WithPreTickHook(hook PreTickHook) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.preTickHooks = append(world.preTickHooks, hook)
+		},
+	}
+}
+
+// WithPostTickHook registers a hook that is run after each tick, once all systems have run and the receipts of the
+// tick are final. The hook is called synchronously from the game loop with the number and timestamp of the completed
+// tick (the same as the ones submitted to the base shard) and its receipts. Hooks run in order of registration. An
+// error returned by a hook is logged and does not stop the game loop, unless WithStrictPostTickHooks is used.
+func WithPostTickHook(hook PostTickHook) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.postTickHooks = append(world.postTickHooks, hook)
+		},
+	}
+}
+
+// WithStrictPostTickHooks makes an error returned by a post tick hook (see WithPostTickHook) fail the tick, which stops
+// the game loop. So that a failed tick is never committed, strict hooks run once all systems have run but before the
+// changes of the tick are committed and submitted to the base shard. The world's state is locked until the changes are
+// committed, so strict hooks must not query the world.
+func WithStrictPostTickHooks() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.strictPostTickHooks = true
+		},
+	}
+}
+
+// WithShutdownHook registers a hook that is run when the world shuts down, after the game loop and the server have
+// stopped and before the storage connection is closed. Hooks run in reverse order of registration and must return
+// once their context is done (see ShutdownHookTimeout and ShutdownHooksTimeout). An error returned by a hook is logged
+// and does not prevent the remaining hooks from running.
+func WithShutdownHook(hook ShutdownHook) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.shutdownHooks = append(world.shutdownHooks, hook)
+		},
+	}
+}
+
 // WithDisableSignatureVerification disables signature verification for the HTTP server. This should only be
 // used for local development.
 func WithDisableSignatureVerification() WorldOption {
@@ -71,9 +197,9 @@ func WithHashCacheSize(sizeKB uint) WorldOption {
 	}
 }
 
-// WithTickChannel sets the channel that will be used to decide when world.doTick is executed. If unset, a loop interval
-// of 1 second will be set. To set some other time, use: WithTickChannel(time.Tick(<some-duration>)). Tests can pass
-// in a channel controlled by the test for fine-grained control over when ticks are executed.
+// WithTickChannel sets the channel that will be used to decide when world.doTick is executed. If unset, ticks run at
+// the tick rate set with WithTickRate or the CARDINAL_TICK_RATE config, or once per second if neither is set.
+// Tests can pass in a channel controlled by the test for fine-grained control over when ticks are executed.
 func WithTickChannel(ch <-chan time.Time) WorldOption {
 	return WorldOption{
 		cardinalOption: func(world *World) {
@@ -82,6 +208,25 @@ func WithTickChannel(ch <-chan time.Time) WorldOption {
 	}
 }
 
+// WithTickRate sets the number of ticks per second like the CARDINAL_TICK_RATE config does, and overrides it. Ticks run
+// at this fixed rate regardless of how many messages are waiting, and each tick processes the messages that were
+// received since the previous tick. If a tick takes longer than its time budget, the ticks that were missed in the
+// meantime are dropped rather than run back to back, so a slow tick can't make the game loop fall further and further
+// behind. Of WithTickRate and WithTickChannel, the option given last is used.
+func WithTickRate(ticksPerSecond int) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			if ticksPerSecond <= 0 {
+				log.Warn().Msgf("Ignoring tick rate of %d ticks per second; it must be positive", ticksPerSecond)
+				return
+			}
+			world.tickRate = uint64(ticksPerSecond)
+			// The tick channel is made from the tick rate once all options are applied.
+			world.tickChannel = nil
+		},
+	}
+}
+
 // WithTickDoneChannel sets a channel that will be notified each time a tick completes. The completed tick will be
 // pushed to the channel. This option is useful in tests when assertions need to be performed at the end of a tick.
 func WithTickDoneChannel(ch chan<- uint64) WorldOption {
@@ -126,6 +271,8 @@ func WithMockJobQueue() WorldOption {
 	}
 }
 
+// WithCustomLogger replaces the zerolog logger that is used for the logs of the world, including the loggers of world
+// contexts. To route the logs to a logger that isn't a zerolog logger, use WithLogger.
 func WithCustomLogger(logger zerolog.Logger) WorldOption {
 	return WorldOption{
 		cardinalOption: func(_ *World) {
@@ -134,6 +281,142 @@ func WithCustomLogger(logger zerolog.Logger) WorldOption {
 	}
 }
 
+// WithTracer sets the tracer provider that the spans of the world are started with, instead of the global tracer
+// provider. Each tick has a span, with a child span for the systems, which in turn has a child span per system. The
+// span of the running system is available to the system through WorldContext.Context.
+func WithTracer(tp trace.TracerProvider) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.tracer = tp.Tracer("world")
+			world.SystemManager.setTracer(tp.Tracer("system"))
+		},
+		gamestateOption: gamestate.WithTracerProvider(tp),
+	}
+}
+
+// WithMaxQueuedMessages caps the number of messages waiting to be processed in the next tick at n. Once the queue is
+// full, new messages are rejected with ErrMessageQueueFull, unless WithBlockWhenQueueFull is used. Messages generated
+// by the world itself and messages of recovered ticks are not subject to the cap. Zero or less means there is no cap,
+// which is the default.
+func WithMaxQueuedMessages(n int) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.txPool.SetMaxTxs(n)
+		},
+	}
+}
+
+// WithBlockWhenQueueFull makes submitting a message to a full message queue (see WithMaxQueuedMessages) wait until the
+// next tick takes the queued messages, instead of rejecting it with ErrMessageQueueFull. If the world shuts down in the
+// meantime, the message is rejected with ErrMessageQueueClosed.
+func WithBlockWhenQueueFull() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.txPool.SetBlockWhenFull(true)
+		},
+	}
+}
+
+// WithTxDedup drops transactions with the same hash as a transaction that was processed in the last windowTicks ticks
+// before they reach the systems, e.g. when the same transaction is delivered again by a retry. The dropped transaction
+// gets a receipt that is marked as a duplicate, unless it is in the same tick as the original transaction, in which
+// case the two share the receipt of the original. Non-positive values are ignored.
+func WithTxDedup(windowTicks int) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			if windowTicks <= 0 {
+				log.Warn().Msgf("ignoring non-positive transaction dedup window of %d ticks", windowTicks)
+				return
+			}
+			world.txDedup = newTxDeduplicator(uint64(windowTicks))
+		},
+	}
+}
+
+// WithTickBudget limits how long the messages of a tick are processed for. Once the budget, measured from the start of
+// the tick, is exceeded, MessageType.Each stops calling its function, and the messages that are left are carried over
+// to the next tick, where they are processed ahead of the messages submitted since. Their receipts in the tick they
+// were deferred in are marked as deferred. At least one message is processed in every tick, and messages that the
+// systems read with MessageType.In are not deferred. Deferred messages are kept in the world's state until the next
+// tick, so they survive restarts and are included in snapshots. Ticks that are recovered from the base shard or
+// replayed, and the final tick run when the world shuts down, always process all of their messages. Non-positive
+// values are ignored.
+func WithTickBudget(d time.Duration) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			if d <= 0 {
+				log.Warn().Msgf("ignoring non-positive tick budget of %s", d)
+				return
+			}
+			world.tickBudget = newTickBudget(d)
+		},
+	}
+}
+
+// WithSignatureVerifier sets a verifier that is called with the signed part of each transaction submitted with
+// World.AddTransaction (e.g. over HTTP) before it is queued. Transactions the verifier returns an error for are not
+// queued, and AddTransaction returns an error that wraps ErrSignatureRejected and the verifier's error. Messages from
+// the EVM base shard, which are authorized by their EVM sender, unsigned messages that are queued in-process with
+// World.SubmitBatch or ScheduleMessage, messages generated by the world itself, and the messages of recovered ticks are
+// not verified.
+func WithSignatureVerifier(verifier SignatureVerifier) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.signatureVerifier = verifier
+		},
+	}
+}
+
+// WithNonceProtection rejects submitted transactions, with ErrNonceNotIncreasing, unless their nonce is greater than
+// the nonce of the last transaction accepted from their persona tag, so that a signed transaction can't be replayed.
+// The nonce is part of the signed payload (see sign.NewTransactionWithNonce), and nonces start at 1. Messages from the
+// EVM base shard are authorized by their EVM sender instead, and are not subject to this.
+func WithNonceProtection() WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.nonceProtection = true
+		},
+	}
+}
+
+// WithHealthStallThreshold sets how long the tick loop may go without completing a tick before Health reports the world
+// as unhealthy. It should be comfortably longer than the tick interval. Non-positive values are ignored.
+func WithHealthStallThreshold(threshold time.Duration) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			if threshold <= 0 {
+				log.Warn().Msgf("ignoring non-positive health stall threshold %s", threshold)
+				return
+			}
+			world.healthStallThreshold = threshold
+		},
+	}
+}
+
+// WithMetricsRecorder passes measurements of the game loop (the number of waiting messages, and the duration and number
+// of messages of each tick and the duration of each system) to the given recorder. To export them to Prometheus, use
+// the metrics package.
+func WithMetricsRecorder(recorder MetricsRecorder) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			world.metrics = recorder
+		},
+	}
+}
+
+// WithLogger routes the logs of the world, including those of the tick loop and of the world contexts that systems
+// and queries log with, to the given logger. Each log event is passed on with its level, its message, and its fields as
+// keys and values. Unlike WithCustomLogger, the global logger is left as is, so other worlds in the same process, and
+// the logs that aren't specific to a world, are not affected. By default, logs are written to stderr.
+func WithLogger(logger Logger) WorldOption {
+	return WorldOption{
+		cardinalOption: func(world *World) {
+			zl := zerolog.New(loggerWriter{logger: logger})
+			world.logger = &zl
+		},
+	}
+}
+
 func WithCustomRouter(rtr router.Router) WorldOption {
 	return WorldOption{
 		cardinalOption: func(world *World) {
@@ -3,14 +3,21 @@ package cardinal_test
 import (
 	"io"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/goccy/go-json"
+	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 func TestOptionFunctionSignatures(_ *testing.T) {
@@ -44,3 +51,127 @@ func isValidJSON(bz []byte) bool {
 	var js map[string]interface{}
 	return json.Unmarshal(bz, &js) == nil
 }
+
+func TestWithArchetypeCreatedHook_FiresOncePerLayout(t *testing.T) {
+	var created [][]string
+	hook := func(_ types.ArchetypeID, comps []types.ComponentMetadata) error {
+		names := make([]string, 0, len(comps))
+		for _, comp := range comps {
+			names = append(names, comp.Name())
+		}
+		created = append(created, names)
+		// An error from the hook must not prevent the archetype from being created.
+		return eris.New("hook failure")
+	}
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithArchetypeCreatedHook(hook))
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+	created = nil
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.Create(wCtx, AlphaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 3, AlphaTest{})
+	assert.NilError(t, err)
+	id, err := cardinal.Create(wCtx, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.Create(wCtx, BetaTest{}, AlphaTest{})
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, created, [][]string{{"alpha"}, {"alpha", "beta"}})
+	comp, err := cardinal.GetComponent[BetaTest](wCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, *comp, BetaTest{})
+}
+
+type logEntry struct {
+	Level, Msg    string
+	KeysAndValues []any
+}
+
+// capturingLogger records log entries. The world logs from several goroutines, so access is synchronized.
+type capturingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *capturingLogger) log(level, msg string, kv []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level, msg, kv})
+}
+
+func (l *capturingLogger) Debug(msg string, kv ...any) { l.log("debug", msg, kv) }
+func (l *capturingLogger) Info(msg string, kv ...any)  { l.log("info", msg, kv) }
+func (l *capturingLogger) Warn(msg string, kv ...any)  { l.log("warn", msg, kv) }
+func (l *capturingLogger) Error(msg string, kv ...any) { l.log("error", msg, kv) }
+
+func TestWithLogger_RoutesTickLogsToLogger(t *testing.T) {
+	logger := &capturingLogger{}
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithLogger(logger))
+	tf.StartWorld()
+	tf.DoTick()
+
+	// The logger is scoped to the world.
+	log.Info().Msg("from global logger")
+	cardinal.NewWorldContext(tf.World).Logger().Info().Msg("from world context")
+
+	var tickLogs []logEntry
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	for _, entry := range logger.entries {
+		if entry.Msg == "Tick completed" {
+			tickLogs = append(tickLogs, entry)
+		}
+	}
+	assert.Equal(t, len(tickLogs), 1)
+	assert.Equal(t, tickLogs[0].Level, "info")
+	// Fields are passed on as keys and values, sorted by key.
+	kv := tickLogs[0].KeysAndValues
+	assert.Equal(t, len(kv), 6)
+	assert.DeepEqual(t, []any{kv[0], kv[2], kv[4]}, []any{"duration", "tick", "tx_count"})
+	assert.Equal(t, kv[3], json.Number("0"))
+	assert.Assert(t, slices.ContainsFunc(logger.entries, func(entry logEntry) bool {
+		return entry.Msg == "from world context"
+	}))
+	assert.Assert(t, !slices.ContainsFunc(logger.entries, func(entry logEntry) bool {
+		return entry.Msg == "from global logger"
+	}))
+}
+
+func TestWithTracer_TickSpanHasSystemChildSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tf := cardinal.NewTestFixture(t, nil, cardinal.WithTracer(tp))
+
+	tracedSystem := func(wCtx cardinal.WorldContext) error {
+		_, span := tp.Tracer("test").Start(wCtx.Context(), "inside.system")
+		span.End()
+		return nil
+	}
+	assert.NilError(t, cardinal.RegisterSystems(tf.World, tracedSystem))
+	tf.StartWorld()
+	tf.DoTick()
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	tickSpan, ok := spans["world.tick"]
+	assert.Assert(t, ok)
+	systemsSpan, ok := spans["system.run"]
+	assert.Assert(t, ok)
+	assert.Equal(t, systemsSpan.Parent.SpanID(), tickSpan.SpanContext.SpanID())
+
+	var systemSpan tracetest.SpanStub
+	for name, span := range spans {
+		if strings.HasPrefix(name, "system.run.") && strings.HasSuffix(name, "func1") {
+			systemSpan = span
+		}
+	}
+	assert.Equal(t, systemSpan.Parent.SpanID(), systemsSpan.SpanContext.SpanID())
+	// Spans started from the world context of a system are children of the system's span.
+	assert.Equal(t, spans["inside.system"].Parent.SpanID(), systemSpan.SpanContext.SpanID())
+}
@@ -0,0 +1,130 @@
+package cardinal
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+
+	"github.com/invopop/jsonschema"
+	"github.com/rotisserie/eris"
+)
+
+// OrderedMap is a map that iterates over its entries in ascending order of their keys. Go maps iterate in a random
+// order, so a system that iterates over a map held by a component can behave differently every time a tick is run,
+// which breaks replays. Components should hold an OrderedMap instead.
+//
+// OrderedMap is encoded as a JSON list of its entries in key order, so components that hold one are encoded the same
+// way every time, including in snapshots. Hold it as a named field of a component rather than embedding it, since an
+// embedded OrderedMap's JSON encoding would replace that of the whole component.
+//
+// The zero value is an empty map ready to use. Like a Go map, an OrderedMap copied out of a component shares its
+// entries with the original until either of them is changed with Set or Delete.
+type OrderedMap[K cmp.Ordered, V any] struct {
+	keys   []K
+	values []V
+}
+
+// orderedMapEntry is the JSON encoding of an entry of an OrderedMap.
+type orderedMapEntry[K cmp.Ordered, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// Len returns the number of entries in the map.
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.keys)
+}
+
+// Get returns the value of the given key, and whether the key is in the map.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if i, ok := slices.BinarySearch(m.keys, key); ok {
+		return m.values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has reports whether the given key is in the map.
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := slices.BinarySearch(m.keys, key)
+	return ok
+}
+
+// Set sets the value of the given key, adding the key to the map if needed.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	i, ok := slices.BinarySearch(m.keys, key)
+	if ok {
+		m.values = slices.Clone(m.values)
+		m.values[i] = value
+		return
+	}
+	m.keys = slices.Insert(slices.Clip(m.keys), i, key)
+	m.values = slices.Insert(slices.Clip(m.values), i, value)
+}
+
+// Delete removes the given key from the map, and reports whether it was in the map.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	i, ok := slices.BinarySearch(m.keys, key)
+	if !ok {
+		return false
+	}
+	m.keys = slices.Delete(slices.Clone(m.keys), i, i+1)
+	m.values = slices.Delete(slices.Clone(m.values), i, i+1)
+	return true
+}
+
+// Keys returns the keys of the map in ascending order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	return slices.Clone(m.keys)
+}
+
+// Each calls the callback with each entry of the map in ascending order of the keys. Returning false from the callback
+// stops the iteration early. The map must not be changed by the callback.
+func (m *OrderedMap[K, V]) Each(callback func(key K, value V) bool) {
+	for i, key := range m.keys {
+		if !callback(key, m.values[i]) {
+			return
+		}
+	}
+}
+
+func (m OrderedMap[K, V]) MarshalJSON() ([]byte, error) {
+	entries := make([]orderedMapEntry[K, V], 0, len(m.keys))
+	for i, key := range m.keys {
+		entries = append(entries, orderedMapEntry[K, V]{Key: key, Value: m.values[i]})
+	}
+	return json.Marshal(entries)
+}
+
+func (m *OrderedMap[K, V]) UnmarshalJSON(bz []byte) error {
+	var entries []orderedMapEntry[K, V]
+	if err := json.Unmarshal(bz, &entries); err != nil {
+		return eris.Wrap(err, "failed to decode ordered map")
+	}
+	// Entries are encoded in key order, but sort them anyway in case the JSON was written by hand. The last of several
+	// entries with the same key wins, like when the entries are set one after the other.
+	slices.SortStableFunc(entries, func(a, b orderedMapEntry[K, V]) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	*m = OrderedMap[K, V]{keys: make([]K, 0, len(entries)), values: make([]V, 0, len(entries))}
+	for _, entry := range entries {
+		if n := len(m.keys); n > 0 && cmp.Compare(m.keys[n-1], entry.Key) == 0 {
+			m.values[n-1] = entry.Value
+			continue
+		}
+		m.keys = append(m.keys, entry.Key)
+		m.values = append(m.values, entry.Value)
+	}
+	return nil
+}
+
+// JSONSchema describes the JSON encoding of the map in the schemas of components that hold one.
+func (OrderedMap[K, V]) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "array",
+		Items: &jsonschema.Schema{
+			Type:     "object",
+			Required: []string{"key", "value"},
+		},
+	}
+}
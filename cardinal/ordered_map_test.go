@@ -0,0 +1,118 @@
+package cardinal_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type Inventory struct {
+	Items cardinal.OrderedMap[string, int]
+}
+
+func (Inventory) Name() string {
+	return "inventory"
+}
+
+func collectEntries(m *cardinal.OrderedMap[string, int]) ([]string, []int) {
+	var keys []string
+	var values []int
+	m.Each(func(key string, value int) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	return keys, values
+}
+
+func TestOrderedMapIteratesInKeyOrder(t *testing.T) {
+	items := map[string]int{"sword": 1, "arrow": 40, "potion": 3, "bow": 1, "shield": 1, "coin": 250}
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+
+	var wantJSON []byte
+	for seed := int64(0); seed < 20; seed++ {
+		// Insert the items in a different order every time.
+		rand.New(rand.NewSource(seed)).Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+		var m cardinal.OrderedMap[string, int]
+		for _, name := range names {
+			m.Set(name, items[name])
+		}
+
+		keys, values := collectEntries(&m)
+		assert.DeepEqual(t, keys, []string{"arrow", "bow", "coin", "potion", "shield", "sword"})
+		assert.DeepEqual(t, values, []int{40, 1, 250, 3, 1, 1})
+		bz, err := json.Marshal(m)
+		assert.NilError(t, err)
+		if wantJSON == nil {
+			wantJSON = bz
+		}
+		assert.Equal(t, string(bz), string(wantJSON))
+	}
+}
+
+func TestOrderedMapCopiesDoNotShareChanges(t *testing.T) {
+	var m cardinal.OrderedMap[string, int]
+	m.Set("b", 2)
+	m.Set("a", 1)
+	m.Set("c", 3)
+
+	cp := m
+	cp.Set("a", 10)
+	cp.Set("d", 4)
+	assert.Check(t, cp.Delete("b"))
+	assert.Check(t, !cp.Delete("b"))
+
+	keys, values := collectEntries(&m)
+	assert.DeepEqual(t, keys, []string{"a", "b", "c"})
+	assert.DeepEqual(t, values, []int{1, 2, 3})
+	keys, values = collectEntries(&cp)
+	assert.DeepEqual(t, keys, []string{"a", "c", "d"})
+	assert.DeepEqual(t, values, []int{10, 3, 4})
+
+	value, ok := cp.Get("c")
+	assert.Check(t, ok)
+	assert.Equal(t, value, 3)
+	_, ok = cp.Get("b")
+	assert.Check(t, !ok)
+	assert.Equal(t, cp.Len(), 3)
+}
+
+func TestOrderedMapRoundTripsThroughSnapshots(t *testing.T) {
+	newFixture := func() *cardinal.TestFixture {
+		tf := cardinal.NewTestFixture(t, nil)
+		assert.NilError(t, cardinal.RegisterComponent[Inventory](tf.World))
+		tf.StartWorld()
+		return tf
+	}
+
+	src := newFixture()
+	inv := Inventory{}
+	inv.Items.Set("potion", 3)
+	inv.Items.Set("arrow", 40)
+	inv.Items.Set("sword", 1)
+	id, err := cardinal.Create(cardinal.NewWorldContext(src.World), inv)
+	assert.NilError(t, err)
+	src.DoTick()
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+
+	dst := newFixture()
+	assert.NilError(t, dst.World.Restore(data))
+	dst.DoTick()
+
+	got, err := cardinal.GetComponent[Inventory](cardinal.NewReadOnlyWorldContext(dst.World), id)
+	assert.NilError(t, err)
+	keys, values := collectEntries(&got.Items)
+	assert.DeepEqual(t, keys, []string{"arrow", "potion", "sword"})
+	assert.DeepEqual(t, values, []int{40, 3, 1})
+
+	again, err := dst.World.Snapshot()
+	assert.NilError(t, err)
+	assert.Equal(t, string(again), string(data))
+}
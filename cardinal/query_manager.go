@@ -58,6 +58,8 @@ func (m *queryManager) HandleQuery(group string, name string, bz []byte) ([]byte
 	if err != nil {
 		return nil, eris.Wrapf(err, "unable to find query %s/%s", group, name)
 	}
+	m.world.stateLock.RLock()
+	defer m.world.stateLock.RUnlock()
 	return q.handleQueryJSON(NewReadOnlyWorldContext(m.world), bz)
 }
 
@@ -66,6 +68,8 @@ func (m *queryManager) HandleQueryEVM(group string, name string, abiRequest []by
 	if err != nil {
 		return nil, eris.Wrapf(err, "unable to find EVM-compatible query %s/%s", group, name)
 	}
+	m.world.stateLock.RLock()
+	defer m.world.stateLock.RUnlock()
 	return q.handleQueryEVM(NewReadOnlyWorldContext(m.world), abiRequest)
 }
 
@@ -3,6 +3,8 @@
 package receipt
 
 import (
+	"cmp"
+	"slices"
 	"sync/atomic"
 
 	"github.com/rotisserie/eris"
@@ -14,6 +16,8 @@ import (
 var (
 	ErrTickHasNotBeenProcessed = eris.New("tick is still in progress")
 	ErrOldTickHasBeenDiscarded = eris.New("the requested tick has been discarded due to age")
+	ErrTickRangeTruncated      = eris.New("only part of the requested tick range is available")
+	ErrInvalidTickRange        = eris.New("the start of the tick range must not be after its end")
 )
 
 // History keeps track of transaction "receipts" (the result of a transaction and any associated errors) for some number
@@ -23,13 +27,22 @@ type History struct {
 	ticksToStore uint64
 	// Receipts for a given tick are assigned to an index into this history slice which acts as a ring buffer.
 	history []map[types.TxHash]Receipt
+	// onEvict, if set, is called with the receipts of a tick right before they are discarded from the ring buffer.
+	onEvict func([]Receipt)
 }
 
-// Receipt contains a transaction hash, an arbitrary result, and a list of errors.
+// Receipt contains a transaction hash, an arbitrary result, and a list of errors. Internal is set for messages that
+// were generated by the world itself rather than submitted from outside of it. Duplicate is set for transactions that
+// were dropped without being processed because the same transaction was processed in a recent tick. Deferred is set
+// for transactions that were not processed because the tick ran out of time, and are pending until a later tick, which
+// has the receipt of their processing.
 type Receipt struct {
-	TxHash types.TxHash
-	Result any
-	Errs   []error
+	TxHash    types.TxHash
+	Result    any
+	Errs      []error
+	Internal  bool
+	Duplicate bool
+	Deferred  bool
 }
 
 func (r Receipt) MarshalJSON() ([]byte, error) {
@@ -39,16 +52,38 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	}
 
 	return codec.Encode(struct {
-		TxHash types.TxHash `json:"txHash"`
-		Result any          `json:"result"`
-		Errs   []string     `json:"errors"`
+		TxHash      types.TxHash `json:"txHash"`
+		Result      any          `json:"result"`
+		Errs        []string     `json:"errors"`
+		StackTraces []string     `json:"stackTraces,omitempty"`
+		Internal    bool         `json:"internal,omitempty"`
+		Duplicate   bool         `json:"duplicate,omitempty"`
+		Deferred    bool         `json:"deferred,omitempty"`
 	}{
-		TxHash: r.TxHash,
-		Result: r.Result,
-		Errs:   errStrings,
+		TxHash:      r.TxHash,
+		Result:      r.Result,
+		Errs:        errStrings,
+		StackTraces: ErrorStackTraces(r.Errs),
+		Internal:    r.Internal,
+		Duplicate:   r.Duplicate,
+		Deferred:    r.Deferred,
 	})
 }
 
+// ErrorStackTraces returns the given errors formatted with their stack traces, for clients that need to debug why a
+// transaction failed. Errors that were not created or wrapped with eris have no stack trace and are only formatted
+// with their message.
+func ErrorStackTraces(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	traces := make([]string, 0, len(errs))
+	for _, err := range errs {
+		traces = append(traces, eris.ToString(err, true))
+	}
+	return traces
+}
+
 // NewHistory creates an object that can track transaction receipts over a number of ticks.
 func NewHistory(currentTick uint64, ticksToStore int) *History {
 	// Add an extra tick for the "current" tick.
@@ -75,9 +110,26 @@ func (h *History) Size() uint64 {
 func (h *History) NextTick() {
 	newCurr := h.currTick.Add(1)
 	mod := newCurr % h.ticksToStore
+	if h.onEvict != nil && len(h.history[mod]) > 0 {
+		evicted := make([]Receipt, 0, len(h.history[mod]))
+		for _, rec := range h.history[mod] {
+			evicted = append(evicted, rec)
+		}
+		// Map iteration order is random, so sort the receipts to hand them to the handler in a stable order.
+		slices.SortFunc(evicted, func(a, b Receipt) int {
+			return cmp.Compare(a.TxHash, b.TxHash)
+		})
+		h.onEvict(evicted)
+	}
 	h.history[mod] = map[types.TxHash]Receipt{}
 }
 
+// SetEvictionHandler sets a function that is called with the receipts of a tick, sorted by transaction hash, when they
+// age out of the history. A nil handler stops handing evicted receipts to the previous one.
+func (h *History) SetEvictionHandler(onEvict func([]Receipt)) {
+	h.onEvict = onEvict
+}
+
 func (h *History) SetTick(tick uint64) {
 	h.currTick.Store(tick)
 }
@@ -102,6 +154,36 @@ func (h *History) SetResult(hash types.TxHash, result any) {
 	h.history[tick][hash] = rec
 }
 
+// MarkInternal marks the receipt of the given transaction hash in the current tick as internal, i.e. the transaction
+// was generated by the world itself. This creates the receipt if it doesn't exist yet.
+func (h *History) MarkInternal(hash types.TxHash) {
+	tick := int(h.currTick.Load() % h.ticksToStore)
+	rec := h.history[tick][hash]
+	rec.TxHash = hash
+	rec.Internal = true
+	h.history[tick][hash] = rec
+}
+
+// MarkDuplicate marks the receipt of the given transaction hash in the current tick as a duplicate, i.e. the
+// transaction was dropped because it had already been processed. This creates the receipt if it doesn't exist yet.
+func (h *History) MarkDuplicate(hash types.TxHash) {
+	tick := int(h.currTick.Load() % h.ticksToStore)
+	rec := h.history[tick][hash]
+	rec.TxHash = hash
+	rec.Duplicate = true
+	h.history[tick][hash] = rec
+}
+
+// MarkDeferred marks the receipt of the given transaction hash in the current tick as deferred, i.e. the transaction
+// is pending until a later tick. This creates the receipt if it doesn't exist yet.
+func (h *History) MarkDeferred(hash types.TxHash) {
+	tick := int(h.currTick.Load() % h.ticksToStore)
+	rec := h.history[tick][hash]
+	rec.TxHash = hash
+	rec.Deferred = true
+	h.history[tick][hash] = rec
+}
+
 // GetReceipt gets the receipt (the transaction result and the list of errors) for the given transaction hash in the
 // current tick. To get receipts from previous ticks use GetReceiptsForTick.
 func (h *History) GetReceipt(hash types.TxHash) (Receipt, bool) {
@@ -110,6 +192,28 @@ func (h *History) GetReceipt(hash types.TxHash) (Receipt, bool) {
 	return rec, ok
 }
 
+// GetReceiptsForCurrentTick gets all receipts for the current tick so far.
+func (h *History) GetReceiptsForCurrentTick() []Receipt {
+	tick := h.currTick.Load() % h.ticksToStore
+	recs := make([]Receipt, 0, len(h.history[tick]))
+	for _, rec := range h.history[tick] {
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+// FindReceipt looks for the receipt of the given transaction hash in the current tick and in all the ticks that are
+// still stored, starting with the most recent one.
+func (h *History) FindReceipt(hash types.TxHash) (Receipt, bool) {
+	currTick := h.currTick.Load()
+	for i := uint64(0); i < h.ticksToStore && i <= currTick; i++ {
+		if rec, ok := h.history[(currTick-i)%h.ticksToStore][hash]; ok {
+			return rec, true
+		}
+	}
+	return Receipt{}, false
+}
+
 // GetReceiptsForTick gets all receipts for the given tick. If the tick is still active, or if the tick is too
 // far in the past, an error is returned.
 func (h *History) GetReceiptsForTick(tick uint64) ([]Receipt, error) {
@@ -130,3 +234,36 @@ func (h *History) GetReceiptsForTick(tick uint64) ([]Receipt, error) {
 
 	return recs, nil
 }
+
+// GetReceiptsForTickRange gets all receipts for the ticks in [start, end], ordered by tick. If none of the ticks in the
+// range are available, the same errors as GetReceiptsForTick are returned. If the range only partially overlaps the
+// ticks that are still stored and have been processed, the receipts of the overlapping ticks are returned along with an
+// error that wraps ErrTickRangeTruncated.
+func (h *History) GetReceiptsForTickRange(start, end uint64) ([]Receipt, error) {
+	if start > end {
+		return nil, eris.Wrapf(ErrInvalidTickRange, "start %d, end %d", start, end)
+	}
+	currTick := h.currTick.Load()
+	if currTick <= start {
+		return nil, ErrTickHasNotBeenProcessed
+	}
+	oldestTick := uint64(0)
+	if currTick >= h.ticksToStore {
+		oldestTick = currTick - h.ticksToStore + 1
+	}
+	if end < oldestTick {
+		return nil, ErrOldTickHasBeenDiscarded
+	}
+
+	first, last := max(start, oldestTick), min(end, currTick-1)
+	recs := make([]Receipt, 0)
+	for tick := first; tick <= last; tick++ {
+		for _, rec := range h.history[tick%h.ticksToStore] {
+			recs = append(recs, rec)
+		}
+	}
+	if first != start || last != end {
+		return recs, eris.Wrapf(ErrTickRangeTruncated, "returned receipts for ticks %d to %d", first, last)
+	}
+	return recs, nil
+}
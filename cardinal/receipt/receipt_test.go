@@ -148,6 +148,67 @@ func TestOldTicksAreDiscarded(t *testing.T) {
 	assert.ErrorIs(t, ErrOldTickHasBeenDiscarded, eris.Cause(err))
 }
 
+func TestReceiptsForTickRange(t *testing.T) {
+	startTick := uint64(10)
+	historyLength := 3
+	rh := NewHistory(startTick, historyLength)
+	hashes := map[uint64]types.TxHash{}
+	// Store one receipt in each of ticks 10 through 15.
+	for tick := startTick; tick < startTick+6; tick++ {
+		hashes[tick] = txHash(t)
+		rh.SetResult(hashes[tick], tick)
+		rh.NextTick()
+	}
+	// The current tick is 16, so ticks 13 through 15 are retained.
+
+	recs, err := rh.GetReceiptsForTickRange(13, 15)
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(recs))
+	for i, rec := range recs {
+		assert.Equal(t, hashes[uint64(13+i)], rec.TxHash)
+	}
+
+	// The range partially overlaps the retained window on both ends.
+	recs, err = rh.GetReceiptsForTickRange(11, 20)
+	assert.ErrorIs(t, ErrTickRangeTruncated, eris.Cause(err))
+	assert.Equal(t, 3, len(recs))
+	assert.Equal(t, hashes[13], recs[0].TxHash)
+	assert.Equal(t, hashes[15], recs[2].TxHash)
+
+	_, err = rh.GetReceiptsForTickRange(10, 12)
+	assert.ErrorIs(t, ErrOldTickHasBeenDiscarded, eris.Cause(err))
+
+	_, err = rh.GetReceiptsForTickRange(16, 20)
+	assert.ErrorIs(t, ErrTickHasNotBeenProcessed, eris.Cause(err))
+
+	_, err = rh.GetReceiptsForTickRange(15, 14)
+	assert.ErrorIs(t, ErrInvalidTickRange, eris.Cause(err))
+}
+
+func TestFindReceiptSearchesAllStoredTicks(t *testing.T) {
+	rh := NewHistory(0, 2)
+	oldHash, recentHash, currentHash := txHash(t), txHash(t), txHash(t)
+	rh.SetResult(oldHash, 1)
+	rh.NextTick()
+	rh.SetResult(recentHash, 2)
+	rh.NextTick()
+	rh.SetResult(currentHash, 3)
+
+	for hash, want := range map[types.TxHash]int{oldHash: 1, recentHash: 2, currentHash: 3} {
+		rec, ok := rh.FindReceipt(hash)
+		assert.Check(t, ok)
+		assert.Equal(t, rec.TxHash, hash)
+		assert.Equal(t, rec.Result, want)
+	}
+
+	// The oldest tick is discarded once it falls out of the history.
+	rh.NextTick()
+	_, ok := rh.FindReceipt(oldHash)
+	assert.Check(t, !ok)
+	_, ok = rh.FindReceipt(txHash(t))
+	assert.Check(t, !ok)
+}
+
 func TestReceipt_ReceiptErrorsArePresentInJSON(t *testing.T) {
 	var (
 		receiptHash   = "some_tx_hash"
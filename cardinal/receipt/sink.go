@@ -0,0 +1,56 @@
+package receipt
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sinkBufferSize is the number of evicted batches of receipts that can be waiting to be saved before evicting more
+// receipts blocks.
+const sinkBufferSize = 16
+
+// Sink persists receipts that are about to be evicted from the in-memory History.
+type Sink interface {
+	Save(ctx context.Context, receipts []Receipt) error
+}
+
+// Flusher delivers batches of receipts to a Sink from a background goroutine so that saving receipts does not block
+// the caller. If the Sink falls behind and the buffer of pending batches is full, Enqueue blocks until there is room.
+// Each batch is saved at most once; a batch that fails to save is logged and dropped.
+type Flusher struct {
+	sink    Sink
+	batches chan []Receipt
+	done    chan struct{}
+}
+
+// NewFlusher creates a Flusher for the given Sink and starts delivering batches to it.
+func NewFlusher(sink Sink) *Flusher {
+	f := &Flusher{
+		sink:    sink,
+		batches: make(chan []Receipt, sinkBufferSize),
+		done:    make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Enqueue queues the given receipts to be saved to the Sink.
+func (f *Flusher) Enqueue(receipts []Receipt) {
+	f.batches <- receipts
+}
+
+// Close stops accepting batches and waits for all queued batches to be saved. Enqueue must not be called after Close.
+func (f *Flusher) Close() {
+	close(f.batches)
+	<-f.done
+}
+
+func (f *Flusher) run() {
+	defer close(f.done)
+	for batch := range f.batches {
+		if err := f.sink.Save(context.Background(), batch); err != nil {
+			log.Error().Err(err).Int("receipts", len(batch)).Msg("failed to save evicted receipts to sink")
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package receipt
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type fakeSink struct {
+	mu    sync.Mutex
+	saved map[types.TxHash]int
+}
+
+func (f *fakeSink) Save(_ context.Context, receipts []Receipt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, rec := range receipts {
+		f.saved[rec.TxHash]++
+	}
+	return nil
+}
+
+func TestEvictedReceiptsAreSavedToSinkExactlyOnce(t *testing.T) {
+	historyLength := 2
+	rh := NewHistory(0, historyLength)
+	sink := &fakeSink{saved: map[types.TxHash]int{}}
+	flusher := NewFlusher(sink)
+	rh.SetEvictionHandler(flusher.Enqueue)
+
+	numOfTicks := 20
+	hashesByTick := make([][]types.TxHash, numOfTicks)
+	for tick := 0; tick < numOfTicks; tick++ {
+		for i := 0; i < 2; i++ {
+			hash := txHash(t)
+			rh.SetResult(hash, i)
+			hashesByTick[tick] = append(hashesByTick[tick], hash)
+		}
+		rh.NextTick()
+	}
+	flusher.Close()
+
+	// The last historyLength ticks are still in the history, so only the receipts before them have been evicted.
+	evictedTicks := numOfTicks - historyLength
+	assert.Equal(t, 2*evictedTicks, len(sink.saved))
+	for tick, hashes := range hashesByTick {
+		for _, hash := range hashes {
+			if tick < evictedTicks {
+				assert.Equal(t, 1, sink.saved[hash], "receipt from tick %d was not saved exactly once", tick)
+			} else {
+				assert.Equal(t, 0, sink.saved[hash], "receipt from tick %d should still be in the history", tick)
+			}
+		}
+	}
+}
+
+func TestEvictedReceiptsAreSortedByHash(t *testing.T) {
+	rh := NewHistory(0, 1)
+	var evicted [][]Receipt
+	rh.SetEvictionHandler(func(receipts []Receipt) {
+		evicted = append(evicted, receipts)
+	})
+	for i := 0; i < 10; i++ {
+		rh.SetResult(txHash(t), i)
+	}
+	rh.NextTick()
+	rh.NextTick()
+	assert.Equal(t, len(evicted), 1)
+	assert.Equal(t, len(evicted[0]), 10)
+	assert.Check(t, slices.IsSortedFunc(evicted[0], func(a, b Receipt) int {
+		return strings.Compare(string(a.TxHash), string(b.TxHash))
+	}))
+
+	// Once the handler is removed, evicting receipts does not call it anymore.
+	rh.SetEvictionHandler(nil)
+	rh.SetResult(txHash(t), 0)
+	rh.NextTick()
+	rh.NextTick()
+	assert.Equal(t, len(evicted), 1)
+}
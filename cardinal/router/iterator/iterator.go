@@ -4,9 +4,14 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/rotisserie/eris"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"pkg.world.dev/world-engine/cardinal/types"
@@ -23,12 +28,75 @@ type Iterator interface {
 	// onchain. If only a single number is supplied, `Each` assumes this to be the tick from which to start the queries.
 	// If both are supplied, `Each` will call `fn` for ticks ranges[0] and ranges[1] (inclusive).
 	Each(fn func(batch []*TxBatch, tick, timestamp uint64) error, ranges ...uint64) error
+
+	// EachWithProgress is like Each, but also calls progressFn after each page of transactions queried from the base
+	// shard, with the last successfully processed tick and whether more pages will be queried.
+	EachWithProgress(
+		fn func(batch []*TxBatch, tick, timestamp uint64) error,
+		progressFn func(currentTick uint64, hasMore bool),
+		ranges ...uint64,
+	) error
+
+	// EachCtx is like Each, but stops and returns ctx.Err() once ctx is cancelled. The context is also passed to the
+	// queries made to the base shard.
+	EachCtx(ctx context.Context, fn func(batch []*TxBatch, tick, timestamp uint64) error, ranges ...uint64) error
+
+	// Validate decodes the transactions in the given ranges like Each does, without processing them, and reports how
+	// many of them could not be decoded.
+	Validate(ranges ...uint64) (ValidationReport, error)
+
+	// Cursor returns the last tick for which `fn` passed to Each returned nil. It can be persisted and later given to
+	// NewFromCursor to resume iterating after that tick. Before any tick has been processed it returns the cursor the
+	// iterator was created with; ok is false if there is none, so that tick 0 can be told apart from no tick at all.
+	Cursor() (tick uint64, ok bool)
 }
 
 type iterator struct {
 	getMsgByID func(id types.MessageID) (types.Message, bool)
 	namespace  string
 	querier    shard.TransactionHandlerClient
+	// cursor is the last tick that was successfully processed. hasCursor is false until a tick has been processed or
+	// the iterator has been created from a cursor, so that tick 0 is not skipped on a fresh start.
+	cursor    *atomic.Uint64
+	hasCursor *atomic.Bool
+	// maxAttempts and baseDelay control how queries that fail with a transient error are retried. See WithRetry.
+	maxAttempts int
+	baseDelay   time.Duration
+	// personaFilter, if not nil, is the set of persona tags whose transactions are delivered. See WithPersonaFilter.
+	personaFilter map[string]struct{}
+	// batchWindow is the number of ticks requested from the base shard per query. See WithBatchWindow.
+	batchWindow uint32
+}
+
+// ErrUnknownMessageID is returned when a transaction queried from the base shard references a message ID that is not
+// registered in Cardinal.
+type ErrUnknownMessageID struct {
+	ID types.MessageID
+}
+
+func (e ErrUnknownMessageID) Error() string {
+	return fmt.Sprintf("queried message with ID %d, but it does not exist in Cardinal", e.ID)
+}
+
+// ValidationReport summarizes the transactions examined by Validate.
+type ValidationReport struct {
+	// Ticks is the number of ticks that were examined.
+	Ticks int
+	// Transactions is the number of transactions that were examined, including the ones that failed to decode.
+	Transactions int
+	// UnknownMessageIDs is the number of transactions whose message ID is not registered in Cardinal.
+	UnknownMessageIDs int
+	// DecodeFailures is the number of transactions that could not be unmarshalled or decoded into their message.
+	DecodeFailures int
+	// Failures has one entry for each transaction counted in UnknownMessageIDs or DecodeFailures.
+	Failures []ValidationFailure
+}
+
+// ValidationFailure describes a transaction that failed to decode during Validate.
+type ValidationFailure struct {
+	Tick  uint64
+	MsgID types.MessageID
+	Err   error
 }
 
 type TxBatch struct {
@@ -41,24 +109,129 @@ func New(
 	getMessageByID func(id types.MessageID) (types.Message, bool),
 	namespace string,
 	querier shard.TransactionHandlerClient,
+	opts ...Option,
 ) Iterator {
-	return &iterator{
-		getMsgByID: getMessageByID,
-		namespace:  namespace,
-		querier:    querier,
+	return newIterator(getMessageByID, namespace, querier, opts...)
+}
+
+func newIterator(
+	getMessageByID func(id types.MessageID) (types.Message, bool),
+	namespace string,
+	querier shard.TransactionHandlerClient,
+	opts ...Option,
+) *iterator {
+	it := &iterator{
+		getMsgByID:    getMessageByID,
+		namespace:     namespace,
+		querier:       querier,
+		cursor:        &atomic.Uint64{},
+		hasCursor:     &atomic.Bool{},
+		maxAttempts:   1,
+		baseDelay:     0,
+		personaFilter: nil,
+		batchWindow:   1,
+	}
+	for _, opt := range opts {
+		opt(it)
 	}
+	return it
+}
+
+// NewFromCursor creates an Iterator that resumes after the given cursor, which is a value previously returned by
+// Cursor. When Each is called without ranges, iteration starts at the tick after the cursor.
+func NewFromCursor(
+	getMessageByID func(id types.MessageID) (types.Message, bool),
+	namespace string,
+	querier shard.TransactionHandlerClient,
+	cursor uint64,
+	opts ...Option,
+) Iterator {
+	it := newIterator(getMessageByID, namespace, querier, opts...)
+	it.cursor.Store(cursor)
+	it.hasCursor.Store(true)
+	return it
+}
+
+func (t *iterator) Cursor() (uint64, bool) {
+	return t.cursor.Load(), t.hasCursor.Load()
 }
 
 // Each iterates over txs from the base shard layer. For each batch of transactions found in
-// each tick, it will apply the callback function to that batch and it's respective tick and timestamp.
-//
-//nolint:gocognit // maybe revisit.. idk.
+// each tick, it will apply the callback function to that batch and it's respective tick and timestamp. The cursor is
+// only advanced once the callback returns nil for a tick. If no ranges are given and the iterator has a cursor, the
+// iteration resumes from the tick after the cursor.
 func (t *iterator) Each(
 	fn func(batch []*TxBatch, tick, timestamp uint64) error,
 	ranges ...uint64,
+) error {
+	return t.each(context.Background(), t.epochHandler(fn), nil, ranges...)
+}
+
+// EachCtx behaves like Each, but checks ctx before every query to the base shard and returns ctx.Err() if it has been
+// cancelled. Ticks that were already passed to fn remain processed, so the cursor can be used to resume later.
+func (t *iterator) EachCtx(
+	ctx context.Context,
+	fn func(batch []*TxBatch, tick, timestamp uint64) error,
+	ranges ...uint64,
+) error {
+	return t.each(ctx, t.epochHandler(fn), nil, ranges...)
+}
+
+// EachWithProgress behaves like Each, but additionally calls progressFn (if not nil) after each page of transactions
+// queried from the base shard has been processed. currentTick is the last successfully processed tick, and hasMore
+// reports whether more pages will be queried.
+func (t *iterator) EachWithProgress(
+	fn func(batch []*TxBatch, tick, timestamp uint64) error,
+	progressFn func(currentTick uint64, hasMore bool),
+	ranges ...uint64,
+) error {
+	return t.each(context.Background(), t.epochHandler(fn), progressFn, ranges...)
+}
+
+// Validate queries the transactions in the given ranges (see Each) and decodes them the same way as Each, without
+// passing them to a callback or advancing the cursor. Transactions that fail to decode are tallied in the returned
+// report instead of stopping the validation. An error is only returned if the transactions can't be queried.
+func (t *iterator) Validate(ranges ...uint64) (ValidationReport, error) {
+	report := ValidationReport{}
+	err := t.each(context.Background(), func(epoch *shard.Epoch) error {
+		report.Ticks++
+		for _, tx := range epoch.GetTxs() {
+			batch, err := t.decodeTx(tx)
+			if batch == nil && err == nil {
+				continue
+			}
+			report.Transactions++
+			if err == nil {
+				continue
+			}
+			var unknownErr ErrUnknownMessageID
+			if errors.As(err, &unknownErr) {
+				report.UnknownMessageIDs++
+			} else {
+				report.DecodeFailures++
+			}
+			report.Failures = append(report.Failures, ValidationFailure{
+				Tick:  epoch.GetEpoch(),
+				MsgID: types.MessageID(tx.GetTxId()),
+				Err:   err,
+			})
+		}
+		return nil
+	}, nil, ranges...)
+	return report, err
+}
+
+func (t *iterator) each(
+	ctx context.Context,
+	handleEpoch func(epoch *shard.Epoch) error,
+	progressFn func(currentTick uint64, hasMore bool),
+	ranges ...uint64,
 ) error {
 	var nextKey []byte
 	stopTick := uint64(0)
+	if len(ranges) == 0 && t.hasCursor.Load() {
+		ranges = []uint64{t.cursor.Load() + 1}
+	}
 	if len(ranges) > 0 {
 		if ranges[0] > uint64(0) {
 			nextKey = makePageKey(ranges[0])
@@ -70,57 +243,122 @@ func (t *iterator) Each(
 			}
 		}
 	}
-OuterLoop:
 	for {
-		res, err := t.querier.QueryTransactions(context.Background(), &shard.QueryTransactionsRequest{
-			Namespace: t.namespace,
-			Page: &shard.PageRequest{
-				Key:   nextKey,
-				Limit: 1,
-			},
-		})
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		res, err := t.queryTransactions(ctx, nextKey)
 		if err != nil {
-			return eris.Wrap(err, "failed to query transactions from base shard")
+			return err
 		}
+		reachedStop := false
 		for _, epoch := range res.GetEpochs() {
 			if stopTick != 0 && epoch.GetEpoch() > stopTick {
-				return nil
+				reachedStop = true
+				break
 			}
-			tickNumber := epoch.GetEpoch()
-			timestamp := epoch.GetUnixTimestamp()
-			batches := make([]*TxBatch, 0, len(epoch.GetTxs()))
-			for _, tx := range epoch.GetTxs() {
-				msgType, exists := t.getMsgByID(types.MessageID(tx.GetTxId()))
-				if !exists {
-					return eris.Errorf(
-						"queried message with ID %d, but it does not exist in Cardinal", tx.GetTxId(),
-					)
-				}
-				protoTx := new(shard.Transaction)
-				err := proto.Unmarshal(tx.GetGameShardTransaction(), protoTx)
-				if err != nil {
-					return eris.Wrap(err, "failed to unmarshal transaction data")
-				}
-				msgValue, err := msgType.Decode(protoTx.GetBody())
-				if err != nil {
-					return err
-				}
-				batches = append(batches, &TxBatch{
-					Tx:       protoTxToSignTx(protoTx),
-					MsgID:    msgType.ID(),
-					MsgValue: msgValue,
-				})
-			}
-			if err := fn(batches, tickNumber, timestamp); err != nil {
+			if err := handleEpoch(epoch); err != nil {
 				return err
 			}
 		}
-		if res.GetPage().GetKey() == nil {
-			break OuterLoop
+		hasMore := !reachedStop && res.GetPage().GetKey() != nil
+		if progressFn != nil {
+			progressFn(t.cursor.Load(), hasMore)
+		}
+		if !hasMore {
+			return nil
 		}
 		nextKey = res.GetPage().GetKey()
 	}
-	return nil
+}
+
+// queryTransactions queries the page of transactions starting at the given key, retrying transient failures as
+// configured by WithRetry.
+func (t *iterator) queryTransactions(ctx context.Context, key []byte) (*shard.QueryTransactionsResponse, error) {
+	delay := t.baseDelay
+	for attempt := 1; ; attempt++ {
+		res, err := t.querier.QueryTransactions(ctx, &shard.QueryTransactionsRequest{
+			Namespace: t.namespace,
+			Page: &shard.PageRequest{
+				Key:   key,
+				Limit: t.batchWindow,
+			},
+		})
+		if err == nil {
+			return res, nil
+		}
+		if attempt >= t.maxAttempts || !isTransientError(err) {
+			return nil, eris.Wrapf(err, "failed to query transactions from base shard after %d attempt(s)", attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isTransientError reports whether err is a gRPC transport error that may succeed if the request is retried.
+func isTransientError(err error) bool {
+	switch status.Code(err) { //nolint:exhaustive // all other codes are not transient
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// epochHandler returns a function that decodes the transactions of a single tick and passes them to fn. The cursor is
+// advanced to the tick only if fn succeeds.
+func (t *iterator) epochHandler(fn func(batch []*TxBatch, tick, timestamp uint64) error) func(*shard.Epoch) error {
+	return func(epoch *shard.Epoch) error {
+		tickNumber := epoch.GetEpoch()
+		batches := make([]*TxBatch, 0, len(epoch.GetTxs()))
+		for _, tx := range epoch.GetTxs() {
+			batch, err := t.decodeTx(tx)
+			if err != nil {
+				return err
+			}
+			if batch != nil {
+				batches = append(batches, batch)
+			}
+		}
+		if err := fn(batches, tickNumber, epoch.GetUnixTimestamp()); err != nil {
+			return err
+		}
+		t.cursor.Store(tickNumber)
+		t.hasCursor.Store(true)
+		return nil
+	}
+}
+
+// decodeTx decodes a transaction queried from the base shard. A nil batch and error are returned if the transaction
+// is skipped by the persona filter.
+func (t *iterator) decodeTx(tx *shard.TxData) (*TxBatch, error) {
+	protoTx := new(shard.Transaction)
+	err := proto.Unmarshal(tx.GetGameShardTransaction(), protoTx)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to unmarshal transaction data")
+	}
+	if t.personaFilter != nil {
+		if _, ok := t.personaFilter[protoTx.GetPersonaTag()]; !ok {
+			return nil, nil //nolint:nilnil // skipped transactions have no batch
+		}
+	}
+	msgType, exists := t.getMsgByID(types.MessageID(tx.GetTxId()))
+	if !exists {
+		return nil, ErrUnknownMessageID{ID: types.MessageID(tx.GetTxId())}
+	}
+	msgValue, err := msgType.Decode(protoTx.GetBody())
+	if err != nil {
+		return nil, err
+	}
+	return &TxBatch{
+		Tx:       protoTxToSignTx(protoTx),
+		MsgID:    msgType.ID(),
+		MsgValue: msgValue,
+	}, nil
 }
 
 func protoTxToSignTx(t *shard.Transaction) *sign.Transaction {
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"pkg.world.dev/world-engine/assert"
@@ -24,10 +26,13 @@ type fooIn struct{ X int }
 type fooOut struct{}
 
 type mockQuerier struct {
-	i       int
-	retErr  error
-	ret     []*shard.QueryTransactionsResponse
-	request *shard.QueryTransactionsRequest
+	i      int
+	retErr error
+	// failures are returned, one per call, before any of the responses in ret.
+	failures []error
+	calls    int
+	ret      []*shard.QueryTransactionsResponse
+	request  *shard.QueryTransactionsRequest
 }
 
 func (m *mockQuerier) RegisterGameShard(
@@ -49,6 +54,12 @@ func (m *mockQuerier) QueryTransactions(
 	_ ...grpc.CallOption,
 ) (*shard.QueryTransactionsResponse, error) {
 	m.request = req
+	m.calls++
+	if len(m.failures) > 0 {
+		err := m.failures[0]
+		m.failures = m.failures[1:]
+		return nil, err
+	}
 	if m.retErr != nil {
 		return nil, m.retErr
 	}
@@ -84,6 +95,10 @@ func TestIteratorReturnsErrorWhenQueryNotFound(t *testing.T) {
 	)
 	err := it.Each(nil)
 	assert.ErrorContains(t, err, "queried message with ID 1, but it does not exist in Cardinal")
+
+	var unknownErr iterator.ErrUnknownMessageID
+	assert.Check(t, errors.As(err, &unknownErr))
+	assert.Equal(t, unknownErr.ID, types.MessageID(1))
 }
 
 func TestIteratorReturnsErrorIfQueryFails(t *testing.T) {
@@ -93,6 +108,59 @@ func TestIteratorReturnsErrorIfQueryFails(t *testing.T) {
 	assert.ErrorContains(t, err, "some error")
 }
 
+func TestIteratorRetriesTransientQueryErrors(t *testing.T) {
+	querier := &mockQuerier{
+		failures: []error{
+			status.Error(codes.Unavailable, "shard is restarting"),
+			status.Error(codes.DeadlineExceeded, "shard is slow"),
+		},
+		ret: []*shard.QueryTransactionsResponse{
+			{Epochs: []*shard.Epoch{{Epoch: 12}}, Page: &shard.PageResponse{}},
+		},
+	}
+	it := iterator.New(nil, "", querier, iterator.WithRetry(3, time.Millisecond))
+	var ticks []uint64
+	err := it.Each(func(_ []*iterator.TxBatch, tick, _ uint64) error {
+		ticks = append(ticks, tick)
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ticks, []uint64{12})
+	assert.Equal(t, querier.calls, 3)
+}
+
+func TestIteratorGivesUpAfterMaxAttempts(t *testing.T) {
+	querier := &mockQuerier{retErr: status.Error(codes.Unavailable, "shard is down")}
+	it := iterator.New(nil, "", querier, iterator.WithRetry(2, time.Millisecond))
+	err := it.Each(nil)
+	assert.ErrorContains(t, err, "shard is down")
+	assert.Equal(t, querier.calls, 2)
+}
+
+func TestIteratorDoesNotRetryLogicalErrors(t *testing.T) {
+	querier := &mockQuerier{retErr: status.Error(codes.InvalidArgument, "bad namespace")}
+	it := iterator.New(nil, "", querier, iterator.WithRetry(5, time.Millisecond))
+	err := it.Each(nil)
+	assert.ErrorContains(t, err, "bad namespace")
+	assert.Equal(t, querier.calls, 1)
+
+	// Messages that can't be decoded are not a query failure and are not retried either.
+	querier = &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{Epochs: []*shard.Epoch{{Epoch: 1, Txs: []*shard.TxData{{TxId: 1}}}}},
+		},
+	}
+	it = iterator.New(
+		func(types.MessageID) (types.Message, bool) { return nil, false },
+		"",
+		querier,
+		iterator.WithRetry(5, time.Millisecond),
+	)
+	err = it.Each(func(_ []*iterator.TxBatch, _, _ uint64) error { return nil })
+	assert.ErrorContains(t, err, "does not exist in Cardinal")
+	assert.Equal(t, querier.calls, 1)
+}
+
 func TestIteratorHappyPath(t *testing.T) {
 	err := fooMsg.SetID(10)
 	assert.NilError(t, err)
@@ -157,6 +225,116 @@ func TestIteratorHappyPath(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+func TestIteratorPersonaFilterSkipsOtherPersonas(t *testing.T) {
+	err := fooMsg.SetID(10)
+	assert.NilError(t, err)
+	txData := func(personaTag string, x int) *shard.TxData {
+		msgBytes, err := fooMsg.Encode(fooIn{x})
+		assert.NilError(t, err)
+		txBz, err := proto.Marshal(&shard.Transaction{PersonaTag: personaTag, Body: msgBytes})
+		assert.NilError(t, err)
+		return &shard.TxData{TxId: uint64(fooMsg.ID()), GameShardTransaction: txBz}
+	}
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{
+					{Epoch: 1, Txs: []*shard.TxData{txData("alice", 1), txData("bob", 2), txData("carol", 3)}},
+					{Epoch: 2, Txs: []*shard.TxData{txData("bob", 4), txData("carol", 5)}},
+				},
+				Page: &shard.PageResponse{},
+			},
+		},
+	}
+	decoded := 0
+	it := iterator.New(
+		func(id types.MessageID) (types.Message, bool) {
+			decoded++
+			return fooMsg, id == fooMsg.ID()
+		},
+		"",
+		querier,
+		iterator.WithPersonaFilter("alice", "carol"),
+	)
+	var got []fooIn
+	err = it.Each(func(batch []*iterator.TxBatch, _, _ uint64) error {
+		for _, tx := range batch {
+			assert.Check(t, tx.Tx.PersonaTag == "alice" || tx.Tx.PersonaTag == "carol")
+			got = append(got, tx.MsgValue.(fooIn))
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, []fooIn{{1}, {3}, {5}})
+	assert.Equal(t, decoded, 3)
+}
+
+func TestEachCtxStopsWhenContextIsCancelled(t *testing.T) {
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{Epochs: []*shard.Epoch{{Epoch: 12}}, Page: &shard.PageResponse{Key: makePageKey(13)}},
+			{Epochs: []*shard.Epoch{{Epoch: 13}}, Page: &shard.PageResponse{}},
+		},
+	}
+	it := iterator.New(nil, "", querier)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ticks []uint64
+	err := it.EachCtx(ctx, func(_ []*iterator.TxBatch, tick, _ uint64) error {
+		ticks = append(ticks, tick)
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.DeepEqual(t, ticks, []uint64{12})
+	assert.Equal(t, querier.calls, 1)
+	assertCursor(t, it, 12)
+}
+
+func TestValidateTalliesUndecodableTransactions(t *testing.T) {
+	err := fooMsg.SetID(10)
+	assert.NilError(t, err)
+	goodBody, err := fooMsg.Encode(fooIn{1})
+	assert.NilError(t, err)
+	goodTx, err := proto.Marshal(&shard.Transaction{PersonaTag: "ty", Body: goodBody})
+	assert.NilError(t, err)
+	badTx, err := proto.Marshal(&shard.Transaction{PersonaTag: "ty", Body: []byte(`{"X":"not a number"}`)})
+	assert.NilError(t, err)
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{
+					{Epoch: 1, Txs: []*shard.TxData{
+						{TxId: uint64(fooMsg.ID()), GameShardTransaction: goodTx},
+						{TxId: uint64(fooMsg.ID()), GameShardTransaction: badTx},
+					}},
+					{Epoch: 2, Txs: []*shard.TxData{{TxId: 99, GameShardTransaction: goodTx}}},
+				},
+				Page: &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.New(
+		func(id types.MessageID) (types.Message, bool) {
+			return fooMsg, id == fooMsg.ID()
+		},
+		"",
+		querier,
+	)
+	report, err := it.Validate()
+	assert.NilError(t, err)
+	assert.Equal(t, report.Ticks, 2)
+	assert.Equal(t, report.Transactions, 3)
+	assert.Equal(t, report.DecodeFailures, 1)
+	assert.Equal(t, report.UnknownMessageIDs, 1)
+	assert.Len(t, report.Failures, 2)
+	assert.Equal(t, report.Failures[0].Tick, uint64(1))
+	assert.Equal(t, report.Failures[1].MsgID, types.MessageID(99))
+	// Validating doesn't count as processing the ticks.
+	assertNoCursor(t, it)
+}
+
 func TestIteratorStartRange(t *testing.T) {
 	querier := &mockQuerier{retErr: errors.New("whatever")}
 	it := iterator.New(nil, "", querier)
@@ -233,7 +411,263 @@ func TestStartGreaterThanStopRange(t *testing.T) {
 	assert.ErrorContains(t, err, "first number in range must be less than the second (start,stop)")
 }
 
+func TestCursorDoesNotAdvancePastFailedTick(t *testing.T) {
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{{Epoch: 12}, {Epoch: 13}, {Epoch: 14}, {Epoch: 15}},
+				Page:   &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.New(nil, "", querier)
+	assertNoCursor(t, it)
+
+	callbackErr := errors.New("failed to process tick")
+	var processed []uint64
+	err := it.Each(func(_ []*iterator.TxBatch, tick, _ uint64) error {
+		if tick == 14 {
+			return callbackErr
+		}
+		processed = append(processed, tick)
+		return nil
+	})
+	assert.ErrorIs(t, err, callbackErr)
+	assert.DeepEqual(t, processed, []uint64{12, 13})
+	assertCursor(t, it, 13)
+}
+
+func TestCursorTellsTickZeroApartFromNoTick(t *testing.T) {
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{{Epoch: 0}},
+				Page:   &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.New(nil, "", querier)
+	assertNoCursor(t, it)
+
+	err := it.Each(func(_ []*iterator.TxBatch, _, _ uint64) error {
+		return nil
+	})
+	assert.NilError(t, err)
+	assertCursor(t, it, 0)
+}
+
+func TestNewFromCursorResumesAfterCursor(t *testing.T) {
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{{Epoch: 14}, {Epoch: 15}},
+				Page:   &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.NewFromCursor(nil, "", querier, 13)
+	assertCursor(t, it, 13)
+
+	err := it.Each(func(_ []*iterator.TxBatch, _, _ uint64) error {
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, parsePageKey(querier.request.GetPage().GetKey()), uint64(14))
+	assertCursor(t, it, 15)
+}
+
+type progressReport struct {
+	Tick    uint64
+	HasMore bool
+}
+
+func TestEachWithProgressIsCalledOncePerPage(t *testing.T) {
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{Epochs: []*shard.Epoch{{Epoch: 12}}, Page: &shard.PageResponse{Key: makePageKey(13)}},
+			{Epochs: []*shard.Epoch{{Epoch: 13}}, Page: &shard.PageResponse{Key: makePageKey(14)}},
+			{Epochs: []*shard.Epoch{{Epoch: 14}}, Page: &shard.PageResponse{}},
+		},
+	}
+	it := iterator.New(nil, "", querier)
+	var reports []progressReport
+	err := it.EachWithProgress(
+		func(_ []*iterator.TxBatch, _, _ uint64) error { return nil },
+		func(tick uint64, hasMore bool) { reports = append(reports, progressReport{tick, hasMore}) },
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reports, []progressReport{{12, true}, {13, true}, {14, false}})
+}
+
+func TestEachWithProgressStopsReportingAtStopRange(t *testing.T) {
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{Epochs: []*shard.Epoch{{Epoch: 12}}, Page: &shard.PageResponse{Key: makePageKey(13)}},
+			{Epochs: []*shard.Epoch{{Epoch: 13}, {Epoch: 20}}, Page: &shard.PageResponse{Key: makePageKey(21)}},
+			{Epochs: []*shard.Epoch{{Epoch: 21}}, Page: &shard.PageResponse{}},
+		},
+	}
+	it := iterator.New(nil, "", querier)
+	var reports []progressReport
+	err := it.EachWithProgress(
+		func(_ []*iterator.TxBatch, _, _ uint64) error { return nil },
+		func(tick uint64, hasMore bool) { reports = append(reports, progressReport{tick, hasMore}) },
+		12, 15,
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reports, []progressReport{{12, true}, {13, false}})
+	assert.Equal(t, querier.i, 2)
+}
+
+func makePageKey(tick uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, tick)
+	return buf
+}
+
+// assertCursor asserts that the iterator has processed ticks up to and including the given tick.
+func assertCursor(t *testing.T, it iterator.Iterator, want uint64) {
+	t.Helper()
+	cursor, ok := it.Cursor()
+	assert.Assert(t, ok)
+	assert.Equal(t, cursor, want)
+}
+
+// assertNoCursor asserts that the iterator has not processed any tick and was not created from a cursor.
+func assertNoCursor(t *testing.T, it iterator.Iterator) {
+	t.Helper()
+	_, ok := it.Cursor()
+	assert.Assert(t, !ok)
+}
+
 func parsePageKey(key []byte) uint64 {
 	tick := binary.BigEndian.Uint64(key)
 	return tick
 }
+
+// fooCodec encodes a fooIn as 8 big-endian bytes.
+type fooCodec struct {
+	decoded int
+}
+
+func (c *fooCodec) Marshal(in fooIn) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(in.X)), nil //nolint:gosec // test values are positive
+}
+
+func (c *fooCodec) Unmarshal(bz []byte) (fooIn, error) {
+	c.decoded++
+	if len(bz) != 8 {
+		return fooIn{}, errors.New("foo must be 8 bytes")
+	}
+	return fooIn{X: int(binary.BigEndian.Uint64(bz))}, nil //nolint:gosec // test values are positive
+}
+
+func TestIteratorDecodesWithTheMessageCodec(t *testing.T) {
+	fooCodec := &fooCodec{}
+	codecMsg := cardinal.NewMessageType[fooIn, fooOut]("foo-codec",
+		cardinal.WithMessageCodec[fooIn, fooOut](fooCodec))
+	assert.NilError(t, codecMsg.SetID(11))
+	namespace := "ns"
+	msgValue := fooIn{7}
+	msgBytes, err := codecMsg.Encode(msgValue)
+	assert.NilError(t, err)
+	// The body is not the JSON encoding of the message.
+	_, err = fooMsg.Decode(msgBytes)
+	assert.IsError(t, err)
+
+	txBz, err := proto.Marshal(&shard.Transaction{PersonaTag: "ty", Namespace: namespace, Body: msgBytes})
+	assert.NilError(t, err)
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{
+					{
+						Epoch: 12,
+						Txs: []*shard.TxData{
+							{TxId: uint64(codecMsg.ID()), GameShardTransaction: txBz},
+						},
+					},
+				},
+				Page: &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.New(
+		func(id types.MessageID) (types.Message, bool) {
+			if id == codecMsg.ID() {
+				return codecMsg, true
+			}
+			return nil, false
+		},
+		namespace,
+		querier,
+	)
+	err = it.Each(func(batch []*iterator.TxBatch, _, _ uint64) error {
+		assert.Len(t, batch, 1)
+		assert.Equal(t, batch[0].MsgValue, msgValue)
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, fooCodec.decoded, 1)
+}
+
+func TestBatchWindowDeliversQueriedTicksOneAtATime(t *testing.T) {
+	assert.NilError(t, fooMsg.SetID(10))
+	txsFor := func(values ...int) []*shard.TxData {
+		txs := make([]*shard.TxData, 0, len(values))
+		for _, v := range values {
+			body, err := fooMsg.Encode(fooIn{v})
+			assert.NilError(t, err)
+			txBz, err := proto.Marshal(&shard.Transaction{PersonaTag: "ty", Namespace: "ns", Body: body})
+			assert.NilError(t, err)
+			txs = append(txs, &shard.TxData{TxId: uint64(fooMsg.ID()), GameShardTransaction: txBz})
+		}
+		return txs
+	}
+	querier := &mockQuerier{
+		ret: []*shard.QueryTransactionsResponse{
+			{
+				Epochs: []*shard.Epoch{
+					{Epoch: 5, UnixTimestamp: 50, Txs: txsFor(1, 2)},
+					{Epoch: 6, UnixTimestamp: 60, Txs: txsFor(3)},
+					{Epoch: 8, UnixTimestamp: 80, Txs: txsFor(4, 5, 6)},
+				},
+				Page: &shard.PageResponse{},
+			},
+		},
+	}
+	it := iterator.New(
+		func(id types.MessageID) (types.Message, bool) { return fooMsg, id == fooMsg.ID() },
+		"ns",
+		querier,
+		iterator.WithBatchWindow(3),
+	)
+
+	type delivered struct {
+		tick, timestamp uint64
+		values          []int
+	}
+	var got []delivered
+	err := it.Each(func(batch []*iterator.TxBatch, tick, timestamp uint64) error {
+		d := delivered{tick: tick, timestamp: timestamp}
+		for _, tx := range batch {
+			d.values = append(d.values, tx.MsgValue.(fooIn).X)
+		}
+		got = append(got, d)
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, querier.calls, 1)
+	assert.Equal(t, querier.request.GetPage().GetLimit(), uint32(3))
+	assert.Equal(t, len(got), 3)
+	assert.Equal(t, got[0].tick, uint64(5))
+	assert.Equal(t, got[0].timestamp, uint64(50))
+	assert.DeepEqual(t, got[0].values, []int{1, 2})
+	assert.Equal(t, got[1].tick, uint64(6))
+	assert.Equal(t, got[1].timestamp, uint64(60))
+	assert.DeepEqual(t, got[1].values, []int{3})
+	assert.Equal(t, got[2].tick, uint64(8))
+	assert.Equal(t, got[2].timestamp, uint64(80))
+	assert.DeepEqual(t, got[2].values, []int{4, 5, 6})
+	assertCursor(t, it, 8)
+}
@@ -0,0 +1,153 @@
+package iterator
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+var _ Iterator = (*memoryIterator)(nil)
+
+// MemoryTx is a transaction that a memory iterator returns in the given tick.
+type MemoryTx struct {
+	Tick uint64
+	// Timestamp is the UNIX timestamp of the tick in milliseconds. The timestamp of the first transaction of a tick is
+	// used for the whole tick.
+	Timestamp uint64
+	Tx        *sign.Transaction
+	MsgID     types.MessageID
+	MsgValue  any
+}
+
+// memoryTick is a tick of transactions of a memory iterator.
+type memoryTick struct {
+	tick      uint64
+	timestamp uint64
+	batches   []*TxBatch
+}
+
+// memoryIterator is an Iterator that returns transactions from memory instead of querying them from the base shard.
+type memoryIterator struct {
+	ticks     []memoryTick
+	cursor    uint64
+	hasCursor bool
+}
+
+// NewMemory creates an Iterator that returns the given transactions instead of querying them from the base shard, so
+// that systems can be tested against a stream of transactions without a base shard. Like the transactions stored on
+// the base shard, only ticks that have transactions are returned, in order of their tick. Transactions of the same
+// tick are returned in the given order. The iterator honors the ranges and cursor the same way as the one returned by
+// New.
+func NewMemory(txs []MemoryTx) Iterator {
+	it := &memoryIterator{ticks: nil, cursor: 0, hasCursor: false}
+	for _, tx := range txs {
+		i, found := slices.BinarySearchFunc(it.ticks, tx.Tick, func(t memoryTick, tick uint64) int {
+			return cmp.Compare(t.tick, tick)
+		})
+		if !found {
+			it.ticks = slices.Insert(it.ticks, i, memoryTick{tick: tx.Tick, timestamp: tx.Timestamp, batches: nil})
+		}
+		it.ticks[i].batches = append(it.ticks[i].batches, &TxBatch{Tx: tx.Tx, MsgID: tx.MsgID, MsgValue: tx.MsgValue})
+	}
+	return it
+}
+
+func (m *memoryIterator) Each(fn func(batch []*TxBatch, tick, timestamp uint64) error, ranges ...uint64) error {
+	return m.each(context.Background(), fn, nil, ranges...)
+}
+
+func (m *memoryIterator) EachWithProgress(
+	fn func(batch []*TxBatch, tick, timestamp uint64) error,
+	progressFn func(currentTick uint64, hasMore bool),
+	ranges ...uint64,
+) error {
+	return m.each(context.Background(), fn, progressFn, ranges...)
+}
+
+func (m *memoryIterator) EachCtx(
+	ctx context.Context,
+	fn func(batch []*TxBatch, tick, timestamp uint64) error,
+	ranges ...uint64,
+) error {
+	return m.each(ctx, fn, nil, ranges...)
+}
+
+// Validate reports the ticks and transactions in the given ranges. The transactions of a memory iterator are already
+// decoded, so none of them fail.
+func (m *memoryIterator) Validate(ranges ...uint64) (ValidationReport, error) {
+	report := ValidationReport{}
+	ticks, err := m.ticksInRange(ranges...)
+	if err != nil {
+		return report, err
+	}
+	for _, tick := range ticks {
+		report.Ticks++
+		report.Transactions += len(tick.batches)
+	}
+	return report, nil
+}
+
+func (m *memoryIterator) Cursor() (uint64, bool) {
+	return m.cursor, m.hasCursor
+}
+
+// each passes the ticks in the given ranges to fn one at a time, like each does for the pages queried from the base
+// shard.
+func (m *memoryIterator) each(
+	ctx context.Context,
+	fn func(batch []*TxBatch, tick, timestamp uint64) error,
+	progressFn func(currentTick uint64, hasMore bool),
+	ranges ...uint64,
+) error {
+	if len(ranges) == 0 && m.hasCursor {
+		ranges = []uint64{m.cursor + 1}
+	}
+	ticks, err := m.ticksInRange(ranges...)
+	if err != nil {
+		return err
+	}
+	for i, tick := range ticks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(tick.batches, tick.tick, tick.timestamp); err != nil {
+			return err
+		}
+		m.cursor = tick.tick
+		m.hasCursor = true
+		if progressFn != nil {
+			progressFn(m.cursor, i < len(ticks)-1)
+		}
+	}
+	return nil
+}
+
+// ticksInRange returns the ticks in the given ranges, see Each. Like for the base shard, a stop tick of 0 means there
+// is no stop tick.
+func (m *memoryIterator) ticksInRange(ranges ...uint64) ([]memoryTick, error) {
+	startTick, stopTick := uint64(0), uint64(0)
+	if len(ranges) > 0 {
+		startTick = ranges[0]
+		if len(ranges) > 1 {
+			stopTick = ranges[1]
+			if ranges[0] > ranges[1] {
+				return nil, errors.New("first number in range must be less than the second (start,stop)")
+			}
+		}
+	}
+	var ticks []memoryTick
+	for _, tick := range m.ticks {
+		if tick.tick < startTick {
+			continue
+		}
+		if stopTick != 0 && tick.tick > stopTick {
+			break
+		}
+		ticks = append(ticks, tick)
+	}
+	return ticks, nil
+}
@@ -0,0 +1,97 @@
+package iterator_test
+
+import (
+	"errors"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/router/iterator"
+	"pkg.world.dev/world-engine/sign"
+)
+
+func newMemoryIterator() iterator.Iterator {
+	txs := make([]iterator.MemoryTx, 0)
+	for _, tick := range []uint64{3, 1, 7, 5} {
+		txs = append(txs, iterator.MemoryTx{
+			Tick:      tick,
+			Timestamp: tick * 1000,
+			Tx:        &sign.Transaction{PersonaTag: "ty"},
+			MsgID:     10,
+			MsgValue:  fooIn{X: int(tick)},
+		})
+	}
+	// A second transaction for tick 3.
+	txs = append(txs, iterator.MemoryTx{
+		Tick:      3,
+		Timestamp: 3000,
+		Tx:        &sign.Transaction{PersonaTag: "ty"},
+		MsgID:     10,
+		MsgValue:  fooIn{X: 30},
+	})
+	return iterator.NewMemory(txs)
+}
+
+func collectTicks(t *testing.T, it iterator.Iterator, ranges ...uint64) []uint64 {
+	var ticks []uint64
+	err := it.Each(func(_ []*iterator.TxBatch, tick, _ uint64) error {
+		ticks = append(ticks, tick)
+		return nil
+	}, ranges...)
+	assert.NilError(t, err)
+	return ticks
+}
+
+func TestMemoryIteratorReturnsTicksInOrder(t *testing.T) {
+	var values []int
+	var timestamps []uint64
+	err := newMemoryIterator().Each(func(batches []*iterator.TxBatch, _, timestamp uint64) error {
+		for _, batch := range batches {
+			value, ok := batch.MsgValue.(fooIn)
+			assert.Assert(t, ok)
+			values = append(values, value.X)
+		}
+		timestamps = append(timestamps, timestamp)
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, []int{1, 3, 30, 5, 7})
+	assert.DeepEqual(t, timestamps, []uint64{1000, 3000, 5000, 7000})
+}
+
+func TestMemoryIteratorRanges(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ranges []uint64
+		want   []uint64
+	}{
+		{name: "no range", ranges: nil, want: []uint64{1, 3, 5, 7}},
+		{name: "start only", ranges: []uint64{4}, want: []uint64{5, 7}},
+		{name: "start and stop", ranges: []uint64{2, 5}, want: []uint64{3, 5}},
+		{name: "single tick", ranges: []uint64{3, 3}, want: []uint64{3}},
+		{name: "no ticks in range", ranges: []uint64{8, 10}, want: nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, collectTicks(t, newMemoryIterator(), tc.ranges...), tc.want)
+		})
+	}
+}
+
+func TestMemoryIteratorRejectsStartGreaterThanStop(t *testing.T) {
+	err := newMemoryIterator().Each(nil, 5, 2)
+	assert.ErrorContains(t, err, "first number in range must be less than the second (start,stop)")
+}
+
+func TestMemoryIteratorResumesAfterCursor(t *testing.T) {
+	it := newMemoryIterator()
+	errStop := errors.New("stop")
+	err := it.Each(func(_ []*iterator.TxBatch, tick, _ uint64) error {
+		if tick == 5 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assertCursor(t, it, 3)
+	assert.DeepEqual(t, collectTicks(t, it), []uint64{5, 7})
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,6 +35,21 @@ func (m *MockIterator) EXPECT() *MockIteratorMockRecorder {
 	return m.recorder
 }
 
+// Cursor mocks base method.
+func (m *MockIterator) Cursor() (uint64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cursor")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Cursor indicates an expected call of Cursor.
+func (mr *MockIteratorMockRecorder) Cursor() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cursor", reflect.TypeOf((*MockIterator)(nil).Cursor))
+}
+
 // Each mocks base method.
 func (m *MockIterator) Each(fn func([]*iterator.TxBatch, uint64, uint64) error, ranges ...uint64) error {
 	m.ctrl.T.Helper()
@@ -52,3 +68,60 @@ func (mr *MockIteratorMockRecorder) Each(fn interface{}, ranges ...interface{})
 	varargs := append([]interface{}{fn}, ranges...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Each", reflect.TypeOf((*MockIterator)(nil).Each), varargs...)
 }
+
+// EachCtx mocks base method.
+func (m *MockIterator) EachCtx(ctx context.Context, fn func([]*iterator.TxBatch, uint64, uint64) error, ranges ...uint64) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fn}
+	for _, a := range ranges {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EachCtx", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachCtx indicates an expected call of EachCtx.
+func (mr *MockIteratorMockRecorder) EachCtx(ctx, fn interface{}, ranges ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fn}, ranges...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachCtx", reflect.TypeOf((*MockIterator)(nil).EachCtx), varargs...)
+}
+
+// EachWithProgress mocks base method.
+func (m *MockIterator) EachWithProgress(fn func([]*iterator.TxBatch, uint64, uint64) error, progressFn func(uint64, bool), ranges ...uint64) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{fn, progressFn}
+	for _, a := range ranges {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EachWithProgress", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachWithProgress indicates an expected call of EachWithProgress.
+func (mr *MockIteratorMockRecorder) EachWithProgress(fn, progressFn interface{}, ranges ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{fn, progressFn}, ranges...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachWithProgress", reflect.TypeOf((*MockIterator)(nil).EachWithProgress), varargs...)
+}
+
+// Validate mocks base method.
+func (m *MockIterator) Validate(ranges ...uint64) (iterator.ValidationReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range ranges {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Validate", varargs...)
+	ret0, _ := ret[0].(iterator.ValidationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockIteratorMockRecorder) Validate(ranges ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockIterator)(nil).Validate), ranges...)
+}
@@ -0,0 +1,39 @@
+package iterator
+
+import (
+	"math"
+	"time"
+)
+
+type Option func(*iterator)
+
+// WithRetry makes the iterator retry queries to the base shard that fail with a transient transport error, such as the
+// base shard being briefly unavailable. A query is attempted at most maxAttempts times, and the delay between attempts
+// starts at baseDelay and doubles after every failed attempt. Errors that are not transient are never retried.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(it *iterator) {
+		it.maxAttempts = max(maxAttempts, 1)
+		it.baseDelay = baseDelay
+	}
+}
+
+// WithPersonaFilter makes the iterator only deliver transactions signed by one of the given persona tags. Transactions
+// from other personas are skipped before their message bodies are decoded.
+func WithPersonaFilter(tags ...string) Option {
+	return func(it *iterator) {
+		it.personaFilter = make(map[string]struct{}, len(tags))
+		for _, tag := range tags {
+			it.personaFilter[tag] = struct{}{}
+		}
+	}
+}
+
+// WithBatchWindow makes the iterator request up to the given number of ticks from the base shard per query, instead of
+// one, to reduce the number of round trips during a sync. The ticks of each response are still passed to the callback
+// one at a time and in order, each with its own timestamp; the cursor and progress callbacks only advance once the
+// response has been queried, so ticks may be delivered later than they otherwise would. Values below 1 mean 1.
+func WithBatchWindow(ticks uint64) Option {
+	return func(it *iterator) {
+		it.batchWindow = uint32(min(max(ticks, 1), math.MaxUint32))
+	}
+}
@@ -37,12 +37,13 @@ func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
 }
 
 // AddEVMTransaction mocks base method.
-func (m *MockProvider) AddEVMTransaction(id types.MessageID, msgValue any, tx *sign.Transaction, evmTxHash string) (uint64, types.TxHash) {
+func (m *MockProvider) AddEVMTransaction(id types.MessageID, msgValue any, tx *sign.Transaction, evmTxHash string) (uint64, types.TxHash, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddEVMTransaction", id, msgValue, tx, evmTxHash)
 	ret0, _ := ret[0].(uint64)
 	ret1, _ := ret[1].(types.TxHash)
-	return ret0, ret1
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // AddEVMTransaction indicates an expected call of AddEVMTransaction.
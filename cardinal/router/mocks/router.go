@@ -36,6 +36,20 @@ func (m *MockRouter) EXPECT() *MockRouterMockRecorder {
 	return m.recorder
 }
 
+// IsConnected mocks base method.
+func (m *MockRouter) IsConnected() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsConnected")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsConnected indicates an expected call of IsConnected.
+func (mr *MockRouterMockRecorder) IsConnected() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConnected", reflect.TypeOf((*MockRouter)(nil).IsConnected))
+}
+
 // RegisterGameShard mocks base method.
 func (m *MockRouter) RegisterGameShard(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -18,7 +18,7 @@ type Provider interface {
 	WaitForNextTick() bool
 
 	AddEVMTransaction(id types.MessageID, msgValue any, tx *sign.Transaction, evmTxHash string) (
-		tick uint64, txHash types.TxHash,
+		tick uint64, txHash types.TxHash, err error,
 	)
 	ConsumeEVMMsgResult(evmTxHash string) ([]byte, []error, string, bool)
 }
@@ -11,6 +11,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
@@ -49,6 +50,9 @@ type Router interface {
 
 	TransactionIterator() iterator.Iterator
 
+	// IsConnected returns false if the connection to the base shard has failed or has been closed.
+	IsConnected() bool
+
 	// Shutdown gracefully stops the EVM gRPC handler.
 	Shutdown()
 	// Start serves the EVM gRPC server.
@@ -58,6 +62,7 @@ type Router interface {
 type router struct {
 	provider          Provider
 	ShardSequencer    shard.TransactionHandlerClient
+	conn              *grpc.ClientConn
 	namespace         string
 	server            *evmServer
 	sequencerJobQueue *jobqueue.JobQueue[*shard.SubmitTransactionsRequest]
@@ -91,6 +96,7 @@ func New(namespace, sequencerAddr, routerKey string, world Provider, opts ...Opt
 	if err != nil {
 		return nil, eris.Wrapf(err, "error dialing shard seqeuncer address at %q", sequencerAddr)
 	}
+	rtr.conn = conn
 	rtr.ShardSequencer = shard.NewTransactionHandlerClient(conn)
 
 	// The job queue will have been initialized if the router option for in-memory job queues is used.
@@ -128,6 +134,15 @@ func (r *router) RegisterGameShard(ctx context.Context) error {
 	return nil
 }
 
+func (r *router) IsConnected() bool {
+	if r.conn == nil {
+		return false
+	}
+	// Connections are established lazily, so an idle connection is not considered to be down.
+	state := r.conn.GetState()
+	return state != connectivity.TransientFailure && state != connectivity.Shutdown
+}
+
 func (r *router) SubmitTxBlob(
 	ctx context.Context,
 	processedTxs txpool.TxMap,
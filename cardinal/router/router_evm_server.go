@@ -24,6 +24,7 @@ const (
 	CodeUnauthorized
 	CodeUnsupportedMessage
 	CodeInvalidFormat
+	CodeQueueFull
 )
 
 var _ routerv1.MsgServer = (*evmServer)(nil)
@@ -121,7 +122,13 @@ func (e *evmServer) SendMessage(
 	// since we are injecting the msgValue directly, all we need is the persona tag in the signed payload.
 	// the sig checking happens in the grpcServer's Handler, not in ecs.Engine.
 	sig := &sign.Transaction{PersonaTag: req.GetPersonaTag()}
-	e.provider.AddEVMTransaction(msgType.ID(), msgValue, sig, req.GetEvmTxHash())
+	if _, _, err := e.provider.AddEVMTransaction(msgType.ID(), msgValue, sig, req.GetEvmTxHash()); err != nil {
+		return &routerv1.SendMessageResponse{
+			Errs:      err.Error(),
+			EvmTxHash: req.GetEvmTxHash(),
+			Code:      CodeQueueFull,
+		}, nil
+	}
 
 	// wait for the next tick so the msgValue gets processed
 	success := e.provider.WaitForNextTick()
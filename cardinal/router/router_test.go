@@ -73,6 +73,14 @@ func (f *mockMsg) GetInFieldInformation() map[string]any {
 	return map[string]any{"foo": "bar"}
 }
 
+func (f *mockMsg) GetInSchema() ([]byte, error) {
+	return nil, nil
+}
+
+func (f *mockMsg) GetOutSchema() ([]byte, error) {
+	return nil, nil
+}
+
 var _ shard.TransactionHandlerClient = &fakeTxHandler{}
 
 type fakeTxHandler struct {
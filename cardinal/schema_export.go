@@ -0,0 +1,103 @@
+package cardinal
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/rotisserie/eris"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// ExportedSchema is the document returned by World.ExportSchema. It is a JSON schema whose "components" and "messages"
+// describe the registered components and messages. The struct types they refer to are described once in "$defs".
+type ExportedSchema struct {
+	Schema     string                           `json:"$schema"`
+	Namespace  string                           `json:"title"`
+	Components map[string]json.RawMessage       `json:"components"`
+	Messages   map[string]ExportedMessageSchema `json:"messages"`
+	Defs       map[string]json.RawMessage       `json:"$defs"`
+}
+
+// ExportedMessageSchema describes the input and output of a message in an ExportedSchema.
+type ExportedMessageSchema struct {
+	In  json.RawMessage `json:"in"`
+	Out json.RawMessage `json:"out"`
+}
+
+// ExportSchema returns a JSON schema document describing every registered component, keyed by component name, and
+// every registered message, keyed by its full name. It is the contract between the world and its clients, and can be
+// used to generate client code or to validate payloads.
+func (w *World) ExportSchema() ([]byte, error) {
+	doc := ExportedSchema{
+		Schema:     jsonSchemaDraft,
+		Namespace:  w.Namespace(),
+		Components: map[string]json.RawMessage{},
+		Messages:   map[string]ExportedMessageSchema{},
+		Defs:       map[string]json.RawMessage{},
+	}
+
+	for _, c := range w.GetComponents() {
+		schema, err := doc.hoistDefs(c.GetSchema())
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to export the schema of component %q", c.Name())
+		}
+		doc.Components[c.Name()] = schema
+	}
+
+	for _, msg := range w.GetRegisteredMessages() {
+		in, err := msg.GetInSchema()
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to export the input schema of message %q", msg.FullName())
+		}
+		out, err := msg.GetOutSchema()
+		if err != nil {
+			return nil, eris.Wrapf(err, "failed to export the output schema of message %q", msg.FullName())
+		}
+		var msgSchema ExportedMessageSchema
+		if msgSchema.In, err = doc.hoistDefs(in); err != nil {
+			return nil, eris.Wrapf(err, "failed to export the input schema of message %q", msg.FullName())
+		}
+		if msgSchema.Out, err = doc.hoistDefs(out); err != nil {
+			return nil, eris.Wrapf(err, "failed to export the output schema of message %q", msg.FullName())
+		}
+		doc.Messages[msg.FullName()] = msgSchema
+	}
+
+	bz, err := json.Marshal(doc)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to marshal the exported schema")
+	}
+	return bz, nil
+}
+
+// hoistDefs moves the definitions of the given schema into the definitions of the document, so that its references
+// resolve against the document, and returns what is left of the schema. Two types may only share a definition name if
+// they are described the same way.
+func (doc *ExportedSchema) hoistDefs(schema []byte) (json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(schema, &fields); err != nil {
+		return nil, eris.Wrap(err, "failed to decode schema")
+	}
+
+	if rawDefs, ok := fields["$defs"]; ok {
+		defs := map[string]json.RawMessage{}
+		if err := json.Unmarshal(rawDefs, &defs); err != nil {
+			return nil, eris.Wrap(err, "failed to decode schema definitions")
+		}
+		for name, def := range defs {
+			if existing, ok := doc.Defs[name]; ok && !bytes.Equal(existing, def) {
+				return nil, eris.Errorf("conflicting definitions of type %q", name)
+			}
+			doc.Defs[name] = def
+		}
+	}
+	delete(fields, "$defs")
+	delete(fields, "$schema")
+
+	bz, err := json.Marshal(fields)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to encode schema")
+	}
+	return bz, nil
+}
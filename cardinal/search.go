@@ -1,8 +1,13 @@
 package cardinal
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"math"
 	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/rotisserie/eris"
 
@@ -12,11 +17,53 @@ import (
 
 const badEntityID types.EntityID = math.MaxUint64
 
+// cache holds the archetypes matched by a filter, and the number of archetypes that were searched for them. It is
+// either owned by a search, or shared by the searches with the same filter key of a world.
 type cache struct {
+	mu         sync.Mutex
 	archetypes []types.ArchetypeID
 	seen       int
 }
 
+// worldCaches holds the archetypes matched by a search in each of the worlds it was evaluated against, since
+// archetype IDs are only meaningful within the world they were created in. Like the caches of the world, they are kept
+// apart for the readers that see the pending archetypes and those that don't. They are only used for filters without a
+// key, whose archetypes can't be shared with other searches, and are only released by Search.Reset, so such a search
+// keeps every world it was evaluated against alive until it is reset.
+type worldCaches struct {
+	mu      sync.Mutex
+	byWorld map[worldCacheKey]*cache
+}
+
+type worldCacheKey struct {
+	world   *World
+	pending bool
+}
+
+func newWorldCaches() *worldCaches {
+	return &worldCaches{byWorld: map[worldCacheKey]*cache{}}
+}
+
+// get returns the cache for the given world, creating it if needed. The cache includes the pending archetypes if
+// pending is true.
+func (c *worldCaches) get(w *World, pending bool) *cache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := worldCacheKey{world: w, pending: pending}
+	wc, ok := c.byWorld[key]
+	if !ok {
+		wc = &cache{}
+		c.byWorld[key] = wc
+	}
+	return wc
+}
+
+func (c *worldCaches) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.byWorld)
+}
+
 //revive:disable-next-line
 type EntitySearch interface {
 	Searchable
@@ -26,10 +73,16 @@ type EntitySearch interface {
 type Searchable interface {
 	evaluateSearch(wCtx WorldContext) []types.ArchetypeID
 	Each(wCtx WorldContext, callback CallbackFn) error
+	EachLimit(wCtx WorldContext, limit int, callback CallbackFn) error
+	Seq(wCtx WorldContext) func(yield func(types.EntityID, error) bool)
+	EachReverse(wCtx WorldContext, callback CallbackFn) error
 	First(wCtx WorldContext) (types.EntityID, error)
 	MustFirst(wCtx WorldContext) types.EntityID
+	Last(wCtx WorldContext) (types.EntityID, error)
 	Count(wCtx WorldContext) (int, error)
+	Any(wCtx WorldContext) (bool, error)
 	Collect(wCtx WorldContext) ([]types.EntityID, error)
+	Reset()
 }
 
 type CallbackFn func(types.EntityID) bool
@@ -41,8 +94,12 @@ type CallbackFn func(types.EntityID) bool
 // So it is not recommended to create a new search every time you want
 // to filter entities with the same search.
 type Search struct {
-	archMatches             *cache
-	filter                  filter.ComponentFilter
+	archMatches *worldCaches
+	filter      filter.ComponentFilter
+	// filterKey is the key of the filter, computed once when the filter is set. It is empty if the filter has no key.
+	filterKey string
+	// rescan is set by Reset, so that the next evaluation drops the archetypes the world caches for the filter key.
+	rescan                  atomic.Bool
 	componentPropertyFilter FilterFn
 }
 
@@ -120,14 +177,17 @@ func NewSearch() searchBuilder {
 // cardinal.NewLegacySearch().Entity(filter.Exact(Alpha{}, Beta{})).Count()
 func NewLegacySearch(componentFilter filter.ComponentFilter) EntitySearch {
 	return &Search{
-		archMatches:             &cache{},
+		archMatches:             newWorldCaches(),
 		filter:                  componentFilter,
+		filterKey:               filter.Key(componentFilter),
+		rescan:                  atomic.Bool{},
 		componentPropertyFilter: nil,
 	}
 }
 
 func (s *Search) Entity(componentFilter filter.ComponentFilter) EntitySearch {
 	s.filter = componentFilter
+	s.filterKey = filter.Key(componentFilter)
 	return s
 }
 
@@ -141,19 +201,22 @@ func (s *Search) Where(componentFilter FilterFn) EntitySearch {
 		componentPropertyFilter = componentFilter
 	}
 	return &Search{
-		archMatches:             &cache{},
+		archMatches:             newWorldCaches(),
 		filter:                  s.filter,
+		filterKey:               s.filterKey,
+		rescan:                  atomic.Bool{},
 		componentPropertyFilter: componentPropertyFilter,
 	}
 }
 
-// Each iterates over all entities that match the search.
+// Each iterates over all entities that match the search, archetype by archetype. With WithStableIteration, entities
+// are visited in ascending order of their IDs instead.
 // If you would like to stop the iteration, return false to the callback. To continue iterating, return true.
 func (s *Search) Each(wCtx WorldContext, callback CallbackFn) (err error) {
 	defer func() { defer panicOnFatalError(wCtx, err) }()
 
 	result := s.evaluateSearch(wCtx)
-	iter := newSearchIterator(wCtx.storeReader(), result)
+	iter := newEntityIterator(wCtx, result)
 	for iter.HasNext() {
 		entities, err := iter.Next()
 		if err != nil {
@@ -181,13 +244,345 @@ func (s *Search) Each(wCtx WorldContext, callback CallbackFn) (err error) {
 	return nil
 }
 
+// EachReverse iterates over all entities that match the search in the reverse order of Each: archetypes are walked
+// from the last matching archetype to the first, and the entities of each archetype from newest to oldest. With
+// WithStableIteration, entities are visited from the highest ID to the lowest.
+// If you would like to stop the iteration, return false to the callback. To continue iterating, return true.
+func (s *Search) EachReverse(wCtx WorldContext, callback CallbackFn) (err error) {
+	defer func() { defer panicOnFatalError(wCtx, err) }()
+
+	result := s.evaluateSearch(wCtx)
+	if w := wCtx.getWorld(); w != nil && w.stableIteration {
+		// Every entity is returned at once, sorted by ID.
+		iter := newSortedSearchIterator(wCtx.storeReader(), result)
+		entities, err := iter.Next()
+		if err != nil {
+			return err
+		}
+		s.eachInReverse(wCtx, entities, callback)
+		return nil
+	}
+	for i := len(result) - 1; i >= 0; i-- {
+		entities, err := wCtx.storeReader().GetEntitiesForArchID(result[i])
+		if err != nil {
+			return err
+		}
+		if !s.eachInReverse(wCtx, entities, callback) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// eachInReverse calls the callback with the given entities that pass the where clauses of the search, from the last
+// entity to the first. It returns false if the callback stopped the iteration.
+func (s *Search) eachInReverse(wCtx WorldContext, entities []types.EntityID, callback CallbackFn) bool {
+	for j := len(entities) - 1; j >= 0; j-- {
+		id := entities[j]
+		if s.componentPropertyFilter != nil {
+			filterValue, err := s.componentPropertyFilter(wCtx, id)
+			if err != nil || !filterValue {
+				continue
+			}
+		}
+		if !callback(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// EachLimit iterates over at most limit entities that match the search.
+// As with Each, returning false from the callback stops the iteration early.
+func (s *Search) EachLimit(wCtx WorldContext, limit int, callback CallbackFn) error {
+	return eachLimit(wCtx, s, limit, callback)
+}
+
+// Seq returns an iterator over the entities that match the search, in the same order as Each. It has the shape of an
+// iter.Seq2, so with Go 1.23 or later, the entities can be visited with a range loop:
+//
+//	for id, err := range search.Seq(wCtx) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Breaking out of the loop stops the iteration early. An error stops the iteration, and is yielded once along with an
+// entity ID of 0.
+func (s *Search) Seq(wCtx WorldContext) func(yield func(types.EntityID, error) bool) {
+	return eachSeq(wCtx, s)
+}
+
+// EachComponent iterates over all entities that match the search and passes each entity along with its component of
+// type T to the callback. Returning false from the callback stops the iteration early.
+// An error is returned if a matched entity does not have a component of type T, which means the search's filter does
+// not guarantee the presence of T.
+func EachComponent[T types.Component](
+	wCtx WorldContext, search Searchable, callback func(types.EntityID, *T) bool,
+) error {
+	var getErr error
+	err := search.Each(wCtx, func(id types.EntityID) bool {
+		var comp *T
+		comp, getErr = GetComponent[T](wCtx, id)
+		if getErr != nil {
+			var t T
+			getErr = eris.Wrapf(getErr, "entity %d matched the search but does not have component %q", id, t.Name())
+			return false
+		}
+		return callback(id, comp)
+	})
+	if getErr != nil {
+		return getErr
+	}
+	return err
+}
+
+// ComponentEntry is an entity along with its component of type T.
+type ComponentEntry[T types.Component] struct {
+	ID        types.EntityID
+	Component T
+}
+
+// SortedBy returns all entities that match the search along with their component of type T, sorted by the component
+// according to less, e.g. to sort players by their score for a leaderboard. Entities whose components are equal
+// according to less are ordered by their ids. The component of each entity is only looked up once, and the order is
+// computed anew on every call; it is not cached. Like EachComponent, an error is returned if a matched entity does not
+// have a component of type T.
+func SortedBy[T types.Component](wCtx WorldContext, search Searchable, less func(a, b T) bool) (
+	[]ComponentEntry[T], error,
+) {
+	var entries []ComponentEntry[T]
+	err := EachComponent[T](wCtx, search, func(id types.EntityID, comp *T) bool {
+		entries = append(entries, ComponentEntry[T]{ID: id, Component: *comp})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entries, func(a, b ComponentEntry[T]) int {
+		switch {
+		case less(a.Component, b.Component):
+			return -1
+		case less(b.Component, a.Component):
+			return 1
+		default:
+			return cmp.Compare(a.ID, b.ID)
+		}
+	})
+	return entries, nil
+}
+
+// EntitiesNotFoundError is returned by ComponentsByID when some of the given entities do not exist or do not have the
+// requested component. It wraps ErrEntityDoesNotExist.
+type EntitiesNotFoundError struct {
+	ComponentName string
+	IDs           []types.EntityID
+}
+
+func (e *EntitiesNotFoundError) Error() string {
+	return fmt.Sprintf("entities %v do not exist or do not have component %q", e.IDs, e.ComponentName)
+}
+
+func (e *EntitiesNotFoundError) Unwrap() error {
+	return ErrEntityDoesNotExist
+}
+
+// ComponentsByID looks up the component of type T of each of the given entities, in the order of ids. Each entity is
+// resolved directly through its archetype, so for a small set of known entities this is cheaper than a search that
+// filters all archetypes. If any of the entities does not exist or does not have a component of type T, an
+// *EntitiesNotFoundError that lists all of them is returned.
+func ComponentsByID[T types.Component](wCtx WorldContext, ids []types.EntityID) ([]ComponentEntry[T], error) {
+	entries := make([]ComponentEntry[T], 0, len(ids))
+	var missing []types.EntityID
+	for _, id := range ids {
+		comp, err := GetComponent[T](wCtx, id)
+		if eris.Is(err, ErrEntityDoesNotExist) || eris.Is(err, ErrComponentNotOnEntity) {
+			missing = append(missing, id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ComponentEntry[T]{ID: id, Component: *comp})
+	}
+	if len(missing) > 0 {
+		var t T
+		return nil, eris.Wrap(&EntitiesNotFoundError{ComponentName: t.Name(), IDs: missing}, "")
+	}
+	return entries, nil
+}
+
+// EachReadOnly iterates over all entities that match the search, outside of a tick, with a read-only world context that
+// the callback can use to read components. Returning false from the callback stops the iteration early.
+//
+// This is meant for reading game state from outside of systems, e.g. for rendering or metrics. Read-only iterations
+// share a lock with each other, so any number of them can run concurrently, and they wait for the tick in progress (if
+// any) to be committed, so they never observe a partially applied tick. Writing components from the callback is not
+// supported and its behavior is undefined; the read-only world context rejects writes with ErrEntityMutationOnReadOnly.
+// Calling EachReadOnly from inside a system deadlocks. A search caches its results, so concurrent iterations must not
+// share the same search.
+func EachReadOnly(w *World, search Searchable, callback func(wCtx WorldContext, id types.EntityID) bool) error {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+	wCtx := NewReadOnlyWorldContext(w)
+	return search.Each(wCtx, func(id types.EntityID) bool {
+		return callback(wCtx, id)
+	})
+}
+
+// EachAcross iterates over the entities that match the search in each of the given worlds in turn, like EachReadOnly.
+// The callback is given the world the entity belongs to, along with a read-only world context of that world. Returning
+// false from the callback stops the iteration early, including for the remaining worlds. The archetypes matched by
+// the search are cached separately for each world, so the same search can be reused across calls.
+func EachAcross(
+	worlds []*World, search Searchable, callback func(w *World, wCtx WorldContext, id types.EntityID) bool,
+) error {
+	for _, w := range worlds {
+		stopped := false
+		err := EachReadOnly(w, search, func(wCtx WorldContext, id types.EntityID) bool {
+			if !callback(w, wCtx, id) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return eris.Wrapf(err, "failed to search world %q", w.Namespace())
+		}
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+// EachParallel calls the callback for the component T of every entity that matches the search, spreading the entities
+// across the given number of worker goroutines. It is meant for independent, CPU-bound work on each entity, such as
+// integrating physics. The components are read before the callback is called and written back once every callback
+// has returned, so the callback may modify the component it is given, but it must not touch any other entity or use
+// the world context to read or write the world's state, since the state is not safe for concurrent use. The one
+// exception is EmitTickEvent, which is safe for concurrent use. Entities that match the search but don't have the
+// component T are skipped. A workers value below 1 means 1. If a callback panics, the panic is returned as an error
+// once every worker has stopped, and no component is written back.
+func EachParallel[T types.Component](
+	wCtx WorldContext, search Searchable, workers int, callback func(id types.EntityID, comp *T),
+) error {
+	ids, err := search.Collect(wCtx)
+	if err != nil {
+		return err
+	}
+	matched := ids[:0]
+	comps := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		comp, err := GetComponent[T](wCtx, id)
+		if eris.Is(err, ErrComponentNotOnEntity) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		matched = append(matched, id)
+		comps = append(comps, comp)
+	}
+	ids = matched
+
+	workers = max(1, min(workers, len(ids)))
+	chunkSize := (len(ids) + workers - 1) / workers
+	// A panic in a worker would take down the process, so it is recovered and returned as an error instead.
+	panics := make([]error, workers)
+	var wg sync.WaitGroup
+	for worker, start := 0, 0; start < len(ids); worker, start = worker+1, start+chunkSize {
+		end := min(start+chunkSize, len(ids))
+		wg.Add(1)
+		go func(worker int, ids []types.EntityID, comps []*T) {
+			defer wg.Done()
+			var id types.EntityID
+			defer func() {
+				r := recover()
+				if rErr, ok := r.(error); ok {
+					panics[worker] = eris.Wrapf(rErr, "EachParallel callback panicked on entity %d", id)
+				} else if r != nil {
+					panics[worker] = eris.Errorf("EachParallel callback panicked on entity %d: %v", id, r)
+				}
+			}()
+			for i := range ids {
+				id = ids[i]
+				callback(id, comps[i])
+			}
+		}(worker, ids[start:end], comps[start:end])
+	}
+	wg.Wait()
+	// None of the components are written back if a callback panicked, as the others may be half updated.
+	if err := errors.Join(panics...); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if err := SetComponent[T](wCtx, id, comps[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// anyMatch reports whether the search matches at least one entity, stopping the iteration at the first match.
+func anyMatch(wCtx WorldContext, search Searchable) (bool, error) {
+	found := false
+	err := search.Each(wCtx, func(types.EntityID) bool {
+		found = true
+		return false
+	})
+	return found, err
+}
+
+// eachSeq turns the Each of the search into an iterator, see Search.Seq.
+func eachSeq(wCtx WorldContext, search Searchable) func(yield func(types.EntityID, error) bool) {
+	return func(yield func(types.EntityID, error) bool) {
+		stopped := false
+		err := search.Each(wCtx, func(id types.EntityID) bool {
+			stopped = !yield(id, nil)
+			return !stopped
+		})
+		// The iterator must not yield again once the loop has been broken out of.
+		if err != nil && !stopped {
+			yield(0, err)
+		}
+	}
+}
+
+// eachLimit wraps the callback so that the underlying Each stops as soon as limit entities have been visited.
+func eachLimit(wCtx WorldContext, search Searchable, limit int, callback CallbackFn) error {
+	if limit <= 0 {
+		return nil
+	}
+	visited := 0
+	return search.Each(wCtx, func(id types.EntityID) bool {
+		visited++
+		return callback(id) && visited < limit
+	})
+}
+
+// eachReverse calls the callback for each of the given ids from the last to the first.
+func eachReverse(ids []types.EntityID, callback CallbackFn) {
+	for i := len(ids) - 1; i >= 0; i-- {
+		if !callback(ids[i]) {
+			return
+		}
+	}
+}
+
 func fastSortIDs(ids []types.EntityID) {
 	slices.Sort(ids)
 }
 
+// Collect returns the sorted ids of all entities that match the search in a freshly allocated slice that is safe to
+// mutate.
 func (s *Search) Collect(wCtx WorldContext) ([]types.EntityID, error) {
-	acc := make([]types.EntityID, 0)
-	err := s.Each(wCtx, func(id types.EntityID) bool {
+	size, err := s.archetypeEntityCount(wCtx)
+	if err != nil {
+		return nil, err
+	}
+	acc := make([]types.EntityID, 0, size)
+	err = s.Each(wCtx, func(id types.EntityID) bool {
 		acc = append(acc, id)
 		return true
 	})
@@ -202,8 +597,14 @@ func (s *Search) Collect(wCtx WorldContext) ([]types.EntityID, error) {
 func (s *Search) Count(wCtx WorldContext) (ret int, err error) {
 	defer func() { defer panicOnFatalError(wCtx, err) }()
 
+	// Without a where clause, every entity of the matched archetypes is counted, so the entity counts of the archetypes
+	// are summed instead of going through their entities.
+	if s.componentPropertyFilter == nil {
+		return s.archetypeEntityCount(wCtx)
+	}
+
 	result := s.evaluateSearch(wCtx)
-	iter := newSearchIterator(wCtx.storeReader(), result)
+	iter := newEntityIterator(wCtx, result)
 	for iter.HasNext() {
 		entities, err := iter.Next()
 		if err != nil {
@@ -227,12 +628,33 @@ func (s *Search) Count(wCtx WorldContext) (ret int, err error) {
 	return ret, nil
 }
 
+// Any reports whether at least one entity matches the search. Without a where clause, it stops at the first matched
+// archetype that has entities, without going through the entities themselves.
+func (s *Search) Any(wCtx WorldContext) (found bool, err error) {
+	defer func() { defer panicOnFatalError(wCtx, err) }()
+
+	if s.componentPropertyFilter != nil {
+		return anyMatch(wCtx, s)
+	}
+	reader := wCtx.storeReader()
+	for _, archID := range s.evaluateSearch(wCtx) {
+		count, err := reader.EntityCountForArchID(archID)
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // First returns the first entity that matches the search.
 func (s *Search) First(wCtx WorldContext) (id types.EntityID, err error) {
 	defer func() { defer panicOnFatalError(wCtx, err) }()
 
 	result := s.evaluateSearch(wCtx)
-	iter := newSearchIterator(wCtx.storeReader(), result)
+	iter := newEntityIterator(wCtx, result)
 	if !iter.HasNext() {
 		return badEntityID, eris.Wrap(err, "")
 	}
@@ -267,9 +689,82 @@ func (s *Search) MustFirst(wCtx WorldContext) types.EntityID {
 	return id
 }
 
+// Last returns the last entity that matches the search, walking every matching archetype in order.
+// If no entity matches the search, ErrEntityDoesNotExist is returned.
+func (s *Search) Last(wCtx WorldContext) (id types.EntityID, err error) {
+	defer func() { defer panicOnFatalError(wCtx, err) }()
+
+	id = badEntityID
+	result := s.evaluateSearch(wCtx)
+	iter := newEntityIterator(wCtx, result)
+	for iter.HasNext() {
+		entities, err := iter.Next()
+		if err != nil {
+			return 0, err
+		}
+		for _, entityID := range entities {
+			var filterValue bool
+			if s.componentPropertyFilter != nil {
+				filterValue, err = s.componentPropertyFilter(wCtx, entityID)
+				if err != nil {
+					continue
+				}
+			} else {
+				filterValue = true
+			}
+			if filterValue {
+				id = entityID
+			}
+		}
+	}
+	if id == badEntityID {
+		return badEntityID, eris.Wrap(ErrEntityDoesNotExist, "no entity matches the search")
+	}
+	return id, nil
+}
+
+// Reset drops the archetypes cached for the search so that the next evaluation re-scans every archetype from the
+// start. The archetypes matched by a filter with a key are cached by the world and shared by all of its searches with
+// the same filter key, so the next evaluation drops them for the world it is evaluated against. The archetypes matched
+// by any other filter are cached by the search for each world it is evaluated against, and Reset drops them for every
+// world, which also releases the worlds a long-lived search was evaluated against (e.g. a search reused across test
+// worlds).
+func (s *Search) Reset() {
+	s.archMatches.reset()
+	s.rescan.Store(true)
+}
+
+// archetypeEntityCount returns the total number of entities in the archetypes that match the search. The where clause
+// is not evaluated, so this is an upper bound on the number of entities the search will return.
+func (s *Search) archetypeEntityCount(wCtx WorldContext) (int, error) {
+	total := 0
+	reader := wCtx.storeReader()
+	for _, archID := range s.evaluateSearch(wCtx) {
+		count, err := reader.EntityCountForArchID(archID)
+		if err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// evaluateSearch returns the archetypes that match the filter of the search. Searches whose filter has a key share
+// the matched archetypes with the other searches of the world with the same key, so that the archetypes are only
+// scanned once for all of them.
 func (s *Search) evaluateSearch(wCtx WorldContext) []types.ArchetypeID {
-	cache := s.archMatches
-	for it := wCtx.storeReader().SearchFrom(s.filter, cache.seen); it.HasNext(); {
+	var cache *cache
+	if s.filterKey != "" {
+		if s.rescan.CompareAndSwap(true, false) {
+			wCtx.getWorld().resetArchetypeCache(s.filterKey)
+		}
+		cache = wCtx.getArchetypeCache(s.filterKey)
+	} else {
+		cache = s.archMatches.get(wCtx.getWorld(), !wCtx.isReadOnly())
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for it := wCtx.storeReader().SearchFrom(withoutTags{filter: s.filter}, cache.seen); it.HasNext(); {
 		cache.archetypes = append(cache.archetypes, it.Next())
 	}
 	cache.seen = wCtx.storeReader().ArchetypeCount()
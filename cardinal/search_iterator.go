@@ -1,6 +1,8 @@
 package cardinal
 
 import (
+	"slices"
+
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/types"
 )
@@ -12,6 +14,8 @@ type searchIterator struct {
 	archIDs []types.ArchetypeID
 	// stateReader is an interface that allows us to read the current entity state
 	stateReader gamestate.Reader
+	// sorted makes the iterator return the entities of all archetypes at once, sorted by ID.
+	sorted bool
 }
 
 // newSearchIterator returns an iterator that returns the list of entities for the given archetype ids.
@@ -20,9 +24,27 @@ func newSearchIterator(stateReader gamestate.Reader, archIDs []types.ArchetypeID
 		current:     0,
 		archIDs:     archIDs,
 		stateReader: stateReader,
+		sorted:      false,
 	}
 }
 
+// newEntityIterator returns an iterator over the entities of the given archetypes, in the iteration order of the world
+// of the given context (see WithStableIteration).
+func newEntityIterator(wCtx WorldContext, archIDs []types.ArchetypeID) searchIterator {
+	if w := wCtx.getWorld(); w != nil && w.stableIteration {
+		return newSortedSearchIterator(wCtx.storeReader(), archIDs)
+	}
+	return newSearchIterator(wCtx.storeReader(), archIDs)
+}
+
+// newSortedSearchIterator returns an iterator that returns the entities of all the given archetypes in a single list
+// sorted by ID, so that the order does not depend on how the entities are laid out in their archetypes.
+func newSortedSearchIterator(stateReader gamestate.Reader, archIDs []types.ArchetypeID) searchIterator {
+	it := newSearchIterator(stateReader, archIDs)
+	it.sorted = true
+	return it
+}
+
 // HasNext evaluates to true if there are still archetypes to iterate over.
 func (it *searchIterator) HasNext() bool {
 	return it.current < len(it.archIDs)
@@ -30,7 +52,23 @@ func (it *searchIterator) HasNext() bool {
 
 // Next returns the next entity list based on the list of archetypes in archIds.
 func (it *searchIterator) Next() ([]types.EntityID, error) {
+	if it.sorted {
+		return it.nextSorted()
+	}
 	archetypeID := it.archIDs[it.current]
 	it.current++
 	return it.stateReader.GetEntitiesForArchID(archetypeID)
 }
+
+func (it *searchIterator) nextSorted() ([]types.EntityID, error) {
+	var ids []types.EntityID
+	for ; it.current < len(it.archIDs); it.current++ {
+		entities, err := it.stateReader.GetEntitiesForArchID(it.archIDs[it.current])
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, entities...)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
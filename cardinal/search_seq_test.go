@@ -0,0 +1,52 @@
+//go:build go1.23
+
+// The module supports Go versions without range over function iterators, so the range loops over Search.Seq are in
+// their own file that is only built with Go 1.23 or later.
+
+package cardinal_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func TestSearch_SeqStopsWhenBreakingOutOfTheLoop(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(worldCtx, 10, AlphaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(worldCtx, 10, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+
+	search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+	all, err := search.Collect(worldCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(all), 20)
+
+	// Break out of the loop in the second archetype.
+	var visited []types.EntityID
+	for id, err := range search.Seq(worldCtx) {
+		assert.NilError(t, err)
+		visited = append(visited, id)
+		if len(visited) == 15 {
+			break
+		}
+	}
+	assert.DeepEqual(t, visited, all[:15])
+
+	count := 0
+	for _, err := range cardinal.Not(search).Seq(worldCtx) {
+		assert.NilError(t, err)
+		count++
+	}
+	assert.Equal(t, count, 0)
+}
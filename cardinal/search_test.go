@@ -1,7 +1,13 @@
 package cardinal_test
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
@@ -496,3 +502,761 @@ func TestWhereClauseOnSearch(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Equal(t, amt, 40)
 }
+
+func TestSearch_Last(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+
+	// No archetype matches the search in an empty world.
+	_, err := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())).Last(worldCtx)
+	assert.ErrorIs(t, err, cardinal.ErrEntityDoesNotExist)
+
+	// Single archetype
+	alphaIDs, err := cardinal.CreateMany(worldCtx, 10, AlphaTest{})
+	assert.NilError(t, err)
+	id, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[AlphaTest]())).Last(worldCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, id, alphaIDs[len(alphaIDs)-1])
+
+	// Multiple archetypes
+	bothIDs, err := cardinal.CreateMany(worldCtx, 10, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+	id, err = cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())).Last(worldCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, id, bothIDs[len(bothIDs)-1])
+
+	// Composed searches return the highest matching id.
+	id, err = cardinal.Or(
+		cardinal.NewSearch().Entity(filter.Exact(filter.Component[AlphaTest]())),
+		cardinal.NewSearch().Entity(filter.Exact(filter.Component[BetaTest]())),
+	).Last(worldCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, id, alphaIDs[len(alphaIDs)-1])
+}
+
+func TestSearch_EachLimit(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(worldCtx, 10, AlphaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(worldCtx, 10, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+
+	search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+	testCases := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "zero limit", limit: 0, want: 0},
+		{name: "limit within first archetype", limit: 5, want: 5},
+		{name: "limit spanning archetypes", limit: 15, want: 15},
+		{name: "limit larger than matches", limit: 100, want: 20},
+	}
+	for _, tc := range testCases {
+		count := 0
+		err = search.EachLimit(worldCtx, tc.limit, func(types.EntityID) bool {
+			count++
+			return true
+		})
+		assert.NilError(t, err, tc.name)
+		assert.Equal(t, count, tc.want, tc.name)
+	}
+
+	// Returning false from the callback still stops the iteration before the limit is reached.
+	count := 0
+	err = search.EachLimit(worldCtx, 10, func(types.EntityID) bool {
+		count++
+		return count < 3
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+
+	count = 0
+	err = cardinal.Not(search).EachLimit(worldCtx, 5, func(types.EntityID) bool {
+		count++
+		return true
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 0)
+}
+
+func TestSearch_EachStopsWithinArchetype(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(worldCtx, 10, AlphaTest{})
+	assert.NilError(t, err)
+
+	// All entities live in a single archetype, so returning false must break out of the inner entity loop.
+	visited := make([]types.EntityID, 0)
+	searches := []cardinal.Searchable{
+		cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())),
+		cardinal.Or(cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))),
+	}
+	for _, search := range searches {
+		visited = visited[:0]
+		err = search.Each(worldCtx, func(id types.EntityID) bool {
+			visited = append(visited, id)
+			return id != ids[3]
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, visited, ids[:4])
+	}
+}
+
+func TestSearch_CollectMatchesCountAndIsSafeToMutate(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(worldCtx, 10, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(worldCtx, 10, AlphaTest{})
+	assert.NilError(t, err)
+
+	search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+	count, err := search.Count(worldCtx)
+	assert.NilError(t, err)
+	ids, err := search.Collect(worldCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(ids), count)
+	assert.True(t, areIDsSorted(ids))
+
+	want := make([]types.EntityID, len(ids))
+	copy(want, ids)
+	for i := range ids {
+		ids[i] = 0
+	}
+	got, err := search.Collect(worldCtx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, want)
+}
+
+func TestSearch_CountFollowsSpawnsAndRemoves(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	withAlpha := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+	onlyAlpha := cardinal.NewSearch().Entity(filter.Exact(filter.Component[AlphaTest]()))
+	assertCounts := func(wantWithAlpha, wantOnlyAlpha int) {
+		t.Helper()
+		count, err := withAlpha.Count(wCtx)
+		assert.NilError(t, err)
+		assert.Equal(t, wantWithAlpha, count)
+		count, err = onlyAlpha.Count(wCtx)
+		assert.NilError(t, err)
+		assert.Equal(t, wantOnlyAlpha, count)
+	}
+
+	ids, err := cardinal.CreateMany(wCtx, 10, AlphaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 5, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+	assertCounts(15, 10)
+
+	assert.NilError(t, cardinal.Remove(wCtx, ids[0]))
+	assert.NilError(t, cardinal.Remove(wCtx, ids[1]))
+	assertCounts(13, 8)
+
+	// Moving an entity to another archetype moves it between the counts of the archetypes.
+	assert.NilError(t, cardinal.AddComponentTo[BetaTest](wCtx, ids[2]))
+	assertCounts(13, 7)
+
+	// The counts survive the tick being committed.
+	tf.DoTick()
+	assertCounts(13, 7)
+	_, err = cardinal.Create(wCtx, AlphaTest{})
+	assert.NilError(t, err)
+	assertCounts(14, 8)
+}
+
+// BenchmarkSearch_Count compares counting the entities of a search by summing the entity counts of the matched
+// archetypes with counting them one by one, which is what a search with a where clause does.
+func BenchmarkSearch_Count(b *testing.B) {
+	tf := cardinal.NewTestFixture(b, nil)
+	world := tf.World
+	assert.NilError(b, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(b, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 50000, AlphaTest{})
+	assert.NilError(b, err)
+	_, err = cardinal.CreateMany(wCtx, 50000, AlphaTest{}, BetaTest{})
+	assert.NilError(b, err)
+
+	searches := map[string]cardinal.Searchable{
+		"archetype counts": cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())),
+		"per entity": cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())).
+			Where(func(cardinal.WorldContext, types.EntityID) (bool, error) { return true, nil }),
+	}
+	for name, search := range searches {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count, err := search.Count(wCtx)
+				assert.NilError(b, err)
+				assert.Equal(b, 100000, count)
+			}
+		})
+	}
+}
+
+func TestSearch_Any(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(world)
+
+	alphas := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+	namedBob := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())).
+		Where(cardinal.ComponentFilter(func(a AlphaTest) bool { return a.Name1 == "bob" }))
+	notBeta := cardinal.Not(cardinal.NewSearch().Entity(filter.Contains(filter.Component[BetaTest]())))
+	assertAny := func(search cardinal.Searchable, want bool) {
+		t.Helper()
+		got, err := search.Any(wCtx)
+		assert.NilError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	assertAny(alphas, false)
+	assertAny(namedBob, false)
+	assertAny(notBeta, false)
+
+	id, err := cardinal.Create(wCtx, AlphaTest{Name1: "alice"})
+	assert.NilError(t, err)
+	assertAny(alphas, true)
+	assertAny(namedBob, false)
+	assertAny(notBeta, true)
+
+	assert.NilError(t, cardinal.SetComponent[AlphaTest](wCtx, id, &AlphaTest{Name1: "bob"}))
+	assertAny(namedBob, true)
+
+	// An archetype that no longer has entities is not a match.
+	assert.NilError(t, cardinal.Remove(wCtx, id))
+	assertAny(alphas, false)
+}
+
+// BenchmarkSearch_AnyVsFirst compares checking whether any entity matches a search with Any and with First.
+func BenchmarkSearch_AnyVsFirst(b *testing.B) {
+	tf := cardinal.NewTestFixture(b, nil)
+	world := tf.World
+	assert.NilError(b, cardinal.RegisterComponent[AlphaTest](world))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 10000, AlphaTest{})
+	assert.NilError(b, err)
+	search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+
+	b.Run("any", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if found, err := search.Any(wCtx); err != nil || !found {
+				b.Fatal("expected a match", err)
+			}
+		}
+	})
+	b.Run("first", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := search.First(wCtx); err != nil {
+				b.Fatal("expected a match", err)
+			}
+		}
+	})
+}
+
+// unkeyedFilter wraps a filter so that it has no filter key, and searches with it use their own archetype cache.
+type unkeyedFilter struct {
+	filter.ComponentFilter
+}
+
+func TestEachAcrossVisitsTheEntitiesOfEveryWorld(t *testing.T) {
+	newWorld := func(create func(wCtx cardinal.WorldContext)) *cardinal.World {
+		tf := cardinal.NewTestFixture(t, nil)
+		assert.NilError(t, cardinal.RegisterComponent[AlphaTest](tf.World))
+		assert.NilError(t, cardinal.RegisterComponent[BetaTest](tf.World))
+		tf.StartWorld()
+		create(cardinal.NewWorldContext(tf.World))
+		tf.DoTick()
+		return tf.World
+	}
+	// The archetypes are created in a different order in each world, so the same archetype ID stands for different
+	// component sets in the two worlds.
+	first := newWorld(func(wCtx cardinal.WorldContext) {
+		_, err := cardinal.CreateMany(wCtx, 2, AlphaTest{Name1: "first"})
+		assert.NilError(t, err)
+		_, err = cardinal.CreateMany(wCtx, 4, BetaTest{})
+		assert.NilError(t, err)
+	})
+	second := newWorld(func(wCtx cardinal.WorldContext) {
+		_, err := cardinal.CreateMany(wCtx, 5, BetaTest{})
+		assert.NilError(t, err)
+		_, err = cardinal.CreateMany(wCtx, 3, AlphaTest{Name1: "second"})
+		assert.NilError(t, err)
+	})
+	worlds := []*cardinal.World{first, second}
+
+	searches := map[string]cardinal.Searchable{
+		"shared cache": cardinal.NewSearch().Entity(filter.Exact(filter.Component[AlphaTest]())),
+		"search cache": cardinal.NewSearch().Entity(unkeyedFilter{filter.Exact(filter.Component[AlphaTest]())}),
+	}
+	for name, search := range searches {
+		t.Run(name, func(t *testing.T) {
+			// The search is run twice so that the second run uses the archetypes cached for each world.
+			for i := 0; i < 2; i++ {
+				got := map[*cardinal.World][]string{}
+				err := cardinal.EachAcross(worlds, search,
+					func(w *cardinal.World, wCtx cardinal.WorldContext, id types.EntityID) bool {
+						alpha, err := cardinal.GetComponent[AlphaTest](wCtx, id)
+						assert.NilError(t, err)
+						got[w] = append(got[w], alpha.Name1)
+						return true
+					})
+				assert.NilError(t, err)
+				assert.DeepEqual(t, []string{"first", "first"}, got[first])
+				assert.DeepEqual(t, []string{"second", "second", "second"}, got[second])
+			}
+		})
+	}
+
+	// Stopping the iteration also skips the remaining worlds.
+	visited := 0
+	err := cardinal.EachAcross(worlds, searches["shared cache"],
+		func(*cardinal.World, cardinal.WorldContext, types.EntityID) bool {
+			visited++
+			return false
+		})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, visited)
+}
+
+func TestEachComponent(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(worldCtx, 3, AlphaTest{})
+	assert.NilError(t, err)
+	for i, id := range ids {
+		assert.NilError(t, cardinal.SetComponent[AlphaTest](worldCtx, id, &AlphaTest{Name1: fmt.Sprint(i)}))
+	}
+	_, err = cardinal.CreateMany(worldCtx, 2, BetaTest{})
+	assert.NilError(t, err)
+
+	got := map[types.EntityID]string{}
+	err = cardinal.EachComponent[AlphaTest](worldCtx,
+		cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())),
+		func(id types.EntityID, alpha *AlphaTest) bool {
+			got[id] = alpha.Name1
+			return true
+		})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, got, map[types.EntityID]string{ids[0]: "0", ids[1]: "1", ids[2]: "2"})
+
+	// The filter matches entities that do not have the requested component.
+	err = cardinal.EachComponent[AlphaTest](worldCtx,
+		cardinal.NewSearch().Entity(filter.All()),
+		func(types.EntityID, *AlphaTest) bool {
+			return true
+		})
+	assert.ErrorIs(t, err, cardinal.ErrComponentNotOnEntity)
+}
+
+func TestSearch_EachReverse(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	alphaIDs, err := cardinal.CreateMany(worldCtx, 3, AlphaTest{})
+	assert.NilError(t, err)
+	alphaBetaIDs, err := cardinal.CreateMany(worldCtx, 2, AlphaTest{}, BetaTest{})
+	assert.NilError(t, err)
+
+	search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+
+	var forward []types.EntityID
+	err = search.Each(worldCtx, func(id types.EntityID) bool {
+		forward = append(forward, id)
+		return true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, forward, []types.EntityID{
+		alphaIDs[0], alphaIDs[1], alphaIDs[2], alphaBetaIDs[0], alphaBetaIDs[1],
+	})
+
+	var reverse []types.EntityID
+	err = search.EachReverse(worldCtx, func(id types.EntityID) bool {
+		reverse = append(reverse, id)
+		return true
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reverse, []types.EntityID{
+		alphaBetaIDs[1], alphaBetaIDs[0], alphaIDs[2], alphaIDs[1], alphaIDs[0],
+	})
+
+	// Returning false stops the reverse iteration early.
+	reverse = reverse[:0]
+	err = search.EachReverse(worldCtx, func(id types.EntityID) bool {
+		reverse = append(reverse, id)
+		return len(reverse) < 3
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, reverse, []types.EntityID{alphaBetaIDs[1], alphaBetaIDs[0], alphaIDs[2]})
+}
+
+func TestEachReadOnlyRunsConcurrently(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	tf.StartWorld()
+
+	_, err := cardinal.CreateMany(cardinal.NewWorldContext(world), 10, AlphaTest{Name1: "alpha"})
+	assert.NilError(t, err)
+	// Read-only iterations see committed state.
+	tf.DoTick()
+
+	// Both iterations wait inside their callback until the other one has started, which only works if they can hold
+	// the read lock at the same time.
+	const numOfQueries = 2
+	started := sync.WaitGroup{}
+	started.Add(numOfQueries)
+	errs := make(chan error, numOfQueries)
+	counts := make(chan int, numOfQueries)
+	for i := 0; i < numOfQueries; i++ {
+		go func() {
+			count := 0
+			search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+			errs <- cardinal.EachReadOnly(world, search, func(wCtx cardinal.WorldContext, id types.EntityID) bool {
+				if count == 0 {
+					started.Done()
+					started.Wait()
+				}
+				alpha, err := cardinal.GetComponent[AlphaTest](wCtx, id)
+				if err != nil || alpha.Name1 != "alpha" {
+					return false
+				}
+				count++
+				return true
+			})
+			counts <- count
+		}()
+	}
+
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < numOfQueries; i++ {
+		select {
+		case err := <-errs:
+			assert.NilError(t, err)
+			assert.Equal(t, <-counts, 10)
+		case <-timeout:
+			t.Fatal("read-only iterations did not run concurrently")
+		}
+	}
+}
+
+func TestReadOnlySearchesDoNotSeeTheArchetypesPendingForOtherSearches(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	tf.StartWorld()
+
+	// The archetype of the entity is pending until the next tick commits it.
+	_, err := cardinal.Create(cardinal.NewWorldContext(world), AlphaTest{})
+	assert.NilError(t, err)
+	count, err := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]())).
+		Count(cardinal.NewWorldContext(world))
+	assert.NilError(t, err)
+	assert.Equal(t, count, 1)
+
+	visit := func() int {
+		visited := 0
+		search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[AlphaTest]()))
+		assert.NilError(t, cardinal.EachReadOnly(world, search, func(cardinal.WorldContext, types.EntityID) bool {
+			visited++
+			return true
+		}))
+		return visited
+	}
+	assert.Equal(t, visit(), 0)
+
+	tf.DoTick()
+	assert.Equal(t, visit(), 1)
+}
+
+type Score struct {
+	Points int
+}
+
+func (Score) Name() string { return "score" }
+
+func TestSortedByReturnsEntitiesInDescendingScoreOrder(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Score](world))
+	tf.StartWorld()
+
+	worldCtx := cardinal.NewWorldContext(world)
+	points := []int{20, 50, 10, 50, 30}
+	ids := make([]types.EntityID, 0, len(points))
+	for _, p := range points {
+		id, err := cardinal.Create(worldCtx, Score{Points: p})
+		assert.NilError(t, err)
+		ids = append(ids, id)
+	}
+
+	entries, err := cardinal.SortedBy[Score](worldCtx,
+		cardinal.NewSearch().Entity(filter.Exact(filter.Component[Score]())),
+		func(a, b Score) bool { return a.Points > b.Points })
+	assert.NilError(t, err)
+
+	gotIDs := make([]types.EntityID, 0, len(entries))
+	gotPoints := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		gotIDs = append(gotIDs, entry.ID)
+		gotPoints = append(gotPoints, entry.Component.Points)
+	}
+	assert.DeepEqual(t, gotPoints, []int{50, 50, 30, 20, 10})
+	// Entities with the same score are ordered by their ids.
+	assert.DeepEqual(t, gotIDs, []types.EntityID{ids[1], ids[3], ids[4], ids[0], ids[2]})
+}
+
+type Particle struct {
+	X, V float64
+	Hits int
+}
+
+func (Particle) Name() string { return "particle" }
+
+func TestEachParallelVisitsEveryEntityOnce(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Particle](tf.World))
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](tf.World))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(tf.World)
+	ids, err := cardinal.CreateMany(wCtx, 97, Particle{V: 2})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 5, AlphaTest{})
+	assert.NilError(t, err)
+
+	var mu sync.Mutex
+	visits := map[types.EntityID]int{}
+	search := cardinal.NewSearch().Entity(filter.Or(
+		filter.Contains(filter.Component[Particle]()),
+		filter.Contains(filter.Component[AlphaTest]()),
+	))
+	err = cardinal.EachParallel[Particle](wCtx, search, 4, func(id types.EntityID, p *Particle) {
+		mu.Lock()
+		visits[id]++
+		mu.Unlock()
+		p.X += p.V
+		p.Hits++
+	})
+	assert.NilError(t, err)
+
+	// Entities without the component are skipped.
+	assert.Equal(t, len(visits), len(ids))
+	for _, id := range ids {
+		assert.Equal(t, visits[id], 1)
+		p, err := cardinal.GetComponent[Particle](wCtx, id)
+		assert.NilError(t, err)
+		assert.Equal(t, *p, Particle{X: 2, V: 2, Hits: 1})
+	}
+}
+
+func TestEachParallelReturnsACallbackPanicAsAnError(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Particle](tf.World))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(tf.World)
+	ids, err := cardinal.CreateMany(wCtx, 10, Particle{V: 2})
+	assert.NilError(t, err)
+
+	search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Particle]()))
+	err = cardinal.EachParallel[Particle](wCtx, search, 4, func(id types.EntityID, p *Particle) {
+		p.Hits++
+		if id == ids[7] {
+			panic("out of bounds")
+		}
+	})
+	assert.ErrorContains(t, err, fmt.Sprintf("EachParallel callback panicked on entity %d: out of bounds", ids[7]))
+
+	// No component is written back once a callback panicked.
+	for _, id := range ids {
+		p, err := cardinal.GetComponent[Particle](wCtx, id)
+		assert.NilError(t, err)
+		assert.Equal(t, p.Hits, 0)
+	}
+}
+
+// BenchmarkEachParallel compares a CPU-bound callback run on a single worker and on four workers.
+func BenchmarkEachParallel(b *testing.B) {
+	tf := cardinal.NewTestFixture(b, nil)
+	assert.NilError(b, cardinal.RegisterComponent[Particle](tf.World))
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(tf.World)
+	_, err := cardinal.CreateMany(wCtx, 1000, Particle{V: 1})
+	assert.NilError(b, err)
+	search := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Particle]()))
+	integrate := func(_ types.EntityID, p *Particle) {
+		for i := 0; i < 2000; i++ {
+			p.X = math.Sqrt(p.X*p.X + p.V)
+		}
+	}
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := cardinal.EachParallel[Particle](wCtx, search, workers, integrate); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestStableIterationVisitsEntitiesByID(t *testing.T) {
+	collect := func(opts ...cardinal.WorldOption) (each, reverse []types.EntityID, first, last types.EntityID) {
+		tf := cardinal.NewTestFixture(t, nil, opts...)
+		assert.NilError(t, cardinal.RegisterComponent[AlphaTest](tf.World))
+		assert.NilError(t, cardinal.RegisterComponent[BetaTest](tf.World))
+		tf.StartWorld()
+		wCtx := cardinal.NewWorldContext(tf.World)
+		_, err := cardinal.CreateMany(wCtx, 5, AlphaTest{})
+		assert.NilError(t, err)
+		_, err = cardinal.CreateMany(wCtx, 5, BetaTest{})
+		assert.NilError(t, err)
+		_, err = cardinal.CreateMany(wCtx, 5, AlphaTest{})
+		assert.NilError(t, err)
+		tf.DoTick()
+		// Removing entities from the middle of an archetype moves its last entities into their slots.
+		for _, id := range []types.EntityID{1, 6, 2} {
+			assert.NilError(t, cardinal.Remove(wCtx, id))
+		}
+		tf.DoTick()
+
+		search := cardinal.NewSearch().Entity(filter.Or(
+			filter.Contains(filter.Component[AlphaTest]()),
+			filter.Contains(filter.Component[BetaTest]()),
+		))
+		assert.NilError(t, search.Each(wCtx, func(id types.EntityID) bool {
+			each = append(each, id)
+			return true
+		}))
+		assert.NilError(t, search.EachReverse(wCtx, func(id types.EntityID) bool {
+			reverse = append(reverse, id)
+			return true
+		}))
+		first, err = search.First(wCtx)
+		assert.NilError(t, err)
+		last, err = search.Last(wCtx)
+		assert.NilError(t, err)
+		return each, reverse, first, last
+	}
+
+	unstable, _, _, _ := collect()
+	assert.Check(t, !slices.IsSorted(unstable))
+
+	each, reverse, first, last := collect(cardinal.WithStableIteration())
+	want := []types.EntityID{0, 3, 4, 5, 7, 8, 9, 10, 11, 12, 13, 14}
+	assert.DeepEqual(t, each, want)
+	slices.Reverse(want)
+	assert.DeepEqual(t, reverse, want)
+	assert.Equal(t, first, types.EntityID(0))
+	assert.Equal(t, last, types.EntityID(14))
+}
+
+func TestComponentsByIDReportsTheUnknownEntities(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Score](world))
+	assert.NilError(t, cardinal.RegisterComponent[HP](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 3, Score{Points: 7})
+	assert.NilError(t, err)
+	removed, err := cardinal.Create(wCtx, Score{Points: 1})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.Remove(wCtx, removed))
+	withoutScore, err := cardinal.Create(wCtx, HP{})
+	assert.NilError(t, err)
+	tf.DoTick()
+
+	entries, err := cardinal.ComponentsByID[Score](wCtx, []types.EntityID{ids[2], ids[0]})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, entries, []cardinal.ComponentEntry[Score]{
+		{ID: ids[2], Component: Score{Points: 7}},
+		{ID: ids[0], Component: Score{Points: 7}},
+	})
+
+	unknown := types.EntityID(1000)
+	_, err = cardinal.ComponentsByID[Score](wCtx, []types.EntityID{ids[0], removed, ids[1], unknown, withoutScore})
+	assert.ErrorIs(t, err, cardinal.ErrEntityDoesNotExist)
+	var notFound *cardinal.EntitiesNotFoundError
+	assert.Check(t, errors.As(err, &notFound))
+	assert.DeepEqual(t, notFound.IDs, []types.EntityID{removed, unknown, withoutScore})
+}
+
+func TestEachArchetypeSumsAComponentAcrossArchetypes(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Score](world))
+	assert.NilError(t, cardinal.RegisterComponent[AlphaTest](world))
+	assert.NilError(t, cardinal.RegisterComponent[BetaTest](world))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	_, err := cardinal.CreateMany(wCtx, 3, Score{Points: 10})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 2, Score{Points: 7}, AlphaTest{})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(wCtx, 4, AlphaTest{})
+	assert.NilError(t, err)
+	// An archetype that matches the filter but has no entities left is skipped.
+	emptied, err := cardinal.Create(wCtx, Score{Points: 1000}, BetaTest{})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.Remove(wCtx, emptied))
+	tf.DoTick()
+
+	total, archetypes, entities := 0, 0, 0
+	err = cardinal.EachArchetype(wCtx, filter.Contains(filter.Component[Score]()), func(arch cardinal.Archetype) bool {
+		archetypes++
+		entities += len(arch.Entities)
+		for _, id := range arch.Entities {
+			score, err := cardinal.GetComponent[Score](wCtx, id)
+			assert.NilError(t, err)
+			total += score.Points
+		}
+		return true
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, archetypes, 2)
+	assert.Equal(t, entities, 5)
+	assert.Equal(t, total, 3*10+2*7)
+}
@@ -457,6 +457,12 @@ const docTemplate = `{
                     }
                 },
                 "result": {},
+                "stackTraces": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "tick": {
                     "type": "integer"
                 },
@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/gofiber/fiber/v2"
 
+	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/server/types"
 )
 
@@ -20,12 +21,14 @@ type ListTxReceiptsResponse struct {
 	Receipts  []ReceiptEntry `json:"receipts"`
 }
 
-// ReceiptEntry represents a single transaction receipt. It contains an ID, a result, and a list of errors.
+// ReceiptEntry represents a single transaction receipt. It contains an ID, a result, and a list of errors, along with
+// the stack trace of each error.
 type ReceiptEntry struct {
-	TxHash string   `json:"txHash"`
-	Tick   uint64   `json:"tick"`
-	Result any      `json:"result"`
-	Errors []string `json:"errors"`
+	TxHash      string   `json:"txHash"`
+	Tick        uint64   `json:"tick"`
+	Result      any      `json:"result"`
+	Errors      []string `json:"errors"`
+	StackTraces []string `json:"stackTraces,omitempty"`
 }
 
 // GetReceipts godoc
@@ -68,10 +71,11 @@ func GetReceipts(world types.ProviderWorld) func(*fiber.Ctx) error {
 			}
 			for _, r := range currReceipts {
 				reply.Receipts = append(reply.Receipts, ReceiptEntry{
-					TxHash: string(r.TxHash),
-					Tick:   t,
-					Result: r.Result,
-					Errors: convertErrorsToStrings(r.Errs),
+					TxHash:      string(r.TxHash),
+					Tick:        t,
+					Result:      r.Result,
+					Errors:      convertErrorsToStrings(r.Errs),
+					StackTraces: receipt.ErrorStackTraces(r.Errs),
 				})
 			}
 		}
@@ -8,6 +8,7 @@ import (
 	personaMsg "pkg.world.dev/world-engine/cardinal/persona/msg"
 	servertypes "pkg.world.dev/world-engine/cardinal/server/types"
 	"pkg.world.dev/world-engine/cardinal/server/validator"
+	"pkg.world.dev/world-engine/cardinal/txpool"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
@@ -77,7 +78,23 @@ func PostTransaction(
 
 		// Add the transaction to the engine
 		// TODO(scott): this should just deal with txpool instead of having to go through engine
-		tick, hash := world.AddTransaction(msgType.ID(), msg, tx)
+		tick, hash, err := world.AddTransaction(msgType.ID(), msg, tx)
+		if err != nil {
+			log.Error(err)
+			if eris.Is(err, types.ErrNonceNotIncreasing) {
+				return fiber.NewError(fiber.StatusForbidden, "Forbidden - nonce already used")
+			}
+			if eris.Is(err, txpool.ErrPoolFull) {
+				return fiber.NewError(fiber.StatusServiceUnavailable, "Service Unavailable - message queue is full")
+			}
+			if eris.Is(err, types.ErrSignatureRejected) {
+				return fiber.NewError(fiber.StatusUnauthorized, "Unauthorized - signature was rejected")
+			}
+			if eris.Is(err, txpool.ErrPoolClosed) {
+				return fiber.NewError(fiber.StatusServiceUnavailable, "Service Unavailable - shutting down")
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, "Internal Server Error - failed to queue message")
+		}
 
 		return ctx.JSON(&PostTransactionResponse{
 			TxHash: string(hash),
@@ -31,9 +31,11 @@ func (s *ServerTestSuite) TestReceiptsQuery() {
 
 	fooMsg, ok := world.GetMessageByFullName("game." + msgName)
 	s.Require().True(ok)
-	_, txHash1 := world.AddTransaction(fooMsg.ID(), fooIn{}, &sign.Transaction{PersonaTag: "alpha"})
+	_, txHash1, err := world.AddTransaction(fooMsg.ID(), fooIn{}, &sign.Transaction{PersonaTag: "alpha"})
+	s.Require().NoError(err)
 	s.fixture.DoTick()
-	_, txHash2 := world.AddTransaction(fooMsg.ID(), fooIn{}, &sign.Transaction{PersonaTag: "beta"})
+	_, txHash2, err := world.AddTransaction(fooMsg.ID(), fooIn{}, &sign.Transaction{PersonaTag: "beta"})
+	s.Require().NoError(err)
 	s.fixture.DoTick()
 
 	s.Require().NotEqual(txHash1, txHash2)
@@ -61,6 +63,8 @@ func (s *ServerTestSuite) TestReceiptsQuery() {
 		Tick:   1,
 		Result: nil,
 		Errors: []string{wantErrorMessage},
+		// The stack traces are checked separately below, as they depend on where the test is run from.
+		StackTraces: reply.Receipts[1].StackTraces,
 	}
 	expectedJSON2, err := json.Marshal(expectedReceipt2)
 	s.Require().NoError(err)
@@ -73,6 +77,9 @@ func (s *ServerTestSuite) TestReceiptsQuery() {
 
 	// Make sure the text of the error message actually ends up in the JSON
 	s.Require().Contains(string(json2), wantErrorMessage)
+	s.Require().Len(reply.Receipts[1].StackTraces, 1)
+	s.Require().Contains(reply.Receipts[1].StackTraces[0], wantErrorMessage)
+	s.Require().Contains(reply.Receipts[1].StackTraces[0], "message.go")
 
 	s.Require().Equal(string(expectedJSON1), string(json1))
 	s.Require().Equal(string(expectedJSON2), string(json2))
@@ -1,6 +1,7 @@
 package server_test
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
@@ -71,7 +72,7 @@ func (s *ServerTestSuite) SetupTest() {
 
 // TearDownTest runs after each test in the suite.
 func (s *ServerTestSuite) TearDownTest() {
-	s.fixture.World.Shutdown()
+	s.Require().NoError(s.fixture.World.Shutdown(context.Background()))
 }
 
 // TestCanClaimPersonaSendGameTxAndQueryGame tests that you can claim a persona, send a tx, and then query.
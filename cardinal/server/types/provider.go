@@ -12,7 +12,7 @@ type ProviderWorld interface {
 	validator.SignerAddressProvider
 	UseNonce(signerAddress string, nonce uint64) error
 	GetSignerForPersonaTag(personaTag string, tick uint64) (addr string, err error)
-	AddTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash)
+	AddTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash, error)
 	Namespace() string
 	GetComponentByName(name string) (types.ComponentMetadata, error)
 	StoreReader() gamestate.Reader
@@ -0,0 +1,48 @@
+package cardinal
+
+import (
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// ErrSignatureRejected is returned when the signature verifier (see WithSignatureVerifier) does not accept the
+// signature of a submitted transaction.
+var ErrSignatureRejected = types.ErrSignatureRejected
+
+// SignedTx is the signed part of a submitted transaction, as passed to a SignatureVerifier.
+type SignedTx struct {
+	PersonaTag string
+	Namespace  string
+	// Timestamp is the UNIX timestamp of the transaction in milliseconds.
+	Timestamp int64
+	// Body is the encoded message of the transaction.
+	Body []byte
+	// Signature is the hex encoded signature of the transaction.
+	Signature string
+	Hash      types.TxHash
+}
+
+// SignatureVerifier checks the signature of a submitted transaction, returning an error if it is not accepted.
+type SignatureVerifier func(tx SignedTx) error
+
+// verifySignature passes the signed part of the given transaction to the signature verifier, if one is set. An error
+// that wraps both ErrSignatureRejected and the verifier's error is returned if the verifier does not accept it.
+func (w *World) verifySignature(sig *sign.Transaction) error {
+	if w.signatureVerifier == nil {
+		return nil
+	}
+	err := w.signatureVerifier(SignedTx{
+		PersonaTag: sig.PersonaTag,
+		Namespace:  sig.Namespace,
+		Timestamp:  sig.Timestamp,
+		Body:       sig.Body,
+		Signature:  sig.Signature,
+		Hash:       types.TxHash(sig.HashHex()),
+	})
+	if err != nil {
+		return eris.Wrap(err, ErrSignatureRejected.Error())
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+package cardinal
+
+import (
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// Positioned is implemented by components that have a position on a 2D plane. Such components can be indexed with
+// RegisterSpatialIndex.
+type Positioned interface {
+	types.Component
+	Position() (x, y float64)
+}
+
+// Point is a position on a 2D plane.
+type Point struct {
+	X, Y float64
+}
+
+type cell struct {
+	x, y int64
+}
+
+// SpatialIndex is a uniform grid index over the entities that have a given Positioned component. It is kept up to
+// date as the component is set on and removed from entities, so it can be queried from systems and queries without
+// searching through every entity.
+//
+// The index only holds committed positions: changes made during a tick are applied when the tick is committed, and
+// are dropped if the tick fails. Systems therefore see the positions as of the end of the previous tick.
+type SpatialIndex struct {
+	cellSize float64
+	position func(comp any) (Point, bool)
+	rebuild  func(*World) error
+
+	mu        sync.RWMutex
+	cells     map[cell]map[types.EntityID]struct{}
+	positions map[types.EntityID]Point
+	// pending holds the positions set since the last commit, or nil for the entities that were removed from the index.
+	pending map[types.EntityID]*Point
+	// undo is nil unless a savepoint is taken. It holds the pending changes entities had at the savepoint, the first
+	// time they are changed after it.
+	undo map[types.EntityID]pendingPosition
+}
+
+type pendingPosition struct {
+	position *Point
+	pending  bool
+}
+
+// RegisterSpatialIndex registers a spatial index over the entities that have the component T. Positions are bucketed
+// into square cells of the given size, so queries only look at entities in the cells that overlap the queried area.
+// The component must already be registered, and the index must be registered before the world starts.
+func RegisterSpatialIndex[T Positioned](w *World, cellSize float64) (*SpatialIndex, error) {
+	var t T
+	if w.worldStage.Current() != worldstage.Init {
+		return nil, eris.Errorf(
+			"failed to register spatial index for %q: world state is %s, expected %s",
+			t.Name(), w.worldStage.Current(), worldstage.Init,
+		)
+	}
+	if cellSize <= 0 || math.IsInf(cellSize, 0) || math.IsNaN(cellSize) {
+		return nil, eris.Errorf("failed to register spatial index for %q: cell size must be positive", t.Name())
+	}
+	if _, err := w.GetComponentByName(t.Name()); err != nil {
+		return nil, eris.Wrapf(err, "failed to register spatial index for %q", t.Name())
+	}
+	if _, ok := w.spatialIndexes[t.Name()]; ok {
+		return nil, eris.Errorf("spatial index for %q is already registered", t.Name())
+	}
+
+	idx := &SpatialIndex{
+		cellSize:  cellSize,
+		position:  positionOf[T],
+		rebuild:   nil,
+		mu:        sync.RWMutex{},
+		cells:     map[cell]map[types.EntityID]struct{}{},
+		positions: map[types.EntityID]Point{},
+		pending:   map[types.EntityID]*Point{},
+		undo:      nil,
+	}
+	idx.rebuild = func(w *World) error {
+		idx.reset()
+		wCtx := NewReadOnlyWorldContext(w)
+		err := NewSearch().Entity(filter.Contains(filter.Component[T]())).Each(wCtx, func(id types.EntityID) bool {
+			comp, err := GetComponent[T](wCtx, id)
+			if err == nil && comp != nil {
+				idx.set(id, *comp)
+			}
+			return true
+		})
+		if err != nil {
+			idx.discard()
+			return err
+		}
+		idx.commit()
+		return nil
+	}
+	w.spatialIndexes[t.Name()] = idx
+	return idx, nil
+}
+
+// QueryRadius returns the IDs of the entities whose position is within radius of center, including those exactly
+// radius away. The IDs are sorted in ascending order.
+func (s *SpatialIndex) QueryRadius(center Point, radius float64) []types.EntityID {
+	if radius < 0 {
+		return []types.EntityID{}
+	}
+	return s.query(
+		Point{X: center.X - radius, Y: center.Y - radius},
+		Point{X: center.X + radius, Y: center.Y + radius},
+		func(p Point) bool {
+			dx, dy := p.X-center.X, p.Y-center.Y
+			return dx*dx+dy*dy <= radius*radius
+		},
+	)
+}
+
+// QueryRect returns the IDs of the entities whose position is within the rectangle spanned by minCorner and
+// maxCorner, including its edges. The IDs are sorted in ascending order.
+func (s *SpatialIndex) QueryRect(minCorner, maxCorner Point) []types.EntityID {
+	if minCorner.X > maxCorner.X || minCorner.Y > maxCorner.Y {
+		return []types.EntityID{}
+	}
+	return s.query(minCorner, maxCorner, func(p Point) bool {
+		return p.X >= minCorner.X && p.X <= maxCorner.X && p.Y >= minCorner.Y && p.Y <= maxCorner.Y
+	})
+}
+
+// query returns the sorted IDs of the entities in the cells overlapping the given bounds that match the given
+// predicate.
+func (s *SpatialIndex) query(minCorner, maxCorner Point, match func(Point) bool) []types.EntityID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lo, hi := s.cellOf(minCorner), s.cellOf(maxCorner)
+	ids := []types.EntityID{}
+	for x := lo.x; x <= hi.x; x++ {
+		for y := lo.y; y <= hi.y; y++ {
+			for id := range s.cells[cell{x: x, y: y}] {
+				if match(s.positions[id]) {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (s *SpatialIndex) cellOf(p Point) cell {
+	return cell{
+		x: int64(math.Floor(p.X / s.cellSize)),
+		y: int64(math.Floor(p.Y / s.cellSize)),
+	}
+}
+
+func (s *SpatialIndex) set(id types.EntityID, comp any) {
+	p, ok := s.position(comp)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordLocked(id)
+	s.pending[id] = &p
+}
+
+func (s *SpatialIndex) remove(id types.EntityID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordLocked(id)
+	s.pending[id] = nil
+}
+
+// recordLocked records the pending change of the entity at the savepoint, if a savepoint is taken and the entity has
+// not been changed since.
+func (s *SpatialIndex) recordLocked(id types.EntityID) {
+	if s.undo == nil {
+		return
+	}
+	if _, ok := s.undo[id]; ok {
+		return
+	}
+	p, ok := s.pending[id]
+	s.undo[id] = pendingPosition{position: p, pending: ok}
+}
+
+// savepoint starts recording the pending changes, so that they can be undone with rollbackToSavepoint.
+func (s *SpatialIndex) savepoint() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.undo = map[types.EntityID]pendingPosition{}
+}
+
+// rollbackToSavepoint undoes the pending changes made since the savepoint was taken.
+func (s *SpatialIndex) rollbackToSavepoint() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, p := range s.undo {
+		if p.pending {
+			s.pending[id] = p.position
+		} else {
+			delete(s.pending, id)
+		}
+	}
+	s.undo = nil
+}
+
+func (s *SpatialIndex) releaseSavepoint() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.undo = nil
+}
+
+// commit applies the pending changes to the index, so that they show up in queries.
+func (s *SpatialIndex) commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, p := range s.pending {
+		s.removeLocked(id)
+		if p == nil {
+			continue
+		}
+		c := s.cellOf(*p)
+		if s.cells[c] == nil {
+			s.cells[c] = map[types.EntityID]struct{}{}
+		}
+		s.cells[c][id] = struct{}{}
+		s.positions[id] = *p
+	}
+	s.pending = map[types.EntityID]*Point{}
+	s.undo = nil
+}
+
+// discard drops the pending changes, leaving the index as it was after the last commit.
+func (s *SpatialIndex) discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = map[types.EntityID]*Point{}
+	s.undo = nil
+}
+
+func (s *SpatialIndex) removeLocked(id types.EntityID) {
+	p, ok := s.positions[id]
+	if !ok {
+		return
+	}
+	c := s.cellOf(p)
+	delete(s.cells[c], id)
+	if len(s.cells[c]) == 0 {
+		delete(s.cells, c)
+	}
+	delete(s.positions, id)
+}
+
+func (s *SpatialIndex) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cells = map[cell]map[types.EntityID]struct{}{}
+	s.positions = map[types.EntityID]Point{}
+	s.pending = map[types.EntityID]*Point{}
+}
+
+// positionOf returns the position of a component that is stored either as a T or a *T.
+func positionOf[T Positioned](comp any) (Point, bool) {
+	var x, y float64
+	switch c := comp.(type) {
+	case T:
+		x, y = c.Position()
+	case *T:
+		if c == nil {
+			return Point{}, false
+		}
+		x, y = (*c).Position()
+	default:
+		return Point{}, false
+	}
+	return Point{X: x, Y: y}, true
+}
+
+// spatialIndexes are the spatial indexes registered on a world, keyed by the name of the indexed component.
+type spatialIndexes map[string]*SpatialIndex
+
+func (s spatialIndexes) componentSet(name string, id types.EntityID, comp any) {
+	if idx, ok := s[name]; ok {
+		idx.set(id, comp)
+	}
+}
+
+func (s spatialIndexes) componentRemoved(name string, id types.EntityID) {
+	if idx, ok := s[name]; ok {
+		idx.remove(id)
+	}
+}
+
+func (s spatialIndexes) entityRemoved(id types.EntityID) {
+	for _, idx := range s {
+		idx.remove(id)
+	}
+}
+
+// commit applies the pending changes of every index. It is called once the changes of a tick have been committed to
+// the state.
+func (s spatialIndexes) commit() {
+	for _, idx := range s {
+		idx.commit()
+	}
+}
+
+// discard drops the pending changes of every index. It is called when the changes of a tick are not committed.
+func (s spatialIndexes) discard() {
+	for _, idx := range s {
+		idx.discard()
+	}
+}
+
+func (s spatialIndexes) savepoint() {
+	for _, idx := range s {
+		idx.savepoint()
+	}
+}
+
+func (s spatialIndexes) rollbackToSavepoint() {
+	for _, idx := range s {
+		idx.rollbackToSavepoint()
+	}
+}
+
+func (s spatialIndexes) releaseSavepoint() {
+	for _, idx := range s {
+		idx.releaseSavepoint()
+	}
+}
+
+// rebuild repopulates every index from the entities in the world's state.
+func (s spatialIndexes) rebuild(w *World) error {
+	for name, idx := range s {
+		if err := idx.rebuild(w); err != nil {
+			return eris.Wrapf(err, "failed to rebuild spatial index for %q", name)
+		}
+	}
+	return nil
+}
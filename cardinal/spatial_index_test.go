@@ -0,0 +1,149 @@
+package cardinal_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type Coords struct {
+	X, Y float64
+}
+
+func (Coords) Name() string {
+	return "coords"
+}
+
+func (l Coords) Position() (float64, float64) {
+	return l.X, l.Y
+}
+
+func newSpatialIndexTestFixture(t *testing.T) (*cardinal.TestFixture, *cardinal.SpatialIndex) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Coords](tf.World))
+	idx, err := cardinal.RegisterSpatialIndex[Coords](tf.World, 10)
+	assert.NilError(t, err)
+	return tf, idx
+}
+
+func TestSpatialIndexRadiusQueryOnCellBoundaries(t *testing.T) {
+	tf, idx := newSpatialIndexTestFixture(t)
+	tf.StartWorld()
+	wCtx := cardinal.NewWorldContext(tf.World)
+
+	create := func(x, y float64) types.EntityID {
+		id, err := cardinal.Create(wCtx, Coords{X: x, Y: y})
+		assert.NilError(t, err)
+		return id
+	}
+	// Entities exactly on the boundaries between cells, both on and just outside of the queried radius.
+	east := create(10, 0)
+	north := create(0, 10)
+	west := create(-10, 0)
+	south := create(0, -10)
+	origin := create(0, 0)
+	justOutside := create(10.001, 0)
+	corner := create(10, 10)
+	// Entities created outside of a tick show up in the index once the next tick is committed.
+	tf.DoTick()
+
+	testCases := []struct {
+		name   string
+		center cardinal.Point
+		radius float64
+		want   []types.EntityID
+	}{
+		{
+			name:   "points exactly radius away are included",
+			center: cardinal.Point{X: 0, Y: 0},
+			radius: 10,
+			want:   []types.EntityID{east, north, west, south, origin},
+		},
+		{
+			name:   "radius ending on a cell boundary",
+			center: cardinal.Point{X: 5, Y: 0},
+			radius: 5,
+			want:   []types.EntityID{east, origin},
+		},
+		{
+			name:   "center on a cell corner",
+			center: cardinal.Point{X: 10, Y: 10},
+			radius: 10,
+			want:   []types.EntityID{east, north, corner},
+		},
+		{
+			name:   "zero radius only matches the exact point",
+			center: cardinal.Point{X: 10.001, Y: 0},
+			radius: 0,
+			want:   []types.EntityID{justOutside},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, tc.want, idx.QueryRadius(tc.center, tc.radius))
+		})
+	}
+
+	assert.DeepEqual(t, []types.EntityID{east, justOutside, corner},
+		idx.QueryRect(cardinal.Point{X: 10, Y: 0}, cardinal.Point{X: 20, Y: 10}))
+}
+
+func TestSpatialIndexFollowsEntitiesAsTheyMove(t *testing.T) {
+	tf, idx := newSpatialIndexTestFixture(t)
+
+	var id types.EntityID
+	step := 0
+	err := cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+		var err error
+		switch step {
+		case 0:
+			id, err = cardinal.Create(wCtx, Coords{X: 1, Y: 1})
+		case 1:
+			err = cardinal.UpdateComponent[Coords](wCtx, id, func(l *Coords) *Coords {
+				l.X = 25
+				return l
+			})
+		case 2:
+			err = cardinal.Remove(wCtx, id)
+		}
+		step++
+		return err
+	})
+	assert.NilError(t, err)
+	tf.StartWorld()
+
+	tf.DoTick()
+	assert.DeepEqual(t, []types.EntityID{id}, idx.QueryRadius(cardinal.Point{X: 0, Y: 0}, 2))
+
+	tf.DoTick()
+	assert.Equal(t, 0, len(idx.QueryRadius(cardinal.Point{X: 0, Y: 0}, 2)))
+	assert.DeepEqual(t, []types.EntityID{id}, idx.QueryRadius(cardinal.Point{X: 25, Y: 0}, 1))
+
+	tf.DoTick()
+	assert.Equal(t, 0, len(idx.QueryRect(cardinal.Point{X: -100, Y: -100}, cardinal.Point{X: 100, Y: 100})))
+}
+
+func TestSpatialIndexOnlyShowsPositionsOnceTheTickIsCommitted(t *testing.T) {
+	tf, idx := newSpatialIndexTestFixture(t)
+
+	origin := cardinal.Point{X: 0, Y: 0}
+	seenDuringTick := -1
+	err := cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+		if seenDuringTick >= 0 {
+			return nil
+		}
+		if _, err := cardinal.Create(wCtx, Coords{X: 1, Y: 1}); err != nil {
+			return err
+		}
+		seenDuringTick = len(idx.QueryRadius(origin, 2))
+		return nil
+	})
+	assert.NilError(t, err)
+	tf.StartWorld()
+
+	tf.DoTick()
+	assert.Equal(t, 0, seenDuringTick)
+	assert.Equal(t, 1, len(idx.QueryRadius(origin, 2)))
+}
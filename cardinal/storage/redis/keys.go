@@ -5,12 +5,19 @@ import "fmt"
 /*
 	NONCE STORAGE:      ADDRESS_TO_NONCE -> Nonce used for verifying signatures.
 	Hash set of signature address to uint64 nonce
+
+	LAST NONCE STORAGE: PERSONA_TAG_TO_LAST_NONCE -> Last nonce used by a persona tag.
+	Hash of persona tag to uint64 nonce
 */
 
 func (r *NonceStorage) nonceSetKey(str string) string {
 	return fmt.Sprintf("USED_NONCES_%s", str)
 }
 
+func (r *NonceStorage) lastNonceKey() string {
+	return "PERSONA_TAG_TO_LAST_NONCE"
+}
+
 func (r *SchemaStorage) schemaStorageKey() string {
 	return "COMPONENT_NAME_TO_SCHEMA_DATA"
 }
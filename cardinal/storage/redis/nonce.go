@@ -9,6 +9,9 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 const (
@@ -32,7 +35,12 @@ const (
 	float64MantissaSize = 52
 )
 
-var ErrNonceHasAlreadyBeenUsed = errors.New("nonce has already been used")
+var (
+	ErrNonceHasAlreadyBeenUsed = errors.New("nonce has already been used")
+	ErrNonceNotIncreasing      = types.ErrNonceNotIncreasing
+)
+
+var _ storage.NonceStorage = (*NonceStorage)(nil)
 
 type NonceStorage struct {
 	Client *redis.Client
@@ -104,6 +112,43 @@ func (r *NonceStorage) UseNonce(signerAddress string, nonce uint64) error {
 	return nil
 }
 
+// UseIncreasingNonce atomically records the given nonce as the last nonce used by the given persona tag. The nonce must
+// be greater than the last nonce the persona tag used, so nonces start at 1; otherwise ErrNonceNotIncreasing is
+// returned. Once the nonce has been checked, use is called, and the nonce is only recorded if use returns nil, so that
+// the nonce of a transaction that is rejected for another reason can still be used. The error returned by use is
+// returned as is.
+func (r *NonceStorage) UseIncreasingNonce(personaTag string, nonce uint64, use func() error) error {
+	ctx := context.Background()
+	key := r.lastNonceKey()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	lastNonce := uint64(0)
+	value, err := r.Client.HGet(ctx, key, personaTag).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return eris.Wrap(err, "failed to get last nonce of persona tag")
+	}
+	if err == nil {
+		lastNonce, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return eris.Wrapf(err, "failed to convert %q to uint64", value)
+		}
+	}
+	if nonce <= lastNonce {
+		return eris.Wrapf(ErrNonceNotIncreasing, "persona tag %q used nonce %d, the last nonce used was %d",
+			personaTag, nonce, lastNonce)
+	}
+
+	if err := use(); err != nil {
+		return err
+	}
+	if err := r.Client.HSet(ctx, key, personaTag, nonce).Err(); err != nil {
+		return eris.Wrap(err, "failed to set last nonce of persona tag")
+	}
+	return nil
+}
+
 // cleanupOldNonces removes the record of all nonces that are older than NonceSlidingWindowSize. Nonces in that range
 // can be rejected without checking storage. ZRemRangeByScore has a performance of O(log(N)+M) where N is the number
 // of items in the set and M is the number of items to remove.
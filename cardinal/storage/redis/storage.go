@@ -7,8 +7,12 @@ import (
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+
+	"pkg.world.dev/world-engine/cardinal/storage"
 )
 
+var _ storage.Storage = (*Storage)(nil)
+
 type Storage struct {
 	Namespace string
 	Client    *redis.Client
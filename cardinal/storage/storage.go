@@ -2,6 +2,9 @@ package storage
 
 type NonceStorage interface {
 	UseNonce(signerAddress string, nonce uint64) error
+	// UseIncreasingNonce records the given nonce as the last nonce used by the given persona tag if it is greater than
+	// the last one, and use returns nil.
+	UseIncreasingNonce(personaTag string, nonce uint64, use func() error) error
 }
 
 type SchemaStorage interface {
@@ -1,13 +1,20 @@
 package cardinal
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"slices"
+	"sync"
+	"time"
 
 	"github.com/rotisserie/eris"
+	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -15,6 +22,13 @@ import (
 
 const (
 	noActiveSystemName = ""
+	// stallStackSize is the maximum size of the goroutine stacks included in the panic of a stalled system.
+	stallStackSize = 1 << 20
+)
+
+var (
+	ErrSystemTimeout         = eris.New("system exceeded its timeout")
+	ErrSystemAddedDuringTick = eris.New("systems cannot be added while systems are running")
 )
 
 var _ SystemManager = &systemManager{}
@@ -26,6 +40,28 @@ type System func(ctx WorldContext) error
 type systemType struct {
 	Name string
 	Fn   System
+	systemOptions
+}
+
+// systemOptions are the optional settings of a registered system.
+type systemOptions struct {
+	// Timeout is the maximum amount of time the system may run for in a tick. Zero means no timeout.
+	Timeout time.Duration
+	// Priority decides the order systems run in. Systems with a higher priority run first, and systems with the same
+	// priority run in the order they were registered.
+	Priority int
+	// Interval is the number of ticks between the runs of the system, starting at tick Offset. Zero means the system
+	// runs every tick.
+	Interval uint64
+	Offset   uint64
+}
+
+// runsOnTick reports whether a system with these options runs on the given tick.
+func (o systemOptions) runsOnTick(tick uint64) bool {
+	if o.Interval == 0 {
+		return true
+	}
+	return tick >= o.Offset && (tick-o.Offset)%o.Interval == 0
 }
 
 type SystemManager interface {
@@ -36,11 +72,19 @@ type SystemManager interface {
 	// If no system is currently running, it returns an empty string.
 	GetCurrentSystem() string
 
+	// SystemTimings returns how long each system took to run in the last tick, keyed by system name.
+	SystemTimings() map[string]time.Duration
+
 	// These methods are intentionally made private to avoid other
 	// packages from trying to modify the system manager in the middle of a tick.
 	registerSystems(isInit bool, systems ...System) error
+	registerSystemsWithOptions(isInit bool, opts systemOptions, systems ...System) error
 	registerSystem(isInit bool, systemName string, systemFunc System) error
+	addSystemBetweenTicks(systemName string, systemFunc System) error
+	isRunningSystems() bool
+	sortSystemsByPriority()
 	runSystems(ctx context.Context, wCtx WorldContext) error
+	setTracer(tracer trace.Tracer)
 }
 
 type systemManager struct {
@@ -49,9 +93,19 @@ type systemManager struct {
 	registeredSystems     []systemType
 	registeredInitSystems []systemType
 
+	// isRunning is true while runSystems is executing systems. It and the registered systems are guarded by mu so that
+	// systems can be added from outside the tick loop after the world has started.
+	isRunning bool
+	mu        sync.Mutex
+
 	// currentSystem is the name of the system that is currently running.
 	currentSystem string
 
+	// lastTickTimings is the wall-clock time each system took to run in the last tick. It is replaced at the end of
+	// every tick, and is guarded by timingsMu because it may be read while a tick is running.
+	lastTickTimings map[string]time.Duration
+	timingsMu       sync.RWMutex
+
 	tracer trace.Tracer
 }
 
@@ -59,7 +113,11 @@ func newSystemManager() SystemManager {
 	var sm SystemManager = &systemManager{
 		registeredSystems:     make([]systemType, 0),
 		registeredInitSystems: make([]systemType, 0),
+		isRunning:             false,
+		mu:                    sync.Mutex{},
 		currentSystem:         noActiveSystemName,
+		lastTickTimings:       map[string]time.Duration{},
+		timingsMu:             sync.RWMutex{},
 		tracer:                otel.Tracer("system"),
 	}
 	return sm
@@ -70,6 +128,13 @@ func newSystemManager() SystemManager {
 // If isInit is true, the system will only be executed once at tick 0.
 // If there is a duplicate system name, an error will be returned and none of the systems will be registered.
 func (m *systemManager) registerSystems(isInit bool, systemFuncs ...System) error {
+	opts := systemOptions{Timeout: 0, Priority: 0, Interval: 0, Offset: 0}
+	return m.registerSystemsWithOptions(isInit, opts, systemFuncs...)
+}
+
+// registerSystemsWithOptions registers multiple systems like registerSystems, applying the given options to each of
+// them.
+func (m *systemManager) registerSystemsWithOptions(isInit bool, opts systemOptions, systemFuncs ...System) error {
 	// We create a list of systemType structs to register, and then register them in one go to ensure all or nothing.
 	systemsToRegister := make([]systemType, 0, len(systemFuncs))
 
@@ -97,13 +162,13 @@ func (m *systemManager) registerSystems(isInit bool, systemFuncs ...System) erro
 			return eris.Errorf("System %q is already registered", systemName)
 		}
 
-		systemsToRegister = append(systemsToRegister, systemType{Name: systemName, Fn: systemFunc})
+		systemsToRegister = append(systemsToRegister, systemType{Name: systemName, Fn: systemFunc, systemOptions: opts})
 	}
 
 	// We only register if the system if we know for sure all of them is not already registsred.
 	for _, sys := range systemsToRegister {
-		if err := m.registerSystem(isInit, sys.Name, sys.Fn); err != nil {
-			return eris.Wrap(err, "failed to register system")
+		if err := m.addSystem(isInit, sys); err != nil {
+			return eris.Wrapf(err, "failed to register system %q", sys.Name)
 		}
 	}
 
@@ -112,18 +177,29 @@ func (m *systemManager) registerSystems(isInit bool, systemFuncs ...System) erro
 
 // registerSystem is an internal function that allows us to register a system with a custom system name.
 func (m *systemManager) registerSystem(isInit bool, systemName string, systemFunc System) error {
+	return m.addSystem(isInit, systemType{
+		Name:          systemName,
+		Fn:            systemFunc,
+		systemOptions: systemOptions{Timeout: 0, Priority: 0, Interval: 0, Offset: 0},
+	})
+}
+
+// addSystem appends the given system to the registered systems.
+func (m *systemManager) addSystem(isInit bool, systemToRegister systemType) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	// TODO: there is duplication in check in registerSystems and this function.
 	//  We should refactor this, but we are doing it this way to err on the side of safety.
 
 	// Checks if the system is already previously registered.
 	if slices.ContainsFunc(
 		slices.Concat(m.registeredSystems, m.registeredInitSystems),
-		func(s systemType) bool { return s.Name == systemName },
+		func(s systemType) bool { return s.Name == systemToRegister.Name },
 	) {
-		return eris.Errorf("System %q is already registered", systemName)
+		return eris.Errorf("System %q is already registered", systemToRegister.Name)
 	}
 
-	systemToRegister := systemType{Name: systemName, Fn: systemFunc}
 	if isInit {
 		m.registeredInitSystems = append(m.registeredInitSystems, systemToRegister)
 	} else {
@@ -133,44 +209,113 @@ func (m *systemManager) registerSystem(isInit bool, systemName string, systemFun
 	return nil
 }
 
-// RunSystems runs all the registered system in the order that they were registered.
+// isRunningSystems reports whether the systems of a tick are currently running.
+func (m *systemManager) isRunningSystems() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isRunning
+}
+
+// addSystemBetweenTicks appends a system to the end of the registered systems so that it runs from the next tick on.
+// An error is returned if systems are currently running.
+func (m *systemManager) addSystemBetweenTicks(systemName string, systemFunc System) error {
+	if m.isRunningSystems() {
+		return eris.Wrapf(ErrSystemAddedDuringTick, "failed to add system %q", systemName)
+	}
+	// The tick loop may start running systems between the check above and the registration below. This is fine since
+	// the running tick has already taken its own copy of the registered systems.
+	return m.addSystem(false, systemType{
+		Name:          systemName,
+		Fn:            systemFunc,
+		systemOptions: systemOptions{Timeout: 0, Priority: 0, Interval: 0, Offset: 0},
+	})
+}
+
+// sortSystemsByPriority orders the registered systems so that systems with a higher priority run first. The sort is
+// stable, so systems with the same priority keep their registration order. This is done once when the world starts
+// rather than on every tick.
+func (m *systemManager) sortSystemsByPriority() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	byPriority := func(a, b systemType) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	}
+	slices.SortStableFunc(m.registeredInitSystems, byPriority)
+	slices.SortStableFunc(m.registeredSystems, byPriority)
+}
+
+// RunSystems runs all the registered system in the order that they were registered. On tick 0, the init systems run
+// first, then the message handlers registered with RegisterMessageHandler, and then the other systems.
 func (m *systemManager) runSystems(ctx context.Context, wCtx WorldContext) error {
 	ctx, span := m.tracer.Start(ctx, "system.run")
 	defer span.End()
 
-	var systemsToRun []systemType
+	m.mu.Lock()
+	m.isRunning = true
+	var initSystems []systemType
 	if wCtx.CurrentTick() == 0 {
-		systemsToRun = slices.Concat(m.registeredInitSystems, m.registeredSystems)
-	} else {
-		systemsToRun = m.registeredSystems
+		initSystems = slices.Clone(m.registeredInitSystems)
 	}
-
-	// Store the original logger so that it can be reset to its original value
+	systems := slices.Clone(m.registeredSystems)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.isRunning = false
+		m.mu.Unlock()
+	}()
+
+	// Store the original logger and context, so that they are reset to their original values however the systems end
 	logger := wCtx.Logger()
+	defer func() {
+		wCtx.setLogger(*logger)
+		wCtx.setContext(ctx)
+	}()
+
+	fail := func(err error) error {
+		m.currentSystem = ""
+		span.SetStatus(codes.Error, eris.ToString(err, true))
+		span.RecordError(err)
+		return err
+	}
 
-	for _, sys := range systemsToRun {
-		// Explicit memory aliasing
-		m.currentSystem = sys.Name
-
-		// Inject the system name into the logger
-		wCtx.setLogger(logger.With().Str("system", sys.Name).Logger())
-
-		// Executes the system function that the user registered
-		_, systemFnSpan := m.tracer.Start(ctx, "system.run."+sys.Name)
-		if err := sys.Fn(wCtx); err != nil {
-			m.currentSystem = ""
-			span.SetStatus(codes.Error, eris.ToString(err, true))
-			span.RecordError(err)
-			systemFnSpan.SetStatus(codes.Error, eris.ToString(err, true))
-			systemFnSpan.RecordError(err)
-			systemFnSpan.End()
-			return eris.Wrapf(err, "System %s generated an error", sys.Name)
+	// Drop the tick events left over from a failed tick, and deliver the deferred events of the previous tick
+	w := wCtx.getWorld()
+	if w != nil {
+		w.tickEvents.reset()
+		err := w.tickEvents.deliverDeferred(wCtx)
+		if err == nil {
+			err = w.tickEvents.dispatch(wCtx)
+		}
+		if err != nil {
+			return fail(err)
 		}
-		systemFnSpan.End()
 	}
 
-	// Reset the logger to the original logger
-	wCtx.setLogger(*logger)
+	timings := make(map[string]time.Duration, len(initSystems)+len(systems))
+	defer m.setLastTickTimings(timings)
+
+	for _, sys := range initSystems {
+		if err := m.runSystem(ctx, wCtx, *logger, sys, timings); err != nil {
+			return fail(err)
+		}
+	}
+
+	// The message handlers see the changes of the init systems
+	if w != nil {
+		err := w.runMessageHandlers(wCtx)
+		if err == nil {
+			err = w.tickEvents.dispatch(wCtx)
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	for _, sys := range systems {
+		if err := m.runSystem(ctx, wCtx, *logger, sys, timings); err != nil {
+			return fail(err)
+		}
+	}
 
 	// Indicate that no system is currently running
 	m.currentSystem = noActiveSystemName
@@ -178,7 +323,148 @@ func (m *systemManager) runSystems(ctx context.Context, wCtx WorldContext) error
 	return nil
 }
 
+// runSystem runs the given system if it runs on the current tick, and records how long it ran for in timings.
+func (m *systemManager) runSystem(
+	ctx context.Context, wCtx WorldContext, logger zerolog.Logger, sys systemType, timings map[string]time.Duration,
+) error {
+	if !sys.runsOnTick(wCtx.CurrentTick()) {
+		return nil
+	}
+
+	// Explicit memory aliasing
+	m.currentSystem = sys.Name
+
+	// Inject the system name into the logger
+	wCtx.setLogger(logger.With().Str("system", sys.Name).Logger())
+
+	// Executes the system function that the user registered. The system's span is passed on through the world
+	// context, so that spans started by the system are its children.
+	systemCtx, systemFnSpan := m.tracer.Start(ctx, "system.run."+sys.Name)
+	defer systemFnSpan.End()
+	wCtx.setContext(systemCtx)
+	startTime := time.Now()
+	err, panicErr := runSystemRecovering(wCtx, sys)
+	timings[sys.Name] = time.Since(startTime)
+	if panicErr != nil {
+		wCtx.Logger().Error().Err(panicErr).Msgf("system %s panicked and was skipped for this tick:\n %s",
+			sys.Name, eris.ToString(panicErr, true))
+		systemFnSpan.RecordError(panicErr)
+	}
+	if err != nil {
+		systemFnSpan.SetStatus(codes.Error, eris.ToString(err, true))
+		systemFnSpan.RecordError(err)
+		return eris.Wrapf(err, "System %s generated an error", sys.Name)
+	}
+
+	// Deliver the tick events emitted by the system before the next system runs
+	if w := wCtx.getWorld(); w != nil {
+		if err := w.tickEvents.dispatch(wCtx); err != nil {
+			return eris.Wrapf(err, "System %s generated an error", sys.Name)
+		}
+	}
+	return nil
+}
+
+// runSystemRecovering runs the system like runSystemFn, but recovers a panic in the system and returns it as panicErr,
+// so that the other systems and the following ticks still run (see runRecovering).
+func runSystemRecovering(wCtx WorldContext, sys systemType) (err, panicErr error) {
+	err, panicErr, undoErr := runRecovering(wCtx, "system "+sys.Name, false, func() error {
+		return runSystemFn(wCtx, sys)
+	})
+	if undoErr != nil {
+		return undoErr, panicErr
+	}
+	return err, panicErr
+}
+
+// runRecovering calls fn under a savepoint, recovering a panic in fn and returning it as panicErr. The changes fn made
+// to the world's state before it panicked are rolled back, and the tick events it emitted are dropped; if undoOnError
+// is set, so are the changes made by fn before it returned an error. If the changes can't be rolled back, the state
+// can't be trusted anymore, and undoErr is returned so that the tick fails. Panics caused by fatal errors (see
+// panicOnFatalError), after which the world's state can't be trusted, are not recovered, and neither are any panics if
+// the world was created with WithStrictSystemPanics. The given name describes what fn runs in the panic's error.
+func runRecovering(
+	wCtx WorldContext, name string, undoOnError bool, fn func() error,
+) (err, panicErr, undoErr error) {
+	w := wCtx.getWorld()
+	if w == nil {
+		return fn(), nil, nil
+	}
+	w.fatalPanic.Store(false)
+	wCtx.storeManager().Savepoint()
+	w.spatialIndexes.savepoint()
+	pendingEvents := w.tickEvents.savepoint()
+	defer func() {
+		var r any
+		if !w.strictSystemPanics {
+			r = recover()
+		}
+		if r == nil && (err == nil || !undoOnError) {
+			wCtx.storeManager().ReleaseSavepoint()
+			w.spatialIndexes.releaseSavepoint()
+			return
+		}
+		if r != nil {
+			if w.fatalPanic.Load() {
+				panic(r)
+			}
+			if rErr, ok := r.(error); ok {
+				panicErr = eris.Wrapf(rErr, "%s panicked", name)
+			} else {
+				panicErr = eris.Errorf("%s panicked: %v", name, r)
+			}
+		}
+		w.tickEvents.rollbackToSavepoint(pendingEvents)
+		w.spatialIndexes.rollbackToSavepoint()
+		if rollbackErr := wCtx.storeManager().RollbackToSavepoint(); rollbackErr != nil {
+			// The state can't be trusted anymore, so the tick fails.
+			undoErr = eris.Wrapf(rollbackErr, "failed to undo the changes of %s", name)
+		}
+	}()
+	return fn(), nil, nil
+}
+
+// runSystemFn executes the system function. If the system has a timeout, the context of the world context is canceled
+// once the timeout has passed, and ErrSystemTimeout is returned if the system did not return within it. The system
+// always runs to completion on the calling goroutine, so it never touches the world's state after the tick ends. If
+// the system has still not returned once its timeout has passed a second time, it is considered stalled, and
+// onSystemStall is called from a watchdog goroutine.
+func runSystemFn(wCtx WorldContext, sys systemType) error {
+	if sys.Timeout <= 0 {
+		return sys.Fn(wCtx)
+	}
+
+	parent := wCtx.Context()
+	ctx, cancel := context.WithTimeout(parent, sys.Timeout)
+	defer cancel()
+	wCtx.setContext(ctx)
+	defer wCtx.setContext(parent)
+
+	watchdog := time.AfterFunc(2*sys.Timeout, func() { onSystemStall(sys) })
+	defer watchdog.Stop()
+
+	err := sys.Fn(wCtx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return eris.Wrapf(ErrSystemTimeout, "system did not return within %s", sys.Timeout)
+	}
+	return err
+}
+
+// onSystemStall is called when a system registered with a timeout ignores its canceled context and blocks the tick
+// loop. A running function can't be stopped and the world can't tick without it, so the process is crashed with the
+// stacks of all goroutines, which include the one of the stalled system. It is a variable so that tests can replace it.
+var onSystemStall = func(sys systemType) {
+	buf := make([]byte, stallStackSize)
+	buf = buf[:runtime.Stack(buf, true)]
+	panic(fmt.Sprintf(
+		"system %s is stalled: it did not return within twice its timeout of %s, so the tick loop can't continue\n\n%s",
+		sys.Name, sys.Timeout, buf,
+	))
+}
+
 func (m *systemManager) GetRegisteredSystems() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	sys := slices.Concat(m.registeredInitSystems, m.registeredSystems)
 	sysNames := make([]string, len(sys))
 	for i, sys := range slices.Concat(m.registeredInitSystems, m.registeredSystems) {
@@ -190,3 +476,19 @@ func (m *systemManager) GetRegisteredSystems() []string {
 func (m *systemManager) GetCurrentSystem() string {
 	return m.currentSystem
 }
+
+func (m *systemManager) SystemTimings() map[string]time.Duration {
+	m.timingsMu.RLock()
+	defer m.timingsMu.RUnlock()
+	return maps.Clone(m.lastTickTimings)
+}
+
+func (m *systemManager) setTracer(tracer trace.Tracer) {
+	m.tracer = tracer
+}
+
+func (m *systemManager) setLastTickTimings(timings map[string]time.Duration) {
+	m.timingsMu.Lock()
+	defer m.timingsMu.Unlock()
+	m.lastTickTimings = timings
+}
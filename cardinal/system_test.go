@@ -102,3 +102,137 @@ func TestInitSystemRunsOnce(t *testing.T) {
 	assert.Equal(t, count, 1)
 	assert.Equal(t, count2, 2)
 }
+
+func TestNamedSystemsHaveTimings(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterSystem(world, "first", func(cardinal.WorldContext) error {
+		return nil
+	}))
+	assert.NilError(t, cardinal.RegisterSystem(world, "second", func(cardinal.WorldContext) error {
+		return nil
+	}))
+	assert.ErrorContains(t, cardinal.RegisterSystem(world, "first", func(cardinal.WorldContext) error {
+		return nil
+	}), "already registered")
+
+	tf.DoTick()
+
+	timings := world.SystemTimings()
+	for _, name := range []string{"first", "second"} {
+		duration, ok := timings[name]
+		assert.Check(t, ok, "missing timing for system %q", name)
+		assert.Check(t, duration >= 0)
+	}
+}
+
+func TestSystemCanBeAddedAfterStartGame(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	tf.DoTick()
+	tf.DoTick()
+
+	count := 0
+	assert.NilError(t, world.AddSystem("late", func(cardinal.WorldContext) error {
+		count++
+		return nil
+	}))
+	registered := world.GetRegisteredSystems()
+	assert.Equal(t, "late", registered[len(registered)-1])
+
+	tf.DoTick()
+	tf.DoTick()
+	assert.Equal(t, 2, count)
+}
+
+func TestSystemCannotBeAddedDuringTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	var addErr error
+	assert.NilError(t, cardinal.RegisterSystem(world, "adder", func(cardinal.WorldContext) error {
+		addErr = world.AddSystem("nested", func(cardinal.WorldContext) error {
+			return nil
+		})
+		return nil
+	}))
+
+	tf.DoTick()
+	assert.ErrorIs(t, addErr, cardinal.ErrSystemAddedDuringTick)
+}
+
+func TestSystemsRunInPriorityOrder(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	var order []string
+	assert.NilError(t, cardinal.RegisterSystems(world, func(cardinal.WorldContext) error {
+		order = append(order, "default")
+		return nil
+	}))
+	assert.NilError(t, cardinal.RegisterSystemWithPriority(world, -1, func(cardinal.WorldContext) error {
+		order = append(order, "low")
+		return nil
+	}))
+	assert.NilError(t, cardinal.RegisterSystemWithPriority(world, 10, func(cardinal.WorldContext) error {
+		order = append(order, "high")
+		return nil
+	}))
+	assert.NilError(t, cardinal.RegisterSystemWithPriority(world, 10, func(cardinal.WorldContext) error {
+		order = append(order, "high too")
+		return nil
+	}))
+
+	tf.DoTick()
+	tf.DoTick()
+
+	assert.DeepEqual(t, order, []string{
+		"high", "high too", "default", "low",
+		"high", "high too", "default", "low",
+	})
+}
+
+func TestSystemsSeeTheCurrentTickAndTimestamp(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	var ticks, timestamps []uint64
+	err := cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+		ticks = append(ticks, wCtx.CurrentTick())
+		timestamps = append(timestamps, wCtx.Timestamp())
+		return nil
+	})
+	assert.NilError(t, err)
+
+	for i := 0; i < 5; i++ {
+		tf.DoTick()
+	}
+
+	assert.DeepEqual(t, ticks, []uint64{0, 1, 2, 3, 4})
+	assert.Equal(t, tf.World.CurrentTick(), uint64(5))
+	for i, ts := range timestamps {
+		assert.Check(t, ts > 0)
+		if i > 0 {
+			assert.Check(t, ts >= timestamps[i-1])
+		}
+	}
+}
+
+func TestSystemsCanRunEveryNTicks(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	var every3, offset2 []uint64
+	assert.NilError(t, cardinal.RegisterSystemEvery(tf.World, 3, func(wCtx cardinal.WorldContext) error {
+		every3 = append(every3, wCtx.CurrentTick())
+		return nil
+	}))
+	assert.NilError(t, cardinal.RegisterSystemEveryWithOffset(tf.World, 4, 2, func(wCtx cardinal.WorldContext) error {
+		offset2 = append(offset2, wCtx.CurrentTick())
+		return nil
+	}))
+	assert.ErrorContains(t, cardinal.RegisterSystemEvery(tf.World, 0, func(cardinal.WorldContext) error {
+		return nil
+	}), "interval must be positive")
+
+	for i := 0; i < 11; i++ {
+		tf.DoTick()
+	}
+
+	assert.DeepEqual(t, every3, []uint64{0, 3, 6, 9})
+	assert.DeepEqual(t, offset2, []uint64{2, 6, 10})
+}
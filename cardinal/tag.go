@@ -0,0 +1,198 @@
+package cardinal
+
+import (
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// entityTags is an internal component that holds the tags of an entity as a bitset, indexed by the order in which the
+// tags were registered. All tags of an entity share this one component, so tagging entities at most doubles the number
+// of archetypes, no matter how many tags are registered, while every marker component can double it.
+type entityTags struct {
+	Bits []uint64
+}
+
+func (entityTags) Name() string {
+	return "entityTags"
+}
+
+func (t *entityTags) has(bit int) bool {
+	word := bit / 64
+	return word < len(t.Bits) && t.Bits[word]&(1<<(bit%64)) != 0
+}
+
+func (t *entityTags) set(bit int) {
+	word := bit / 64
+	if word >= len(t.Bits) {
+		t.Bits = append(t.Bits, make([]uint64, word+1-len(t.Bits))...)
+	}
+	t.Bits[word] |= 1 << (bit % 64)
+}
+
+// clear clears the given bit, and reports whether any bits are left.
+func (t *entityTags) clear(bit int) bool {
+	if word := bit / 64; word < len(t.Bits) {
+		t.Bits[word] &^= 1 << (bit % 64)
+	}
+	return slices.ContainsFunc(t.Bits, func(w uint64) bool { return w != 0 })
+}
+
+// isTagsComponent reports whether the given component is the internal component that holds the tags of entities.
+func isTagsComponent(c types.Component) bool {
+	return c.Name() == entityTags{}.Name()
+}
+
+// withoutTags wraps a component filter so that it doesn't see the component that holds the tags of entities. Tags are
+// not components, so an entity with tags matches the same filters as it does without them, e.g. filter.Exact and
+// filter.ComponentCount.
+type withoutTags struct {
+	filter filter.ComponentFilter
+}
+
+func (f withoutTags) MatchesComponents(components []types.Component) bool {
+	if slices.ContainsFunc(components, isTagsComponent) {
+		components = slices.DeleteFunc(slices.Clone(components), isTagsComponent)
+	}
+	return f.filter.MatchesComponents(components)
+}
+
+// RegisterTag registers a tag, a boolean marker for entities such as "enemy" or "selected". Tags are a lightweight
+// alternative to components without fields: the tags of an entity are stored together as a bitset, so unlike marker
+// components, they don't split entities into a new archetype for every combination of markers. The bitset is held by
+// an internal component, so an entity with tags is stored in a separate archetype from the entities without tags, but
+// searches and EachArchetype don't see that component: an entity matches the same component filters, including
+// filter.Exact and filter.ComponentCount, whether or not it has tags.
+//
+// Tags are stored by the order in which they were registered, so they must always be registered in the same order, and
+// new tags must be registered after the existing ones. Tags must be registered before the world starts.
+func RegisterTag(w *World, name string) error {
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"failed to register tag %q: world state is %s, expected %s",
+			name, w.worldStage.Current(), worldstage.Init,
+		)
+	}
+	if name == "" {
+		return eris.New("failed to register tag: tag name must not be empty")
+	}
+	if _, ok := w.tags[name]; ok {
+		return eris.Errorf("tag %q is already registered", name)
+	}
+	w.tags[name] = len(w.tags)
+	return nil
+}
+
+// tagBit returns the bit of the given tag.
+func tagBit(wCtx WorldContext, name string) (int, error) {
+	bit, ok := wCtx.getWorld().tags[name]
+	if !ok {
+		return 0, eris.Errorf("tag %q is not registered", name)
+	}
+	return bit, nil
+}
+
+// getTags returns the tags of an entity, which are empty if the entity has no tags.
+func getTags(wCtx WorldContext, id types.EntityID) (*entityTags, bool, error) {
+	ok, err := hasComponent(wCtx, id, entityTags{}.Name())
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		return &entityTags{}, false, nil
+	}
+	tags, err := GetComponent[entityTags](wCtx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	return tags, true, nil
+}
+
+// AddTag adds the given tag to an entity. Adding a tag that the entity already has does nothing.
+func AddTag(wCtx WorldContext, id types.EntityID, name string) error {
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+	bit, err := tagBit(wCtx, name)
+	if err != nil {
+		return err
+	}
+	tags, ok, err := getTags(wCtx, id)
+	if err != nil {
+		return err
+	}
+	if tags.has(bit) {
+		return nil
+	}
+	tags = &entityTags{Bits: slices.Clone(tags.Bits)}
+	tags.set(bit)
+	if !ok {
+		if err := AddComponentTo[entityTags](wCtx, id); err != nil {
+			return err
+		}
+	}
+	return SetComponent[entityTags](wCtx, id, tags)
+}
+
+// RemoveTag removes the given tag from an entity. Removing a tag that the entity doesn't have does nothing.
+func RemoveTag(wCtx WorldContext, id types.EntityID, name string) error {
+	if wCtx.isReadOnly() {
+		return ErrEntityMutationOnReadOnly
+	}
+	bit, err := tagBit(wCtx, name)
+	if err != nil {
+		return err
+	}
+	tags, _, err := getTags(wCtx, id)
+	if err != nil {
+		return err
+	}
+	if !tags.has(bit) {
+		return nil
+	}
+	tags = &entityTags{Bits: slices.Clone(tags.Bits)}
+	if !tags.clear(bit) {
+		// The entity has no tags left, so it goes back to the archetype of its other components.
+		return RemoveComponentFrom[entityTags](wCtx, id)
+	}
+	return SetComponent[entityTags](wCtx, id, tags)
+}
+
+// HasTag reports whether an entity has the given tag.
+func HasTag(wCtx WorldContext, id types.EntityID, name string) (bool, error) {
+	bit, err := tagBit(wCtx, name)
+	if err != nil {
+		return false, err
+	}
+	tags, _, err := getTags(wCtx, id)
+	if err != nil {
+		return false, err
+	}
+	return tags.has(bit), nil
+}
+
+// WithTag returns a filter for Search.Where that matches the entities with the given tag. Tags are not part of
+// archetypes, so unlike component filters, it is evaluated for every entity that matches the rest of the search.
+func WithTag(name string) FilterFn {
+	return func(wCtx WorldContext, id types.EntityID) (bool, error) {
+		return HasTag(wCtx, id, name)
+	}
+}
+
+// tagPlugin registers the component that holds the tags of entities.
+type tagPlugin struct{}
+
+func newTagPlugin() *tagPlugin {
+	return &tagPlugin{}
+}
+
+func (*tagPlugin) Register(w *World) error {
+	if err := RegisterComponent[entityTags](w); err != nil {
+		return eris.Wrap(err, "failed to register tag component")
+	}
+	return nil
+}
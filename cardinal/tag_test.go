@@ -0,0 +1,170 @@
+package cardinal_test
+
+import (
+	"slices"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type EnemyMarker struct{}
+
+func (EnemyMarker) Name() string { return "enemyMarker" }
+
+type SelectedMarker struct{}
+
+func (SelectedMarker) Name() string { return "selectedMarker" }
+
+type FlyingMarker struct{}
+
+func (FlyingMarker) Name() string { return "flyingMarker" }
+
+type BossMarker struct{}
+
+func (BossMarker) Name() string { return "bossMarker" }
+
+func countArchetypes(t *testing.T, wCtx cardinal.WorldContext) int {
+	count := 0
+	err := cardinal.EachArchetype(wCtx, filter.Contains(filter.Component[Health]()), func(cardinal.Archetype) bool {
+		count++
+		return true
+	})
+	assert.NilError(t, err)
+	return count
+}
+
+func TestTagsDoNotSplitArchetypesLikeMarkerComponents(t *testing.T) {
+	markers := []types.Component{EnemyMarker{}, SelectedMarker{}, FlyingMarker{}, BossMarker{}}
+	tags := []string{"enemy", "selected", "flying", "boss"}
+
+	// Give an entity every combination of the markers.
+	markerWorld := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Health](markerWorld.World))
+	assert.NilError(t, cardinal.RegisterComponent[EnemyMarker](markerWorld.World))
+	assert.NilError(t, cardinal.RegisterComponent[SelectedMarker](markerWorld.World))
+	assert.NilError(t, cardinal.RegisterComponent[FlyingMarker](markerWorld.World))
+	assert.NilError(t, cardinal.RegisterComponent[BossMarker](markerWorld.World))
+	markerWorld.StartWorld()
+	markerCtx := cardinal.NewWorldContext(markerWorld.World)
+	for combo := 0; combo < 1<<len(markers); combo++ {
+		comps := []types.Component{Health{}}
+		for i, marker := range markers {
+			if combo&(1<<i) != 0 {
+				comps = append(comps, marker)
+			}
+		}
+		_, err := cardinal.Create(markerCtx, comps...)
+		assert.NilError(t, err)
+	}
+	markerWorld.DoTick()
+
+	// Do the same with tags.
+	tagWorld := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Health](tagWorld.World))
+	for _, tag := range tags {
+		assert.NilError(t, cardinal.RegisterTag(tagWorld.World, tag))
+	}
+	tagWorld.StartWorld()
+	tagCtx := cardinal.NewWorldContext(tagWorld.World)
+	for combo := 0; combo < 1<<len(tags); combo++ {
+		id, err := cardinal.Create(tagCtx, Health{})
+		assert.NilError(t, err)
+		for i, tag := range tags {
+			if combo&(1<<i) != 0 {
+				assert.NilError(t, cardinal.AddTag(tagCtx, id, tag))
+			}
+		}
+	}
+	tagWorld.DoTick()
+
+	assert.Equal(t, countArchetypes(t, markerCtx), 16)
+	// Entities without tags, and entities with any tags.
+	assert.Equal(t, countArchetypes(t, tagCtx), 2)
+}
+
+func TestTagsCanBeAddedRemovedAndSearched(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	assert.NilError(t, cardinal.RegisterTag(world, "enemy"))
+	assert.NilError(t, cardinal.RegisterTag(world, "selected"))
+	assert.ErrorContains(t, cardinal.RegisterTag(world, "enemy"), "already registered")
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 3, Health{})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.AddTag(wCtx, ids[0], "enemy"))
+	assert.NilError(t, cardinal.AddTag(wCtx, ids[0], "enemy"))
+	assert.NilError(t, cardinal.AddTag(wCtx, ids[0], "selected"))
+	assert.NilError(t, cardinal.AddTag(wCtx, ids[2], "enemy"))
+	assert.ErrorContains(t, cardinal.AddTag(wCtx, ids[1], "unknown"), "not registered")
+	tf.DoTick()
+
+	enemies, err := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Health]())).
+		Where(cardinal.WithTag("enemy")).Collect(wCtx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, enemies, []types.EntityID{ids[0], ids[2]})
+
+	assert.NilError(t, cardinal.RemoveTag(wCtx, ids[0], "enemy"))
+	assert.NilError(t, cardinal.RemoveTag(wCtx, ids[1], "enemy"))
+	// Removing the last tag of an entity removes the component that holds its tags.
+	assert.NilError(t, cardinal.RemoveTag(wCtx, ids[2], "enemy"))
+	tf.DoTick()
+
+	readCtx := cardinal.NewReadOnlyWorldContext(world)
+	for _, tc := range []struct {
+		id   types.EntityID
+		tag  string
+		want bool
+	}{
+		{ids[0], "enemy", false},
+		{ids[0], "selected", true},
+		{ids[1], "enemy", false},
+		{ids[2], "enemy", false},
+	} {
+		got, err := cardinal.HasTag(readCtx, tc.id, tc.tag)
+		assert.NilError(t, err)
+		assert.Equal(t, got, tc.want)
+	}
+	assert.Equal(t, countArchetypes(t, wCtx), 2)
+}
+
+func TestTagsDoNotChangeWhichComponentFiltersMatch(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	assert.NilError(t, cardinal.RegisterTag(world, "enemy"))
+	tf.StartWorld()
+
+	wCtx := cardinal.NewWorldContext(world)
+	ids, err := cardinal.CreateMany(wCtx, 2, Health{})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.AddTag(wCtx, ids[0], "enemy"))
+	tf.DoTick()
+
+	for _, ctx := range []cardinal.WorldContext{wCtx, cardinal.NewReadOnlyWorldContext(world)} {
+		for _, f := range []filter.ComponentFilter{
+			filter.Exact(filter.Component[Health]()),
+			filter.ComponentCount(1, 1),
+			filter.All(),
+		} {
+			got, err := cardinal.NewSearch().Entity(f).Collect(ctx)
+			assert.NilError(t, err)
+			slices.Sort(got)
+			assert.DeepEqual(t, got, ids)
+		}
+	}
+
+	// Both archetypes are made of the entities' only component.
+	err = cardinal.EachArchetype(wCtx, filter.All(), func(arch cardinal.Archetype) bool {
+		assert.Equal(t, len(arch.Components), 1)
+		assert.Equal(t, arch.Components[0].Name(), Health{}.Name())
+		return true
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, countArchetypes(t, wCtx), 2)
+}
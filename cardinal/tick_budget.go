@@ -0,0 +1,245 @@
+package cardinal
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/codec"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// tickBudget limits how long the messages of a tick are processed for. See WithTickBudget.
+type tickBudget struct {
+	budget time.Duration
+	// deadline is the time by which the messages of the current tick must have been processed. It is zero outside of
+	// ticks, and in ticks the budget is not enforced for.
+	deadline time.Time
+	// handled are the hashes of the messages that have been handled in the current tick.
+	handled map[types.TxHash]struct{}
+	// deferred are the messages deferred in the current tick, in the order they were deferred, until they are stored
+	// in the state with the tick's changes. The hashes of the ones that were generated by the world are in internal.
+	deferred []txpool.TxData
+	internal map[types.TxHash]struct{}
+}
+
+func newTickBudget(budget time.Duration) *tickBudget {
+	return &tickBudget{
+		budget:   budget,
+		deadline: time.Time{},
+		handled:  map[types.TxHash]struct{}{},
+		deferred: nil,
+		internal: map[types.TxHash]struct{}{},
+	}
+}
+
+// start starts the budget of a tick that started at the given time. If enforce is false, no message is deferred in
+// the tick.
+func (b *tickBudget) start(startedAt time.Time, enforce bool) {
+	clear(b.handled)
+	b.deadline = time.Time{}
+	if enforce {
+		b.deadline = startedAt.Add(b.budget)
+	}
+}
+
+// stop ends the budget of the current tick.
+func (b *tickBudget) stop() {
+	b.deadline = time.Time{}
+	clear(b.handled)
+}
+
+// overBudget reports whether the message with the given hash must be deferred to the next tick because the budget of
+// the current tick is exceeded. Otherwise, the message is recorded as handled. Messages that were already handled in
+// the tick, e.g. by another system, are never deferred, and at least one message is handled in every tick, so that
+// messages are not deferred forever when handling a single message exceeds the budget.
+func (b *tickBudget) overBudget(hash types.TxHash) bool {
+	if b == nil || b.deadline.IsZero() {
+		return false
+	}
+	if _, ok := b.handled[hash]; ok {
+		return false
+	}
+	if len(b.handled) > 0 && time.Now().After(b.deadline) {
+		return true
+	}
+	b.handled[hash] = struct{}{}
+	return false
+}
+
+// tickBudgetOf returns the tick budget of the world of the given context, which is nil if the world doesn't have one.
+func tickBudgetOf(wCtx WorldContext) *tickBudget {
+	w := wCtx.getWorld()
+	if w == nil || wCtx.isReadOnly() {
+		return nil
+	}
+	return w.tickBudget
+}
+
+// deferMessages takes the messages with the given hashes out of the pool of the current tick, so that they are neither
+// processed in it nor submitted to the base shard with it, and carries them over to the next tick. Their receipts in
+// the current tick are marked as deferred.
+func (w *World) deferMessages(pool *txpool.TxPool, hashes []types.TxHash) {
+	toDefer := make(map[types.TxHash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		toDefer[hash] = struct{}{}
+	}
+	deferred := pool.RemoveFunc(func(tx txpool.TxData) bool {
+		_, ok := toDefer[tx.TxHash]
+		return ok
+	})
+	for _, tx := range deferred {
+		if rec, ok := w.receiptHistory.GetReceipt(tx.TxHash); ok && rec.Internal {
+			w.tickBudget.internal[tx.TxHash] = struct{}{}
+		}
+		w.receiptHistory.MarkDeferred(tx.TxHash)
+	}
+	w.tickBudget.deferred = append(w.tickBudget.deferred, deferred...)
+}
+
+// storeDeferredMessages stores the messages deferred in the current tick in the world's state, so that they are
+// committed along with the other changes of the tick. Keeping them in the state means they survive restarts and are
+// included in snapshots.
+func (w *World) storeDeferredMessages(wCtx WorldContext) error {
+	if w.tickBudget == nil || len(w.tickBudget.deferred) == 0 {
+		return nil
+	}
+	for _, tx := range w.tickBudget.deferred {
+		msgType, ok := w.GetMessageByID(tx.MsgID)
+		if !ok {
+			return eris.Errorf("failed to store deferred message %s: message %d is not registered", tx.TxHash, tx.MsgID)
+		}
+		body, err := msgType.Encode(tx.Msg)
+		if err != nil {
+			return eris.Wrapf(err, "failed to store deferred message %s", tx.TxHash)
+		}
+		signed, err := codec.Encode(tx.Tx)
+		if err != nil {
+			return eris.Wrapf(err, "failed to store deferred message %s", tx.TxHash)
+		}
+		_, isInternal := w.tickBudget.internal[tx.TxHash]
+		_, err = Create(wCtx, deferredMessage{
+			MessageName:     msgType.FullName(),
+			Body:            body,
+			Tx:              signed,
+			TxHash:          tx.TxHash,
+			EVMSourceTxHash: tx.EVMSourceTxHash,
+			Internal:        isInternal,
+		})
+		if err != nil {
+			return eris.Wrapf(err, "failed to store deferred message %s", tx.TxHash)
+		}
+	}
+	w.tickBudget.deferred = nil
+	clear(w.tickBudget.internal)
+	return nil
+}
+
+// takeDeferredMessages removes the messages deferred in the previous tick from the state and, if inject is set, adds
+// them to the given tick's pool, ahead of the messages submitted since. They were verified and deduplicated when they
+// were first taken into a tick, so they must be added after that is done for the other messages of the tick.
+func (w *World) takeDeferredMessages(wCtx WorldContext, pool *txpool.TxPool, inject bool) error {
+	type stored struct {
+		id  types.EntityID
+		msg *deferredMessage
+	}
+	var msgs []stored
+	var getErr error
+	err := NewSearch().Entity(filter.Exact(filter.Component[deferredMessage]())).Each(wCtx,
+		func(id types.EntityID) bool {
+			msg, err := GetComponent[deferredMessage](wCtx, id)
+			if err != nil {
+				getErr = err
+				return false
+			}
+			msgs = append(msgs, stored{id: id, msg: msg})
+			return true
+		})
+	if err = cmp.Or(getErr, err); err != nil {
+		return eris.Wrap(err, "failed to find the deferred messages")
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	// Entities are created in the order the messages were deferred in.
+	slices.SortFunc(msgs, func(a, b stored) int { return cmp.Compare(a.id, b.id) })
+
+	txs := make([]txpool.TxData, 0, len(msgs))
+	for _, m := range msgs {
+		if err := Remove(wCtx, m.id); err != nil {
+			return eris.Wrap(err, "failed to remove a deferred message")
+		}
+		msgType, ok := w.GetMessageByFullName(m.msg.MessageName)
+		if !ok {
+			return eris.Errorf("deferred message %q is not registered", m.msg.MessageName)
+		}
+		value, err := msgType.Decode(m.msg.Body)
+		if err != nil {
+			return eris.Wrapf(err, "failed to decode deferred message %q", m.msg.MessageName)
+		}
+		tx, err := codec.Decode[*sign.Transaction](m.msg.Tx)
+		if err != nil {
+			return eris.Wrapf(err, "failed to decode the signed transaction of deferred message %q", m.msg.MessageName)
+		}
+		// The hash isn't encoded with the signed transaction, so it is restored as the message was submitted with.
+		tx.Hash = common.HexToHash(string(m.msg.TxHash))
+		txs = append(txs, txpool.TxData{
+			MsgID:           msgType.ID(),
+			Msg:             value,
+			TxHash:          m.msg.TxHash,
+			Tx:              tx,
+			EVMSourceTxHash: m.msg.EVMSourceTxHash,
+		})
+	}
+	if !inject {
+		return nil
+	}
+	pool.AddTransactionsFirst(txs)
+	for _, m := range msgs {
+		if m.msg.Internal {
+			w.receiptHistory.MarkInternal(m.msg.TxHash)
+		}
+	}
+	return nil
+}
+
+// hasDeferredMessages reports whether messages deferred in the last tick are waiting in the state for the next tick.
+func (w *World) hasDeferredMessages() (bool, error) {
+	return anyMatch(NewReadOnlyWorldContext(w), NewSearch().Entity(filter.Exact(filter.Component[deferredMessage]())))
+}
+
+// deferredMessage is an internal component that holds a message deferred by the tick budget until the next tick.
+type deferredMessage struct {
+	MessageName string
+	Body        []byte
+	// Tx is the encoded signed transaction of the message.
+	Tx              []byte
+	TxHash          types.TxHash
+	EVMSourceTxHash string
+	Internal        bool
+}
+
+func (deferredMessage) Name() string {
+	return "deferredMessage"
+}
+
+// tickBudgetPlugin registers the component that holds the messages deferred by the tick budget. It is registered
+// whether or not the world has a tick budget, so that snapshots of worlds with one can be restored into any world.
+type tickBudgetPlugin struct{}
+
+func newTickBudgetPlugin() *tickBudgetPlugin {
+	return &tickBudgetPlugin{}
+}
+
+func (*tickBudgetPlugin) Register(w *World) error {
+	if err := RegisterComponent[deferredMessage](w); err != nil {
+		return eris.Wrap(err, "failed to register deferred message component")
+	}
+	return nil
+}
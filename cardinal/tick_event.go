@@ -0,0 +1,130 @@
+package cardinal
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/worldstage"
+)
+
+// tickEventHandler is a handler registered with RegisterTickEventHandler, with the event untyped.
+type tickEventHandler func(wCtx WorldContext, evt any) error
+
+// tickEventBus passes transient events between the systems of a tick. Unlike the events emitted with EmitEvent, tick
+// events are not broadcast to clients, and unlike components, they are not part of the world's state.
+type tickEventBus struct {
+	handlers map[reflect.Type][]tickEventHandler
+	// deferred are the handlers registered with RegisterDeferredEventHandler, keyed by event type name.
+	deferred map[string]*deferredEventHandlers
+
+	mu      sync.Mutex
+	pending []any
+}
+
+func newTickEventBus() *tickEventBus {
+	return &tickEventBus{
+		handlers: map[reflect.Type][]tickEventHandler{},
+		deferred: map[string]*deferredEventHandlers{},
+		mu:       sync.Mutex{},
+		pending:  nil,
+	}
+}
+
+// RegisterTickEventHandler registers a handler that is called with every event of type T emitted with EmitTickEvent.
+//
+// Events are delivered in the same tick they are emitted in: once the emitting system returns, and before the next
+// system runs, all events emitted by it are delivered in the order they were emitted, with the handlers of each event
+// called in order of registration. Events emitted by handlers are delivered after those, before the next system runs.
+// An error returned by a handler fails the tick, in the same way as an error returned by a system. Events that are
+// still pending when a tick fails are dropped, so no event outlives the tick it was emitted in. The events emitted by a
+// system that panics are dropped along with its other changes.
+//
+// Handlers must be registered before the world starts.
+func RegisterTickEventHandler[T any](w *World, handler func(wCtx WorldContext, evt T) error) error {
+	evtType := reflect.TypeOf((*T)(nil)).Elem()
+	if w.worldStage.Current() != worldstage.Init {
+		return eris.Errorf(
+			"failed to register tick event handler for %s: world state is %s, expected %s",
+			evtType, w.worldStage.Current(), worldstage.Init,
+		)
+	}
+	if handler == nil {
+		return eris.Errorf("failed to register tick event handler for %s: handler must not be nil", evtType)
+	}
+	w.tickEvents.handlers[evtType] = append(w.tickEvents.handlers[evtType], func(wCtx WorldContext, evt any) error {
+		return handler(wCtx, evt.(T)) //nolint:errcheck // events are keyed by their type
+	})
+	return nil
+}
+
+// EmitTickEvent emits an event to the handlers registered for its type with RegisterTickEventHandler. See
+// RegisterTickEventHandler for when the event is delivered. Events without any handlers are dropped. Tick events can
+// only be emitted while systems are running. Unlike the rest of the world context, EmitTickEvent is safe for
+// concurrent use, so it may be called from the callback of EachParallel.
+func EmitTickEvent(wCtx WorldContext, evt any) error {
+	if evt == nil {
+		return eris.New("failed to emit tick event: event must not be nil")
+	}
+	w := wCtx.getWorld()
+	if w == nil || wCtx.isReadOnly() || !w.SystemManager.isRunningSystems() {
+		return eris.New("failed to emit tick event: tick events can only be emitted by running systems")
+	}
+	w.tickEvents.emit(evt)
+	return nil
+}
+
+func (b *tickEventBus) emit(evt any) {
+	if len(b.handlers[reflect.TypeOf(evt)]) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, evt)
+}
+
+// dispatch delivers the pending events, including the events emitted while delivering them, until none are left.
+func (b *tickEventBus) dispatch(wCtx WorldContext) error {
+	for {
+		b.mu.Lock()
+		evts := b.pending
+		b.pending = nil
+		b.mu.Unlock()
+		if len(evts) == 0 {
+			return nil
+		}
+		for _, evt := range evts {
+			for _, handler := range b.handlers[reflect.TypeOf(evt)] {
+				if err := handler(wCtx, evt); err != nil {
+					b.mu.Lock()
+					b.pending = nil
+					b.mu.Unlock()
+					return eris.Wrapf(err, "tick event handler for %T generated an error", evt)
+				}
+			}
+		}
+	}
+}
+
+// savepoint returns the number of pending events, so that the events emitted after it can be dropped with
+// rollbackToSavepoint.
+func (b *tickEventBus) savepoint() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// rollbackToSavepoint drops the events emitted since the given savepoint was taken.
+func (b *tickEventBus) rollbackToSavepoint(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = b.pending[:min(n, len(b.pending))]
+}
+
+// reset drops the pending events, e.g. those left over from a failed tick.
+func (b *tickEventBus) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = nil
+}
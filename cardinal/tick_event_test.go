@@ -0,0 +1,175 @@
+package cardinal_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+type Collision struct {
+	Target types.EntityID
+	Damage int
+}
+
+type Knockout struct {
+	Target types.EntityID
+}
+
+func TestTickEventsAreDeliveredBeforeTheNextSystem(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+
+	var target types.EntityID
+	var log []string
+	assert.NilError(t, cardinal.RegisterTickEventHandler[Collision](world,
+		func(wCtx cardinal.WorldContext, evt Collision) error {
+			log = append(log, "collision")
+			return cardinal.UpdateComponent[Health](wCtx, evt.Target, func(h *Health) *Health {
+				h.Value -= evt.Damage
+				if h.Value <= 0 {
+					assert.NilError(t, cardinal.EmitTickEvent(wCtx, Knockout{Target: evt.Target}))
+				}
+				return h
+			})
+		}))
+	assert.NilError(t, cardinal.RegisterTickEventHandler[Knockout](world,
+		func(_ cardinal.WorldContext, evt Knockout) error {
+			assert.Equal(t, target, evt.Target)
+			log = append(log, "knockout")
+			return nil
+		}))
+	assert.NilError(t, cardinal.RegisterSystems(world,
+		func(wCtx cardinal.WorldContext) error {
+			if wCtx.CurrentTick() != 1 {
+				return nil
+			}
+			log = append(log, "emit")
+			assert.NilError(t, cardinal.EmitTickEvent(wCtx, Collision{Target: target, Damage: 3}))
+			assert.NilError(t, cardinal.EmitTickEvent(wCtx, Collision{Target: target, Damage: 7}))
+			// Events without handlers are dropped.
+			return cardinal.EmitTickEvent(wCtx, "unhandled")
+		},
+		func(wCtx cardinal.WorldContext) error {
+			health, err := cardinal.GetComponent[Health](wCtx, target)
+			assert.NilError(t, err)
+			log = append(log, "observe")
+			if wCtx.CurrentTick() == 1 {
+				assert.Equal(t, 0, health.Value)
+			}
+			return nil
+		},
+	))
+	tf.StartWorld()
+
+	var err error
+	target, err = cardinal.Create(cardinal.NewWorldContext(world), Health{Value: 10})
+	assert.NilError(t, err)
+	tf.DoTick()
+	log = nil
+	tf.DoTick()
+	tf.DoTick()
+
+	assert.DeepEqual(t, log, []string{"emit", "collision", "collision", "knockout", "observe", "observe"})
+}
+
+func TestTickEventsCanOnlyBeEmittedBySystems(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterTickEventHandler[Collision](tf.World,
+		func(cardinal.WorldContext, Collision) error { return nil }))
+	tf.StartWorld()
+
+	err := cardinal.EmitTickEvent(cardinal.NewWorldContext(tf.World), Collision{})
+	assert.ErrorContains(t, err, "can only be emitted by running systems")
+}
+
+func TestTickEventsCanBeEmittedFromEachParallel(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[Health](world))
+	collisions := 0
+	assert.NilError(t, cardinal.RegisterTickEventHandler[Collision](world,
+		func(cardinal.WorldContext, Collision) error {
+			collisions++
+			return nil
+		}))
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		search := cardinal.NewSearch().Entity(filter.Contains(filter.Component[Health]()))
+		return cardinal.EachParallel[Health](wCtx, search, 4, func(id types.EntityID, _ *Health) {
+			assert.NilError(t, cardinal.EmitTickEvent(wCtx, Collision{Target: id, Damage: 1}))
+		})
+	}))
+	tf.StartWorld()
+
+	_, err := cardinal.CreateMany(cardinal.NewWorldContext(world), 50, Health{Value: 10})
+	assert.NilError(t, err)
+	tf.DoTick()
+	assert.Equal(t, collisions, 50)
+}
+
+type Respawn struct {
+	Player string
+}
+
+func TestDeferredEventsAreHandledOnTheNextTick(t *testing.T) {
+	tf := cardinal.NewTestFixture(t, nil)
+	var handled []uint64
+	assert.NilError(t, cardinal.RegisterDeferredEventHandler[Respawn](tf.World,
+		func(wCtx cardinal.WorldContext, evt Respawn) error {
+			assert.Equal(t, "alice", evt.Player)
+			handled = append(handled, wCtx.CurrentTick())
+			return nil
+		}))
+	assert.NilError(t, cardinal.RegisterSystems(tf.World, func(wCtx cardinal.WorldContext) error {
+		if wCtx.CurrentTick() == 1 {
+			return cardinal.EmitDeferredEvent(wCtx, Respawn{Player: "alice"})
+		}
+		return nil
+	}))
+	tf.StartWorld()
+
+	for i := 0; i < 4; i++ {
+		tf.DoTick()
+	}
+	assert.DeepEqual(t, handled, []uint64{2})
+}
+
+func TestDeferredEventsSurviveSnapshotAndRestore(t *testing.T) {
+	src := cardinal.NewTestFixture(t, nil)
+	srcHandled := 0
+	assert.NilError(t, cardinal.RegisterDeferredEventHandler[Respawn](src.World,
+		func(cardinal.WorldContext, Respawn) error {
+			srcHandled++
+			return nil
+		}))
+	assert.NilError(t, cardinal.RegisterSystems(src.World, func(wCtx cardinal.WorldContext) error {
+		if wCtx.CurrentTick() == 1 {
+			return cardinal.EmitDeferredEvent(wCtx, Respawn{Player: "alice"})
+		}
+		return nil
+	}))
+	src.StartWorld()
+	src.DoTick()
+	src.DoTick()
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	assert.Equal(t, srcHandled, 0)
+
+	// The restored world never emits the event itself.
+	dst := cardinal.NewTestFixture(t, nil)
+	var dstHandled []Respawn
+	assert.NilError(t, cardinal.RegisterDeferredEventHandler[Respawn](dst.World,
+		func(_ cardinal.WorldContext, evt Respawn) error {
+			dstHandled = append(dstHandled, evt)
+			return nil
+		}))
+	dst.StartWorld()
+	assert.NilError(t, dst.World.Restore(data))
+	for i := 0; i < 3; i++ {
+		dst.DoTick()
+	}
+	assert.DeepEqual(t, dstHandled, []Respawn{{Player: "alice"}})
+}
@@ -0,0 +1,54 @@
+package cardinal
+
+import (
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// txDeduplicator remembers the hashes of the transactions processed in the last windowTicks ticks, so that a
+// transaction that is delivered again within that window can be dropped instead of being processed twice.
+type txDeduplicator struct {
+	windowTicks uint64
+	// seen maps the hash of a transaction to the tick it was processed in.
+	seen map[types.TxHash]uint64
+	// hashesByTick is a ring buffer of the hashes processed in each tick of the window, which are forgotten once their
+	// tick leaves the window. This keeps the size of seen bounded by the transactions of windowTicks ticks.
+	hashesByTick [][]types.TxHash
+}
+
+func newTxDeduplicator(windowTicks uint64) *txDeduplicator {
+	return &txDeduplicator{
+		windowTicks:  windowTicks,
+		seen:         map[types.TxHash]uint64{},
+		hashesByTick: make([][]types.TxHash, windowTicks),
+	}
+}
+
+// removeDuplicates removes the transactions of the given tick's pool that have already been processed within the
+// window, and remembers the others. It must be called once for every tick, in order. The hashes of the duplicates of
+// transactions that were processed in earlier ticks are returned. Repeats of a transaction within the same tick are
+// removed as well, but are not returned, as they share the receipt of the transaction that is processed.
+func (d *txDeduplicator) removeDuplicates(tick uint64, pool *txpool.TxPool) []types.TxHash {
+	slot := tick % d.windowTicks
+	for _, hash := range d.hashesByTick[slot] {
+		if d.seen[hash]+d.windowTicks <= tick {
+			delete(d.seen, hash)
+		}
+	}
+	d.hashesByTick[slot] = d.hashesByTick[slot][:0]
+
+	var duplicates []types.TxHash
+	pool.RemoveFunc(func(tx txpool.TxData) bool {
+		seenTick, ok := d.seen[tx.TxHash]
+		if !ok {
+			d.seen[tx.TxHash] = tick
+			d.hashesByTick[slot] = append(d.hashesByTick[slot], tx.TxHash)
+			return false
+		}
+		if seenTick != tick {
+			duplicates = append(duplicates, tx.TxHash)
+		}
+		return true
+	})
+	return duplicates
+}
@@ -0,0 +1,27 @@
+package cardinal
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/sign"
+)
+
+func TestTxDeduplicatorForgetsHashesOutsideOfTheWindow(t *testing.T) {
+	d := newTxDeduplicator(2)
+	tx := &sign.Transaction{PersonaTag: "alice"}
+	poolWithTx := func() *txpool.TxPool {
+		pool := txpool.New()
+		_, err := pool.AddTransaction(1, nil, tx)
+		assert.NilError(t, err)
+		return pool
+	}
+
+	assert.Equal(t, len(d.removeDuplicates(0, poolWithTx())), 0)
+	assert.Equal(t, len(d.removeDuplicates(1, poolWithTx())), 1)
+	assert.Equal(t, len(d.removeDuplicates(2, txpool.New())), 0)
+	// The transaction was processed in tick 0, which is no longer in the window at tick 2.
+	assert.Equal(t, len(d.seen), 0)
+	assert.Equal(t, len(d.removeDuplicates(3, poolWithTx())), 0)
+}
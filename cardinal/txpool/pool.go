@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/rotisserie/eris"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
@@ -11,6 +12,14 @@ import (
 	"pkg.world.dev/world-engine/sign"
 )
 
+// ErrPoolFull is returned when transactions are added to a pool that holds its maximum number of transactions, unless
+// the pool is set to block until there is room for them.
+var ErrPoolFull = eris.New("transaction pool is full")
+
+// ErrPoolClosed is returned when transactions are added to a pool that has been closed, including to the callers that
+// were waiting for room in a full pool when it was closed.
+var ErrPoolClosed = eris.New("transaction pool is closed")
+
 type TxMap map[types.MessageID][]TxData
 
 type TxData struct {
@@ -27,16 +36,51 @@ type TxPool struct {
 	txsInPool int
 	mux       *sync.Mutex
 	tracer    trace.Tracer
+
+	// maxTxs is the maximum number of transactions the pool holds, or 0 if there is no limit. When the pool is full,
+	// transactions are rejected with ErrPoolFull, or, if blockWhenFull is set, the caller waits on notFull until the
+	// transactions of the pool are copied out for a tick, or until the pool is closed.
+	maxTxs        int
+	blockWhenFull bool
+	notFull       *sync.Cond
+	closed        bool
 }
 
 func New() *TxPool {
+	mux := &sync.Mutex{}
 	return &TxPool{
-		m:      TxMap{},
-		mux:    &sync.Mutex{},
-		tracer: otel.Tracer("txpool"),
+		m:       TxMap{},
+		mux:     mux,
+		tracer:  otel.Tracer("txpool"),
+		notFull: sync.NewCond(mux),
 	}
 }
 
+// SetMaxTxs sets the maximum number of transactions the pool holds. Zero or less means there is no limit.
+func (t *TxPool) SetMaxTxs(maxTxs int) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.maxTxs = maxTxs
+}
+
+// SetBlockWhenFull sets whether adding transactions to a full pool waits until there is room for them, instead of
+// returning ErrPoolFull.
+func (t *TxPool) SetBlockWhenFull(block bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.blockWhenFull = block
+}
+
+// Close stops the pool from accepting transactions with AddTransaction, AddEVMTransaction and AddTransactions, and
+// wakes up the callers waiting for room in a full pool. They all return ErrPoolClosed from then on. The transactions
+// that are already in the pool can still be copied out.
+func (t *TxPool) Close() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.closed = true
+	t.notFull.Broadcast()
+}
+
 func (t *TxPool) GetAmountOfTxs() int {
 	return t.txsInPool
 }
@@ -59,17 +103,24 @@ func (t *TxPool) GetEVMTxs() []TxData {
 	return transactions
 }
 
-func (t *TxPool) AddTransaction(id types.MessageID, v any, sig *sign.Transaction) types.TxHash {
+func (t *TxPool) AddTransaction(id types.MessageID, v any, sig *sign.Transaction) (types.TxHash, error) {
 	return t.addTransaction(id, v, sig, "")
 }
 
-func (t *TxPool) AddEVMTransaction(id types.MessageID, v any, sig *sign.Transaction, evmTxHash string) types.TxHash {
+func (t *TxPool) AddEVMTransaction(
+	id types.MessageID, v any, sig *sign.Transaction, evmTxHash string,
+) (types.TxHash, error) {
 	return t.addTransaction(id, v, sig, evmTxHash)
 }
 
-func (t *TxPool) addTransaction(id types.MessageID, v any, sig *sign.Transaction, evmTxHash string) types.TxHash {
+func (t *TxPool) addTransaction(
+	id types.MessageID, v any, sig *sign.Transaction, evmTxHash string,
+) (types.TxHash, error) {
 	t.mux.Lock()
 	defer t.mux.Unlock()
+	if err := t.waitForRoom(1); err != nil {
+		return "", err
+	}
 	txHash := types.TxHash(sig.HashHex())
 	t.m[id] = append(t.m[id], TxData{
 		MsgID:           id,
@@ -79,7 +130,104 @@ func (t *TxPool) addTransaction(id types.MessageID, v any, sig *sign.Transaction
 		EVMSourceTxHash: evmTxHash,
 	})
 	t.txsInPool++
-	return txHash
+	return txHash, nil
+}
+
+// AddTransactions adds all the given transactions to the pool at once, so that either all or none of them are part of
+// the next copy of the pool. The TxHash of each transaction is derived from its signed transaction, like in
+// AddTransaction. The hashes are returned in the same order as the given transactions.
+func (t *TxPool) AddTransactions(txs []TxData) ([]types.TxHash, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if err := t.waitForRoom(len(txs)); err != nil {
+		return nil, err
+	}
+	return t.addTransactions(txs), nil
+}
+
+// AddTransactionsIgnoringLimit adds all the given transactions to the pool at once like AddTransactions, even if that
+// takes the pool over its maximum number of transactions. This is meant for transactions that must not be dropped,
+// like those of a tick that is being recovered.
+func (t *TxPool) AddTransactionsIgnoringLimit(txs []TxData) []types.TxHash {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.addTransactions(txs)
+}
+
+// AddTransactionsFirst adds all the given transactions to the pool like AddTransactionsIgnoringLimit, but ahead of the
+// transactions of the same message types that are already in the pool, so that they are processed before them.
+func (t *TxPool) AddTransactionsFirst(txs []TxData) []types.TxHash {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	first := TxMap{}
+	hashes := make([]types.TxHash, 0, len(txs))
+	for _, tx := range txs {
+		tx.TxHash = types.TxHash(tx.Tx.HashHex())
+		first[tx.MsgID] = append(first[tx.MsgID], tx)
+		hashes = append(hashes, tx.TxHash)
+	}
+	for id, firstTxs := range first {
+		t.m[id] = append(firstTxs, t.m[id]...)
+	}
+	t.txsInPool += len(txs)
+	return hashes
+}
+
+func (t *TxPool) addTransactions(txs []TxData) []types.TxHash {
+	hashes := make([]types.TxHash, 0, len(txs))
+	for _, tx := range txs {
+		tx.TxHash = types.TxHash(tx.Tx.HashHex())
+		t.m[tx.MsgID] = append(t.m[tx.MsgID], tx)
+		t.txsInPool++
+		hashes = append(hashes, tx.TxHash)
+	}
+	return hashes
+}
+
+// waitForRoom returns nil once n more transactions fit in the pool. If the pool is full and does not block, or can
+// never hold n transactions, ErrPoolFull is returned instead. If the pool is or gets closed, ErrPoolClosed is returned.
+// The mutex of the pool must be held.
+func (t *TxPool) waitForRoom(n int) error {
+	if t.closed {
+		return eris.Wrap(ErrPoolClosed, "")
+	}
+	if t.maxTxs <= 0 {
+		return nil
+	}
+	if n > t.maxTxs {
+		return eris.Wrapf(ErrPoolFull, "%d transactions exceed the limit of %d transactions", n, t.maxTxs)
+	}
+	for t.txsInPool+n > t.maxTxs {
+		if !t.blockWhenFull {
+			return eris.Wrapf(ErrPoolFull, "the pool holds its limit of %d transactions", t.maxTxs)
+		}
+		t.notFull.Wait()
+		if t.closed {
+			return eris.Wrap(ErrPoolClosed, "")
+		}
+	}
+	return nil
+}
+
+// RemoveFunc removes the transactions for which remove returns true from the pool, and returns them. The transactions
+// are visited one message type at a time, in the order they were added.
+func (t *TxPool) RemoveFunc(remove func(tx TxData) bool) []TxData {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	var removed []TxData
+	for id, txs := range t.m {
+		kept := txs[:0]
+		for _, tx := range txs {
+			if remove(tx) {
+				removed = append(removed, tx)
+				continue
+			}
+			kept = append(kept, tx)
+		}
+		t.m[id] = kept
+	}
+	t.txsInPool -= len(removed)
+	return removed
 }
 
 func (t *TxPool) Transactions() TxMap {
@@ -96,6 +244,8 @@ func (t *TxPool) CopyTransactions(ctx context.Context) *TxPool {
 
 	cpy := *t
 	t.reset()
+	// The pool is empty again, so submitters waiting for room can go ahead.
+	t.notFull.Broadcast()
 
 	return &cpy
 }
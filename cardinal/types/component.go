@@ -27,8 +27,13 @@ type ComponentMetadata interface { //revive:disable-line:exported
 	ID() ComponentID
 	// New returns the marshaled bytes of the default value for the component struct.
 	New() ([]byte, error)
+	// Default returns the default value for the component struct, or false if it has no default value.
+	Default() (Component, bool)
 	Encode(any) ([]byte, error)
 	Decode([]byte) (Component, error)
+	// HasCustomCodec reports whether Encode and Decode use a custom codec, in which case the encoded bytes are not
+	// necessarily JSON.
+	HasCustomCodec() bool
 	GetSchema() []byte
 	ValidateAgainstSchema(targetSchema []byte) error
 
@@ -2,6 +2,9 @@ package types
 
 import "encoding/json"
 
+// EntityID identifies an entity. IDs are assigned from an ever-increasing counter that is persisted with the game state
+// and are never reused after an entity is removed, so an ID held past the removal of its entity can't refer to a
+// different entity. Using it returns an error that wraps ErrEntityDoesNotExist instead.
 type EntityID uint64
 
 type EntityStateElement struct {
@@ -3,3 +3,11 @@ package types
 import "github.com/rotisserie/eris"
 
 var ErrQueryNotFound = eris.New("query not found")
+
+// ErrSignatureRejected is returned when the signature verifier of a world does not accept the signature of a submitted
+// transaction.
+var ErrSignatureRejected = eris.New("transaction signature was rejected")
+
+// ErrNonceNotIncreasing is returned when a world with nonce protection rejects a submitted transaction because its
+// nonce is not greater than the last nonce its persona tag used.
+var ErrNonceNotIncreasing = eris.New("nonce is not greater than the last nonce used")
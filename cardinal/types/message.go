@@ -21,6 +21,10 @@ type Message interface {
 
 	// GetInFieldInformation returns a map of the fields of the message's "In" type and it's field types.
 	GetInFieldInformation() map[string]any
+	// GetInSchema returns the JSON schema of the message's "In" type.
+	GetInSchema() ([]byte, error)
+	// GetOutSchema returns the JSON schema of the message's "Out" type.
+	GetOutSchema() ([]byte, error)
 }
 
 // MessageID represents a message's id.
@@ -3,6 +3,7 @@ package cardinal
 import (
 	"github.com/rotisserie/eris"
 
+	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/router"
 	"pkg.world.dev/world-engine/cardinal/server"
 )
@@ -14,13 +15,15 @@ var NonFatalError = []error{
 	ErrEntityMustHaveAtLeastOneComponent,
 }
 
-// separateOptions separates the given options into ecs options, server options, and cardinal (this package) options.
+// separateOptions separates the given options into server options, router options, cardinal (this package) options,
+// and gamestate options.
 // The different options are all grouped together to simplify the end user's experience, but under the hood different
 // options are meant for different sub-systems.
 func separateOptions(opts []WorldOption) (
 	serverOptions []server.Option,
 	routerOptions []router.Option,
 	cardinalOptions []Option,
+	gamestateOptions []gamestate.Option,
 ) {
 	for _, opt := range opts {
 		if opt.serverOption != nil {
@@ -32,13 +35,20 @@ func separateOptions(opts []WorldOption) (
 		if opt.cardinalOption != nil {
 			cardinalOptions = append(cardinalOptions, opt.cardinalOption)
 		}
+		if opt.gamestateOption != nil {
+			gamestateOptions = append(gamestateOptions, opt.gamestateOption)
+		}
 	}
-	return serverOptions, routerOptions, cardinalOptions
+	return serverOptions, routerOptions, cardinalOptions, gamestateOptions
 }
 
 // panicOnFatalError is a helper function to panic on non-deterministic errors (i.e. Redis error).
 func panicOnFatalError(wCtx WorldContext, err error) {
 	if err != nil && !wCtx.isReadOnly() && isFatalError(err) {
+		if w := wCtx.getWorld(); w != nil {
+			// Panics caused by fatal errors must not be recovered by the system runner.
+			w.fatalPanic.Store(true)
+		}
 		wCtx.Logger().Panic().Err(err).Msgf("fatal error: %v", eris.ToString(err, true))
 		panic(err)
 	}
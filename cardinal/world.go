@@ -1,11 +1,15 @@
 package cardinal
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"os"
 	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -38,11 +42,26 @@ import (
 const (
 	DefaultHistoricalTicksToStore = 10
 	RedisDialTimeOut              = 150
+
+	// ShutdownHookTimeout is how long a single shutdown hook may run before its context is canceled.
+	ShutdownHookTimeout = 5 * time.Second
+	// ShutdownHooksTimeout is how long all shutdown hooks together may run before their contexts are canceled.
+	ShutdownHooksTimeout = 30 * time.Second
 )
 
 var _ router.Provider = &World{}           //nolint:exhaustruct
 var _ servertypes.ProviderWorld = &World{} //nolint:exhaustruct
 
+// PreTickHook is a function that is run before each tick and returns messages to process in that tick. See
+// WithPreTickHook.
+type PreTickHook func(tick uint64) []PendingMessage
+
+// PostTickHook is a function that is run after each tick. See WithPostTickHook.
+type PostTickHook func(tick uint64, timestamp uint64, receipts []receipt.Receipt) error
+
+// ShutdownHook is a function that is run when the world shuts down. See WithShutdownHook.
+type ShutdownHook func(ctx context.Context) error
+
 type World struct {
 	SystemManager
 	MessageManager
@@ -56,6 +75,9 @@ type World struct {
 	// Storage
 	redisStorage *redis.Storage
 	entityStore  gamestate.Manager
+	// stateLock is held exclusively while the systems of a tick run and their changes are committed, and shared by
+	// read-only queries (see EachReadOnly), which can then run concurrently with each other but not with a tick.
+	stateLock sync.RWMutex
 
 	// Networking
 	server        *server.Server
@@ -65,14 +87,64 @@ type World struct {
 	worldStage *worldstage.Manager
 	router     router.Router
 	txPool     *txpool.TxPool
+	// txDedup, if set, drops transactions that were already processed in a recent tick.
+	txDedup *txDeduplicator
+	// signatureVerifier, if set, rejects transactions whose signature it does not accept.
+	signatureVerifier SignatureVerifier
+	// nonceProtection is set if submitted transactions must have a nonce greater than the last one of their persona.
+	nonceProtection bool
+	// spatialIndexes are the indexes registered with RegisterSpatialIndex, keyed by component name.
+	spatialIndexes spatialIndexes
+	// componentRemovalHooks are the hooks registered with RegisterComponentRemovalHook, keyed by component name.
+	componentRemovalHooks componentRemovalHooks
+	// tickEvents passes the events emitted with EmitTickEvent to the handlers registered with RegisterTickEventHandler.
+	tickEvents *tickEventBus
+	// tags are the tags registered with RegisterTag, mapped to their bit in the tags of entities.
+	tags map[string]int
+	// messageHandlers are the handlers registered with RegisterMessageHandler, in the order they run in.
+	messageHandlers []messageHandler
+	// tickBudget, if set, carries the messages that are not processed within the budget of a tick over to the next.
+	tickBudget *tickBudget
+
+	// archetypeCaches are the archetypes matched by the filters of searches, keyed by filter key and by whether they
+	// include the archetypes still pending in the tick. They are shared by all searches with the same filter key.
+	archetypeCaches   map[archetypeCacheKey]*cache
+	archetypeCachesMu sync.Mutex
 
 	// Receipt
 	receiptHistory *receipt.History
+	receiptSink    receipt.Sink
 	evmTxReceipts  map[string]EVMTxReceipt
 
 	// Telemetry
 	telemetry *telemetry.Manager
 	tracer    trace.Tracer // Tracer for World
+	metrics   MetricsRecorder
+	// logger is the logger of the world and its world contexts. It points to the global logger unless WithLogger is
+	// used, so that the global logger can still be replaced after the world is created.
+	logger *zerolog.Logger
+
+	// preTickHooks are run in order of registration before each tick, except while recovering.
+	preTickHooks []PreTickHook
+	// postTickHooks are run in order of registration after each tick. If strictPostTickHooks is set, an error returned
+	// by a hook fails the tick.
+	postTickHooks       []PostTickHook
+	strictPostTickHooks bool
+	// stableIteration makes searches visit entities in order of their IDs. See WithStableIteration.
+	stableIteration bool
+	// strictSystemPanics makes a panicking system crash the game loop instead of being skipped for the tick. See
+	// WithStrictSystemPanics.
+	strictSystemPanics bool
+	// seed is the seed set with WithSeed, from which the random number generator of each tick is derived. It is nil if
+	// the generator is seeded with the timestamp of the tick instead.
+	seed *int64
+	// randTickOffset is added to the tick number when deriving the random number generator of a tick from the seed, so
+	// that a world restored from a snapshot continues with the generators of the world the snapshot was taken from.
+	randTickOffset uint64
+	// fatalPanic is set right before a panic caused by a fatal error, which is never recovered.
+	fatalPanic *atomic.Bool
+	// shutdownHooks are run in reverse order of registration when the world shuts down.
+	shutdownHooks []ShutdownHook
 
 	// Tick
 	tick            *atomic.Uint64
@@ -80,13 +152,25 @@ type World struct {
 	tickResults     *TickResults
 	tickChannel     <-chan time.Time
 	tickDoneChannel chan<- uint64
+	// tickRate is the number of ticks per second, from the CARDINAL_TICK_RATE config or WithTickRate. It is used to
+	// make the tickChannel unless one is set with WithTickChannel. Zero means one tick per second.
+	tickRate uint64
+	// lastTickAt is the wall-clock time in Unix nanoseconds at which the last tick completed, or at which the game
+	// started running if no tick has completed since.
+	lastTickAt *atomic.Int64
+	// healthStallThreshold is how long the tick loop may go without completing a tick before Health reports it as
+	// stalled.
+	healthStallThreshold time.Duration
+	// isReplaying is true while Replay is running ticks. Like during recovery, those ticks are not submitted to the
+	// base shard and their results are not broadcast.
+	isReplaying *atomic.Bool
 	// addChannelWaitingForNextTick accepts a channel which will be closed after a tick has been completed.
 	addChannelWaitingForNextTick chan chan struct{}
 }
 
 // NewWorld creates a new World object using Redis as the storage layer
 func NewWorld(opts ...WorldOption) (*World, error) {
-	serverOptions, routerOptions, cardinalOptions := separateOptions(opts)
+	serverOptions, routerOptions, cardinalOptions, gamestateOptions := separateOptions(opts)
 
 	// Load config. Fallback value is used if it's not set.
 	cfg, err := loadWorldConfig()
@@ -118,7 +202,7 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 	}, cfg.CardinalNamespace)
 
 	redisStore := gamestate.NewRedisPrimitiveStorage(redisMetaStore.Client)
-	entityCommandBuffer, err := gamestate.NewEntityCommandBuffer(&redisStore)
+	entityCommandBuffer, err := gamestate.NewEntityCommandBuffer(&redisStore, gamestateOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +216,7 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		// Storage
 		redisStorage: &redisMetaStore,
 		entityStore:  entityCommandBuffer,
+		stateLock:    sync.RWMutex{},
 
 		// Networking
 		server:        nil, // Will be initialized in StartGame
@@ -145,21 +230,50 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		QueryManager:     nil,
 		router:           nil, // Will be set if run mode is production or its injected via options
 		txPool:           txpool.New(),
+		txDedup:          nil, // Will be set if the WithTxDedup option is used
+		// Will be set if the WithSignatureVerifier option is used
+		signatureVerifier:     nil,
+		nonceProtection:       false,
+		spatialIndexes:        spatialIndexes{},
+		componentRemovalHooks: componentRemovalHooks{},
+		tickEvents:            newTickEventBus(),
+		tags:                  map[string]int{},
+		messageHandlers:       nil, // Will be set if RegisterMessageHandler is used
+		tickBudget:            nil, // Will be set if the WithTickBudget option is used
+		archetypeCaches:       map[archetypeCacheKey]*cache{},
+		archetypeCachesMu:     sync.Mutex{},
 
 		// Receipt
 		receiptHistory: receipt.NewHistory(tick.Load(), DefaultHistoricalTicksToStore),
+		receiptSink:    nil, // Will be set if the WithReceiptSink option is used
 		evmTxReceipts:  make(map[string]EVMTxReceipt),
 
 		// Telemetry
 		telemetry: tm,
 		tracer:    otel.Tracer("world"),
+		metrics:   nil, // Will be set if the WithMetricsRecorder option is used
+		logger:    &log.Logger,
+
+		preTickHooks:        nil, // Will be set if the WithPreTickHook option is used
+		postTickHooks:       nil, // Will be set if the WithPostTickHook option is used
+		strictPostTickHooks: false,
+		stableIteration:     false,
+		strictSystemPanics:  false,
+		seed:                nil,
+		randTickOffset:      0,
+		fatalPanic:          &atomic.Bool{},
+		shutdownHooks:       nil, // Will be set if the WithShutdownHook option is used
 
 		// Tick
 		tick:                         tick,
 		timestamp:                    new(atomic.Uint64),
 		tickResults:                  NewTickResults(tick.Load()),
-		tickChannel:                  time.Tick(time.Second), //nolint:staticcheck // its ok.
-		tickDoneChannel:              nil,                    // Will be injected via options
+		tickChannel:                  nil, // Will be made from the tick rate unless injected via options
+		tickDoneChannel:              nil, // Will be injected via options
+		tickRate:                     cfg.CardinalTickRate,
+		lastTickAt:                   new(atomic.Int64),
+		healthStallThreshold:         DefaultHealthStallThreshold,
+		isReplaying:                  new(atomic.Bool),
 		addChannelWaitingForNextTick: make(chan chan struct{}),
 	}
 
@@ -179,20 +293,27 @@ func NewWorld(opts ...WorldOption) (*World, error) {
 		}
 	}
 
-	// Set tick rate if provided
-	// it will be overridden by WithTickChannel option if provided
-	if cfg.CardinalTickRate > 0 {
-		world.tickChannel = time.Tick(time.Second / time.Duration(cfg.CardinalTickRate)) //nolint:staticcheck // its ok.
-	}
-
 	// Apply options
 	for _, opt := range cardinalOptions {
 		opt(world)
 	}
 
+	// Tick at the configured tick rate, unless a tick channel was injected with WithTickChannel
+	if world.tickChannel == nil {
+		interval := time.Second
+		if world.tickRate > 0 {
+			interval = time.Second / time.Duration(world.tickRate) //nolint:gosec // a tick rate never overflows
+		}
+		world.tickChannel = time.Tick(interval) //nolint:staticcheck // its ok.
+	}
+
 	// Register internal plugins
 	world.RegisterPlugin(newPersonaPlugin())
 	world.RegisterPlugin(newFutureTaskPlugin())
+	world.RegisterPlugin(newMessageSchedulerPlugin())
+	world.RegisterPlugin(newTickBudgetPlugin())
+	world.RegisterPlugin(newDeferredEventPlugin())
+	world.RegisterPlugin(newTagPlugin())
 
 	return world, nil
 }
@@ -226,24 +347,37 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	// current system that is running.
 	defer w.handleTickPanic()
 
+	if w.metrics != nil {
+		w.metrics.SetQueueDepth(w.txPool.GetAmountOfTxs())
+	}
+
 	// Copy the transactions from the pool so that we can safely modify the pool while the tick is running.
 	txPool := w.txPool.CopyTransactions(ctx)
 
+	if w.txDedup != nil {
+		for _, hash := range w.txDedup.removeDuplicates(w.CurrentTick(), txPool) {
+			w.receiptHistory.MarkDuplicate(hash)
+		}
+	}
+
+	// Messages generated by the world are only injected into live ticks; recovered ticks already include them. The pre
+	// tick hooks run before the state is locked, so that they can query it.
+	var preTickMsgs []PendingMessage
+	if !w.isRecovering() {
+		preTickMsgs = w.preTickHookMessages(w.CurrentTick())
+	}
+
 	// Store the timestamp for this tick
 	w.timestamp.Store(timestamp)
 
-	// Create the engine context to inject into systems
-	wCtx := newWorldContextForTick(w, txPool)
-
-	// Run all registered systems.
-	// This will run the registered init systems if the current tick is 0
-	if err := w.SystemManager.runSystems(ctx, wCtx); err != nil {
-		span.SetStatus(codes.Error, eris.ToString(err, true))
-		span.RecordError(err)
-		return err
+	// Recovered ticks must process all their messages, as they did when they were first run, and so must the final
+	// tick that is run while shutting down.
+	if w.tickBudget != nil {
+		w.tickBudget.start(startTime, !w.isRecovering() && w.worldStage.Current() != worldstage.ShuttingDown)
+		defer w.tickBudget.stop()
 	}
 
-	if err := w.entityStore.FinalizeTick(ctx); err != nil {
+	if err := w.runSystemsAndFinalize(ctx, txPool, timestamp, preTickMsgs); err != nil {
 		span.SetStatus(codes.Error, eris.ToString(err, true))
 		span.RecordError(err)
 		return err
@@ -255,7 +389,7 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	// Only submit transactions when the following criteria is satisfied:
 	// 1. The shard router is set
 	// 2. The world is not in the recovering stage (we don't want to resubmit past transactions)
-	if w.router != nil && w.worldStage.Current() != worldstage.Recovering {
+	if w.router != nil && !w.isRecovering() {
 		err := w.router.SubmitTxBlob(ctx, txPool.Transactions(), w.tick.Load(), w.timestamp.Load())
 		if err != nil {
 			span.SetStatus(codes.Error, eris.ToString(err, true))
@@ -266,14 +400,22 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 
 	// Increment the tick
 	w.tick.Add(1)
+	w.lastTickAt.Store(time.Now().UnixNano())
 	w.receiptHistory.NextTick() // todo(scott): use channels
 
-	if w.worldStage.Current() != worldstage.Recovering {
+	// Strict post tick hooks have already run before the changes of the tick were committed.
+	if !w.strictPostTickHooks {
+		w.runPostTickHooksAfterCommit(w.tick.Load()-1, w.timestamp.Load())
+	}
+
+	if !w.isRecovering() {
 		// Populate world.TickResults for the current tick and emit it as an Event
 		w.broadcastTickResults(ctx)
 	}
 
-	log.Info().
+	w.recordTickMetrics(time.Since(startTime), txPool.GetAmountOfTxs())
+
+	w.logger.Info().
 		Int64("tick", int64(w.CurrentTick()-1)).
 		Str("duration", time.Since(startTime).String()).
 		Int("tx_count", txPool.GetAmountOfTxs()).
@@ -282,6 +424,106 @@ func (w *World) doTick(ctx context.Context, timestamp uint64) (err error) {
 	return nil
 }
 
+// runSystemsAndFinalize injects the messages deferred in the previous tick, the scheduled messages that are due and the
+// given messages of the pre tick hooks into the pool of a tick, runs the systems of the tick and commits their changes.
+// The state lock is held throughout, so that read-only queries never observe the state of a tick that is still in
+// progress.
+func (w *World) runSystemsAndFinalize(
+	ctx context.Context, txPool *txpool.TxPool, timestamp uint64, preTickMsgs []PendingMessage,
+) error {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+
+	// The spatial indexes only follow the changes of the tick once they are committed, so their pending changes are
+	// dropped if the tick fails or panics.
+	committed := false
+	defer func() {
+		if !committed {
+			w.spatialIndexes.discard()
+		}
+	}()
+
+	// Changes to components are tracked per tick
+	w.entityStore.ClearDirtyComponents()
+
+	// Create the engine context to inject into systems
+	wCtx := newWorldContextForTick(w, txPool)
+
+	// Messages that did not fit in the budget of the previous tick are processed first. Like scheduled messages, they
+	// are taken out of the state even while recovering, as recovered ticks already include them.
+	if err := w.takeDeferredMessages(wCtx, txPool, !w.isRecovering()); err != nil {
+		return err
+	}
+
+	// Messages generated by the world are injected after the submitted transactions have been checked, as they are
+	// neither signed nor resubmitted. Scheduled messages that are due are taken out of the state even while recovering,
+	// so they don't fire twice.
+	salts, err := w.injectScheduledMessages(wCtx, txPool, w.CurrentTick(), timestamp, !w.isRecovering())
+	if err != nil {
+		return err
+	}
+	w.injectPreTickHookMessages(txPool, w.CurrentTick(), timestamp, preTickMsgs, salts)
+
+	// Run all registered systems.
+	// This will run the registered init systems if the current tick is 0
+	if err := w.SystemManager.runSystems(ctx, wCtx); err != nil {
+		return err
+	}
+
+	// Messages deferred to the next tick are committed along with the other changes of the tick.
+	if err := w.storeDeferredMessages(wCtx); err != nil {
+		return err
+	}
+
+	// The receipts of the tick are final once all systems have run. A strict post tick hook can still fail the tick,
+	// so strict hooks run before its changes are committed.
+	if w.strictPostTickHooks {
+		receipts := w.receiptHistory.GetReceiptsForCurrentTick()
+		if err := w.runPostTickHooks(w.CurrentTick(), w.timestamp.Load(), receipts); err != nil {
+			return err
+		}
+	}
+
+	if err := w.entityStore.FinalizeTick(ctx); err != nil {
+		return err
+	}
+	w.spatialIndexes.commit()
+	committed = true
+	return nil
+}
+
+// runPostTickHooksAfterCommit runs the post tick hooks with the receipts of the given tick, whose changes have already
+// been committed. The tick can't fail anymore, so errors are only logged.
+func (w *World) runPostTickHooksAfterCommit(tick, timestamp uint64) {
+	if len(w.postTickHooks) == 0 {
+		return
+	}
+	receipts, err := w.receiptHistory.GetReceiptsForTick(tick)
+	if errors.Is(err, receipt.ErrOldTickHasBeenDiscarded) {
+		// A receipt history that only stores a single tick has already discarded the receipts of the completed tick.
+		receipts = nil
+	} else if err != nil {
+		w.logger.Error().Err(err).Msgf("Failed to get the receipts of tick %d for the post tick hooks", tick)
+		return
+	}
+	// Errors are returned only if strict post tick hooks are enabled, and those don't run here.
+	_ = w.runPostTickHooks(tick, timestamp, receipts)
+}
+
+// runPostTickHooks runs the post tick hooks with the given receipts of the given tick. An error returned by a hook is
+// logged, unless strict post tick hooks are enabled, in which case it is returned.
+func (w *World) runPostTickHooks(tick, timestamp uint64, receipts []receipt.Receipt) error {
+	for i, hook := range w.postTickHooks {
+		if err := hook(tick, timestamp, receipts); err != nil {
+			if w.strictPostTickHooks {
+				return eris.Wrapf(err, "post tick hook %d failed at tick %d", i, tick)
+			}
+			w.logger.Error().Err(err).Msgf("Post tick hook %d failed at tick %d", i, tick)
+		}
+	}
+	return nil
+}
+
 // StartGame starts running the world game loop. Each time a message arrives on the tickChannel, a world tick is
 // attempted. In addition, an HTTP server (listening on the given port) is created so that game messages can be sent
 // to this world. After StartGame is called, RegisterComponent, registerMessagesByName,
@@ -291,14 +533,21 @@ func (w *World) StartGame() error {
 	defer w.cleanup()
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	w.cancel = cancel
 
 	// Handles SIGINT and SIGTERM signals and starts the shutdown process.
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
 	go func() {
-		<-sigCh
-		w.Shutdown()
+		select {
+		case <-sigCh:
+			if err := w.Shutdown(context.Background()); err != nil {
+				w.logger.Error().Err(err).Msg("Failed to shut down cardinal")
+			}
+		case <-ctx.Done():
+		}
 	}()
 
 	// World stage: Init -> Starting
@@ -313,8 +562,11 @@ func (w *World) StartGame() error {
 		return eris.Wrap(err, "failed to register components")
 	}
 
+	// Systems are ordered once here so that the order does not need to be computed on every tick.
+	w.SystemManager.sortSystemsByPriority()
+
 	// Log world info
-	ecslog.World(&log.Logger, w, zerolog.InfoLevel)
+	ecslog.World(w.logger, w, zerolog.InfoLevel)
 
 	// Start router if it is set
 	if w.router != nil {
@@ -333,6 +585,11 @@ func (w *World) StartGame() error {
 	}
 	w.tick.Store(tick)
 
+	// Spatial indexes are populated from the loaded state, and are kept up to date by the ticks from here on.
+	if err := w.spatialIndexes.rebuild(w); err != nil {
+		return err
+	}
+
 	// If Cardinal is in rollup mode and router is set, recover any old state of Cardinal from base shard.
 	if w.rollupEnabled && w.router != nil {
 		if err := w.recoverFromChain(ctx); err != nil {
@@ -345,7 +602,19 @@ func (w *World) StartGame() error {
 	//  receiptHistory tick separately.
 	w.receiptHistory.SetTick(w.CurrentTick())
 
+	// Flush receipts that age out of the receipt history to the receipt sink, if one is set.
+	if w.receiptSink != nil {
+		flusher := receipt.NewFlusher(w.receiptSink)
+		w.receiptHistory.SetEvictionHandler(flusher.Enqueue)
+		defer func() {
+			// Ticks run after the game loop stops, e.g. by tests, must not enqueue to the closed flusher.
+			w.receiptHistory.SetEvictionHandler(nil)
+			flusher.Close()
+		}()
+	}
+
 	// World stage: Ready -> Running
+	w.lastTickAt.Store(time.Now().UnixNano())
 	w.worldStage.Store(worldstage.Running)
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -367,14 +636,25 @@ func (w *World) StartGame() error {
 }
 
 func (w *World) startGameLoop(ctx context.Context, tickStart <-chan time.Time, tickDone chan<- uint64) error {
-	log.Info().Msg("Game loop started")
+	w.logger.Info().Msg("Game loop started")
 	var waitingChs []chan struct{}
 
 loop:
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("Shutting down game loop")
+			w.logger.Info().Msg("Shutting down game loop")
+			// No more messages are accepted, including from submitters that are waiting for room in a full queue.
+			w.txPool.Close()
+			// Messages that were accepted before the shutdown, or deferred in the last tick, are processed in one
+			// final tick. Nothing may be reading tickDone anymore, so the final tick is not reported on it.
+			hasDeferred, err := w.hasDeferredMessages()
+			if err != nil {
+				w.logger.Err(err).Msg("Failed to check for deferred messages")
+			}
+			if w.txPool.GetAmountOfTxs() > 0 || hasDeferred {
+				w.tickTheEngine(context.Background(), nil)
+			}
 			w.drainChannelsWaitingForNextTick()
 			closeAllChannels(waitingChs)
 			if tickDone != nil {
@@ -396,7 +676,7 @@ loop:
 		}
 	}
 
-	log.Info().Msg("Successfully shut down game loop")
+	w.logger.Info().Msg("Successfully shut down game loop")
 	return nil
 }
 
@@ -418,43 +698,94 @@ func (w *World) tickTheEngine(ctx context.Context, tickDone chan<- uint64) {
 	}
 }
 
+// isRecovering returns true if the world is running ticks from historical transactions, either because it is
+// recovering from the base shard or because Replay was called.
+func (w *World) isRecovering() bool {
+	return w.worldStage.Current() == worldstage.Recovering || w.isReplaying.Load()
+}
+
 func (w *World) IsGameRunning() bool {
 	return w.worldStage.Current() == worldstage.Running
 }
 
-// Shutdown will trigger a graceful shutdown of the World.
-func (w *World) Shutdown() {
+// StartGameAsync starts the game like StartGame, but returns as soon as the world is running instead of blocking. The
+// returned channel receives the error StartGame terminates with (nil after a clean Shutdown) and is then closed. If
+// the game fails to start, the error is returned directly.
+func (w *World) StartGameAsync() (<-chan error, error) {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- w.StartGame()
+		close(errCh)
+	}()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			err = eris.New("game stopped before it was running")
+		}
+		return nil, err
+	case <-w.worldStage.NotifyOnStage(worldstage.Running):
+		return errCh, nil
+	}
+}
+
+// Shutdown gracefully shuts down the World. The tick in progress is finished, and messages that are still waiting in
+// the transaction pool are processed in one final tick before the game loop and the server stop. Shutdown blocks until
+// the world is shut down or ctx is done, in which case ctx.Err() is returned and the shutdown continues in the
+// background.
+func (w *World) Shutdown(ctx context.Context) error {
 	if w.worldStage.Current() == worldstage.ShutDown || w.worldStage.Current() == worldstage.ShuttingDown {
-		log.Warn().Msgf("Cardinal is already %s, ignoring shutdown request", w.worldStage.Current())
-		return
+		w.logger.Warn().Msgf("Cardinal is already %s, ignoring shutdown request", w.worldStage.Current())
+		return nil
 	}
 
-	log.Info().Msg("Shutting down cardinal")
+	w.logger.Info().Msg("Shutting down cardinal")
 	w.worldStage.Store(worldstage.ShuttingDown)
 
 	// Cancel the context used for server and game loop, therefore triggering their shutdown.
 	w.cancel()
-	<-w.worldStage.NotifyOnStage(worldstage.ShutDown)
+	select {
+	case <-w.worldStage.NotifyOnStage(worldstage.ShutDown):
+	case <-ctx.Done():
+		return eris.Wrap(ctx.Err(), "timed out waiting for cardinal to shut down")
+	}
 
-	log.Info().Msg("Successfully shut down cardinal")
+	w.logger.Info().Msg("Successfully shut down cardinal")
+	return nil
 }
 
 // cleanup is called after StartGame terminates. It does the housekeeping required to cleanly shutdown World.
 func (w *World) cleanup() {
+	w.runShutdownHooks()
 	if err := w.redisStorage.Close(); err != nil {
-		log.Error().Err(err).Msg("Failed to close storage connection")
+		w.logger.Error().Err(err).Msg("Failed to close storage connection")
 	}
 	if w.telemetry != nil {
 		if err := w.telemetry.Shutdown(); err != nil {
-			log.Error().Err(err).Msg("Failed to shut down telemetry")
+			w.logger.Error().Err(err).Msg("Failed to shut down telemetry")
 		}
 	}
 	w.worldStage.Store(worldstage.ShutDown)
 }
 
+// runShutdownHooks runs the shutdown hooks in reverse order of registration, so that hooks registered later (which may
+// depend on resources set up by earlier ones) run first. Each hook gets a context that is canceled after
+// ShutdownHookTimeout, or when ShutdownHooksTimeout has passed since the first hook started. An error returned by a
+// hook is logged and does not prevent the remaining hooks from running.
+func (w *World) runShutdownHooks() {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownHooksTimeout)
+	defer cancel()
+	for i := len(w.shutdownHooks) - 1; i >= 0; i-- {
+		hookCtx, hookCancel := context.WithTimeout(ctx, ShutdownHookTimeout)
+		if err := w.shutdownHooks[i](hookCtx); err != nil {
+			w.logger.Error().Err(err).Msgf("Shutdown hook %d failed", i)
+		}
+		hookCancel()
+	}
+}
+
 func (w *World) handleTickPanic() {
 	if r := recover(); r != nil {
-		log.Error().Msgf(
+		w.logger.Error().Msgf(
 			"Tick: %d, Current running system: %s",
 			w.CurrentTick(),
 			w.SystemManager.GetCurrentSystem(),
@@ -463,6 +794,19 @@ func (w *World) handleTickPanic() {
 	}
 }
 
+// AddSystem adds a system to the end of the systems that are run every tick, including after the world has started.
+// The system starts running on the tick after it is added. Systems cannot be added while the systems of a tick are
+// running (e.g. from inside a system), in which case an error that wraps ErrSystemAddedDuringTick is returned.
+func (w *World) AddSystem(name string, sys System) error {
+	if stage := w.worldStage.Current(); stage == worldstage.ShuttingDown || stage == worldstage.ShutDown {
+		return eris.Errorf("world state is %s, cannot add system %q", stage, name)
+	}
+	if name == "" {
+		return eris.New("system name must not be empty")
+	}
+	return w.SystemManager.addSystemBetweenTicks(name, sys)
+}
+
 func (w *World) RegisterPlugin(plugin Plugin) {
 	if err := plugin.Register(w); err != nil {
 		log.Fatal().Err(err).Msgf("failed to register plugin: %v", err)
@@ -488,15 +832,31 @@ func (w *World) drainChannelsWaitingForNextTick() {
 
 // AddTransaction adds a transaction to the transaction pool. This should not be used directly.
 // Instead, use a MessageType.addTransaction to ensure type consistency. Returns the tick this transaction will be
-// executed in.
+// executed in. If the message queue is full (see WithMaxQueuedMessages), ErrMessageQueueFull is returned. If the
+// signature verifier (see WithSignatureVerifier) does not accept the transaction, ErrSignatureRejected is returned.
 func (w *World) AddTransaction(id types.MessageID, v any, sig *sign.Transaction) (
-	tick uint64, txHash types.TxHash,
+	tick uint64, txHash types.TxHash, err error,
 ) {
+	if err := w.verifySignature(sig); err != nil {
+		return 0, "", eris.Wrap(err, "failed to queue message")
+	}
 	// TODO: There's no locking between getting the tick and adding the transaction, so there's no guarantee that this
 	// transaction is actually added to the returned tick.
-	tick = w.CurrentTick()
-	txHash = w.txPool.AddTransaction(id, v, sig)
-	return tick, txHash
+	enqueue := func() error {
+		tick = w.CurrentTick()
+		txHash, err = w.txPool.AddTransaction(id, v, sig)
+		return err
+	}
+	if w.nonceProtection {
+		// The nonce is only used up once the transaction has been queued.
+		err = w.redisStorage.UseIncreasingNonce(sig.PersonaTag, sig.Nonce, enqueue)
+	} else {
+		err = enqueue()
+	}
+	if err != nil {
+		return 0, "", eris.Wrap(err, "failed to queue message")
+	}
+	return tick, txHash, nil
 }
 
 func (w *World) AddEVMTransaction(
@@ -505,11 +865,14 @@ func (w *World) AddEVMTransaction(
 	sig *sign.Transaction,
 	evmTxHash string,
 ) (
-	tick uint64, txHash types.TxHash,
+	tick uint64, txHash types.TxHash, err error,
 ) {
 	tick = w.CurrentTick()
-	txHash = w.txPool.AddEVMTransaction(id, v, sig, evmTxHash)
-	return tick, txHash
+	txHash, err = w.txPool.AddEVMTransaction(id, v, sig, evmTxHash)
+	if err != nil {
+		return 0, "", eris.Wrap(err, "failed to queue EVM message")
+	}
+	return tick, txHash, nil
 }
 
 func (w *World) UseNonce(signerAddress string, nonce uint64) error {
@@ -517,6 +880,8 @@ func (w *World) UseNonce(signerAddress string, nonce uint64) error {
 }
 
 func (w *World) GetDebugState() ([]types.DebugStateElement, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
 	result := make([]types.DebugStateElement, 0)
 	s := w.Search(filter.All())
 	var eachClosureErr error
@@ -561,6 +926,42 @@ func (w *World) GameStateManager() gamestate.Manager {
 	return w.entityStore
 }
 
+// randTick returns the tick number the random number generator of the current tick is derived from. It is the current
+// tick, unless the world was restored from a snapshot of a world at another tick.
+func (w *World) randTick() uint64 {
+	return w.CurrentTick() + w.randTickOffset
+}
+
+// tickSeed returns the seed of the random number generator of the current tick. With a seed set with WithSeed, the seed
+// and the tick number are mixed with the SplitMix64 finalizer, so that the generators of consecutive ticks are
+// unrelated.
+func (w *World) tickSeed() int64 {
+	if w.seed == nil {
+		return int64(w.timestamp.Load())
+	}
+	z := uint64(*w.seed) + (w.randTick()+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
+
+// Alive reports whether the given entity exists as of the last completed tick. Like read-only queries, it never
+// observes the changes of a tick that is still in progress, nor changes made outside of a tick before the next tick
+// commits them. Entity IDs are never reused, so once the removal of an entity has been committed, Alive returns false
+// for its ID from then on and reading its components returns ErrEntityDoesNotExist. An error is returned if the state
+// could not be read.
+func (w *World) Alive(id types.EntityID) (bool, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
+	_, err := w.StoreReader().GetComponentTypesForEntity(id)
+	if eris.Is(err, ErrEntityDoesNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, eris.Wrapf(err, "failed to check whether entity %d is alive", id)
+	}
+	return true, nil
+}
+
 // WaitForNextTick blocks until at least one game tick has completed. It returns true if it successfully waited for a
 // tick. False may be returned if the engine was shut down while waiting for the next tick to complete.
 func (w *World) WaitForNextTick() (success bool) {
@@ -583,6 +984,103 @@ func (w *World) GetRegisteredComponents() []types.ComponentMetadata {
 	return w.GetComponents()
 }
 
+// ComponentInfo describes a registered component type.
+type ComponentInfo struct {
+	Name string
+	ID   types.ComponentID
+	// Fields maps the name of each field of the component to its type. Nested structs are described by nested maps.
+	Fields map[string]any
+}
+
+// Components returns a description of every registered component, ordered by component ID. It is meant for tooling
+// that needs to inspect the components of a world at runtime.
+func (w *World) Components() []ComponentInfo {
+	comps := w.GetComponents()
+	infos := make([]ComponentInfo, 0, len(comps))
+	for _, c := range comps {
+		var fields map[string]any
+		if bz, err := c.New(); err == nil {
+			if value, err := c.Decode(bz); err == nil {
+				fields = types.GetFieldInformation(reflect.TypeOf(value))
+			}
+		}
+		infos = append(infos, ComponentInfo{Name: c.Name(), ID: c.ID(), Fields: fields})
+	}
+	slices.SortFunc(infos, func(a, b ComponentInfo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return infos
+}
+
+// MessageInfo describes a registered message type.
+type MessageInfo struct {
+	Name     string
+	FullName string
+	ID       types.MessageID
+	// InSchema and OutSchema are the JSON schemas of the message's input and output types.
+	InSchema  json.RawMessage
+	OutSchema json.RawMessage
+}
+
+// Messages returns a description of every registered message, ordered by message ID. It is meant for tooling that
+// generates clients for the messages of a world.
+func (w *World) Messages() []MessageInfo {
+	msgs := w.GetRegisteredMessages()
+	infos := make([]MessageInfo, 0, len(msgs))
+	for _, msg := range msgs {
+		inSchema, err := msg.GetInSchema()
+		if err != nil {
+			w.logger.Warn().Err(err).Msgf("failed to get the input schema of message %q", msg.FullName())
+		}
+		outSchema, err := msg.GetOutSchema()
+		if err != nil {
+			w.logger.Warn().Err(err).Msgf("failed to get the output schema of message %q", msg.FullName())
+		}
+		infos = append(infos, MessageInfo{
+			Name:      msg.Name(),
+			FullName:  msg.FullName(),
+			ID:        msg.ID(),
+			InSchema:  inSchema,
+			OutSchema: outSchema,
+		})
+	}
+	slices.SortFunc(infos, func(a, b MessageInfo) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return infos
+}
+
+// archetypeCacheKey identifies the archetypes matched by a filter. Read-only contexts only see the committed
+// archetypes, while the other contexts also see the archetypes that are still pending, so each kind of reader has its
+// own cache.
+type archetypeCacheKey struct {
+	filterKey string
+	pending   bool
+}
+
+// archetypeCache returns the archetypes matched by the searches with the given filter key, creating it if needed.
+// The cache includes the pending archetypes if pending is true.
+func (w *World) archetypeCache(filterKey string, pending bool) *cache {
+	w.archetypeCachesMu.Lock()
+	defer w.archetypeCachesMu.Unlock()
+	key := archetypeCacheKey{filterKey: filterKey, pending: pending}
+	c, ok := w.archetypeCaches[key]
+	if !ok {
+		c = &cache{}
+		w.archetypeCaches[key] = c
+	}
+	return c
+}
+
+// resetArchetypeCache drops the archetypes matched by the searches with the given filter key, so that the next
+// evaluation of any of them re-scans every archetype from the start.
+func (w *World) resetArchetypeCache(filterKey string) {
+	w.archetypeCachesMu.Lock()
+	defer w.archetypeCachesMu.Unlock()
+	delete(w.archetypeCaches, archetypeCacheKey{filterKey: filterKey, pending: false})
+	delete(w.archetypeCaches, archetypeCacheKey{filterKey: filterKey, pending: true})
+}
+
 func (w *World) GetReadOnlyCtx() WorldContext {
 	return NewReadOnlyWorldContext(w)
 }
@@ -600,7 +1098,7 @@ func (w *World) broadcastTickResults(ctx context.Context) {
 	//  current tick. We should fix this.
 	receipts, err := w.receiptHistory.GetReceiptsForTick(w.CurrentTick() - 1)
 	if err != nil {
-		log.Error().Err(err).Msgf("failed to get receipts for tick %d", w.CurrentTick()-1)
+		w.logger.Error().Err(err).Msgf("failed to get receipts for tick %d", w.CurrentTick()-1)
 	}
 	w.tickResults.SetReceipts(receipts)
 	w.tickResults.SetTick(w.CurrentTick() - 1)
@@ -621,6 +1119,8 @@ func (w *World) ReceiptHistorySize() uint64 {
 }
 
 func (w *World) EvaluateCQL(cqlString string) ([]types.EntityStateElement, error) {
+	w.stateLock.RLock()
+	defer w.stateLock.RUnlock()
 	// getComponentByName is a wrapper function that casts component.ComponentMetadata from ctx.getComponentByName
 	// to types.Component
 	getComponentByName := func(name string) (types.Component, error) {
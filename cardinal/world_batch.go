@@ -0,0 +1,128 @@
+package cardinal
+
+import (
+	"math"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+	"pkg.world.dev/world-engine/sign"
+)
+
+// MaxBatchSize is the maximum number of messages that can be submitted at once with SubmitBatch. Each message of a
+// batch is given a distinct salt so that identical messages have distinct hashes, and there are only this many salts.
+const MaxBatchSize = math.MaxUint16 + 1
+
+// PendingMessage is a message to be submitted to the world with SubmitBatch.
+type PendingMessage struct {
+	PersonaTag string
+	// MessageName is either the full name of the message (e.g. "game.move") or, if it is unambiguous, only its name.
+	MessageName string
+	Value       any
+}
+
+// SubmitBatch enqueues all the given messages for the next tick at once, using the same transaction pool that messages
+// submitted over HTTP or from the base shard go through. If any of the messages cannot be resolved or encoded, an error
+// is returned and none of the messages are enqueued. Messages of the same type are processed in submission order.
+// A batch holds at most MaxBatchSize messages.
+// The transactions are not signed, so this is meant for in-process simulation and tests.
+func (w *World) SubmitBatch(msgs []PendingMessage) ([]types.TxHash, error) {
+	txs, err := w.pendingMessagesToTxs(msgs, sign.TimestampNow(), 0)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := w.txPool.AddTransactions(txs)
+	if err != nil {
+		return nil, eris.Wrap(err, "failed to queue batch")
+	}
+	return hashes, nil
+}
+
+// pendingMessagesToTxs resolves and encodes the given messages into transactions with the given timestamp, salting
+// them with consecutive salts starting at firstSalt. The transactions are not signed.
+func (w *World) pendingMessagesToTxs(msgs []PendingMessage, timestamp int64, firstSalt int) ([]txpool.TxData, error) {
+	if len(msgs) > MaxBatchSize-firstSalt {
+		return nil, eris.Errorf("batch of %d messages exceeds the limit of %d messages", len(msgs), MaxBatchSize-firstSalt)
+	}
+	txs := make([]txpool.TxData, 0, len(msgs))
+	for i, pending := range msgs {
+		//nolint:gosec // the salt is below MaxBatchSize, so it fits a uint16
+		tx, err := w.pendingMessageToTx(pending, timestamp, uint16(firstSalt+i))
+		if err != nil {
+			return nil, eris.Wrapf(err, "message %d in batch", i)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// pendingMessageToTx resolves and encodes the given message into a transaction with the given timestamp and salt. The
+// salt makes otherwise identical messages with the same timestamp have distinct hashes. The transaction is not signed.
+func (w *World) pendingMessageToTx(pending PendingMessage, timestamp int64, salt uint16) (txpool.TxData, error) {
+	msgType, ok := w.GetMessageByFullName(pending.MessageName)
+	if !ok {
+		msgType, ok = w.GetMessageByName(pending.MessageName)
+	}
+	if !ok {
+		return txpool.TxData{}, eris.Errorf("message %q is not registered", pending.MessageName)
+	}
+	body, err := msgType.Encode(pending.Value)
+	if err != nil {
+		return txpool.TxData{}, eris.Wrapf(err, "failed to encode %q", pending.MessageName)
+	}
+	// Decoding the body ensures the value that systems receive has the message's input type.
+	value, err := msgType.Decode(body)
+	if err != nil {
+		return txpool.TxData{}, eris.Wrapf(err, "value is not a valid %q", pending.MessageName)
+	}
+	return txpool.TxData{
+		MsgID:  msgType.ID(),
+		Msg:    value,
+		TxHash: "", // Set by the tx pool
+		Tx: &sign.Transaction{
+			PersonaTag: pending.PersonaTag,
+			Namespace:  w.Namespace(),
+			Timestamp:  timestamp,
+			Salt:       salt,
+			Signature:  "",
+			Body:       body,
+		},
+		EVMSourceTxHash: "",
+	}, nil
+}
+
+// preTickHookMessages returns the messages the pre tick hooks return for the given tick.
+func (w *World) preTickHookMessages(tick uint64) []PendingMessage {
+	var msgs []PendingMessage
+	for _, hook := range w.preTickHooks {
+		msgs = append(msgs, hook(tick)...)
+	}
+	return msgs
+}
+
+// injectPreTickHookMessages adds the given messages returned by the pre tick hooks to the transaction pool of the
+// given tick, so that they are processed in it, and marks their receipts as internal. Their salts start at firstSalt,
+// after those of the scheduled messages injected in the tick. The messages are resolved together; if any of them is
+// invalid, none of them are added and the error is logged.
+func (w *World) injectPreTickHookMessages(pool *txpool.TxPool, tick, timestamp uint64, msgs []PendingMessage,
+	firstSalt int) {
+	if len(msgs) == 0 {
+		return
+	}
+	//nolint:gosec // millisecond timestamps fit an int64
+	txs, err := w.pendingMessagesToTxs(msgs, int64(timestamp), firstSalt)
+	if err != nil {
+		w.logger.Error().Err(err).Msgf("Failed to inject the pre tick hook messages at tick %d", tick)
+		return
+	}
+	w.injectInternalMessages(pool, txs)
+}
+
+// injectInternalMessages adds the given messages generated by the world to the given pool and marks their receipts as
+// internal. They are not subject to the message queue limit, as they would otherwise be lost.
+func (w *World) injectInternalMessages(pool *txpool.TxPool, txs []txpool.TxData) {
+	for _, hash := range pool.AddTransactionsIgnoringLimit(txs) {
+		w.receiptHistory.MarkInternal(hash)
+	}
+}
@@ -1,13 +1,13 @@
 package cardinal
 
 import (
+	"context"
 	"math/rand"
 	"reflect"
 	"time"
 
 	"github.com/rotisserie/eris"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/gamestate"
 	"pkg.world.dev/world-engine/cardinal/receipt"
@@ -24,14 +24,20 @@ var _ WorldContext = (*worldContext)(nil)
 type WorldContext interface {
 	// Timestamp returns the UNIX timestamp of the tick in milliseconds.
 	// Millisecond is used to provide precision when working with subsecond tick intervals.
+	// Ticks that are recovered or replayed from the base shard have the timestamp they originally had.
 	Timestamp() uint64
 
-	// CurrentTick returns the current tick.
+	// CurrentTick returns the current tick. Within a system, this is the number of the tick being run; it starts at 0
+	// and increases by one every tick, including the ticks that are recovered or replayed from the base shard.
 	CurrentTick() uint64
 
 	// Logger returns the logger that can be used to log messages from within system or query.
 	Logger() *zerolog.Logger
 
+	// Context returns the context of the system that is running, which carries the system's trace span. Spans started
+	// from it are children of the system's span. Outside of systems, it is context.Background().
+	Context() context.Context
+
 	// EmitEvent emits an event that will be broadcast to all websocket subscribers.
 	EmitEvent(map[string]any) error
 
@@ -42,7 +48,9 @@ type WorldContext interface {
 	// Namespace returns the namespace of the world.
 	Namespace() string
 
-	// Rand returns a random number generator that is seeded specifically for a current tick.
+	// Rand returns a random number generator that is seeded specifically for a current tick. Systems must use it instead
+	// of the global generator of math/rand, so that replaying a tick produces the same random numbers. Use WithSeed to
+	// make the random numbers independent of the timestamps of the ticks.
 	Rand() *rand.Rand
 
 	// ScheduleTickTask schedules a task to be executed after the specified tickDelay.
@@ -55,6 +63,7 @@ type WorldContext interface {
 
 	// Private methods for internal use.
 	setLogger(logger zerolog.Logger)
+	setContext(ctx context.Context)
 	addMessageError(id types.TxHash, err error)
 	setMessageResult(id types.TxHash, a any)
 	getComponentByName(name string) (types.ComponentMetadata, error)
@@ -63,18 +72,23 @@ type WorldContext interface {
 	getSignerForPersonaTag(personaTag string, tick uint64) (addr string, err error)
 	getTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, error)
 	receiptHistorySize() uint64
-	addTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash)
+	addTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash, error)
 	isWorldReady() bool
 	storeReader() gamestate.Reader
 	storeManager() gamestate.Manager
 	getTxPool() *txpool.TxPool
 	isReadOnly() bool
+	getSpatialIndexes() spatialIndexes
+	getComponentRemovalHooks() componentRemovalHooks
+	getArchetypeCache(filterKey string) *cache
+	getWorld() *World
 }
 
 type worldContext struct {
 	world    *World
 	txPool   *txpool.TxPool
 	logger   *zerolog.Logger
+	ctx      context.Context //nolint:containedctx // the context of the running system is passed on to it
 	readOnly bool
 	rand     *rand.Rand
 }
@@ -83,10 +97,11 @@ func newWorldContextForTick(world *World, txPool *txpool.TxPool) WorldContext {
 	return &worldContext{
 		world:    world,
 		txPool:   txPool,
-		logger:   &log.Logger,
+		logger:   world.logger,
+		ctx:      context.Background(),
 		readOnly: false,
 		//nolint:gosec // we require manual in the rng which crypto/rand doesn't have, but math/rand does.
-		rand: rand.New(rand.NewSource(int64(world.timestamp.Load()))),
+		rand: rand.New(rand.NewSource(world.tickSeed())),
 	}
 }
 
@@ -94,7 +109,8 @@ func NewWorldContext(world *World) WorldContext {
 	return &worldContext{
 		world:    world,
 		txPool:   nil,
-		logger:   &log.Logger,
+		logger:   world.logger,
+		ctx:      context.Background(),
 		readOnly: false,
 		rand:     nil,
 	}
@@ -104,7 +120,8 @@ func NewReadOnlyWorldContext(world *World) WorldContext {
 	return &worldContext{
 		world:    world,
 		txPool:   nil,
-		logger:   &log.Logger,
+		logger:   world.logger,
+		ctx:      context.Background(),
 		readOnly: true,
 		rand:     nil,
 	}
@@ -148,6 +165,10 @@ func (ctx *worldContext) Logger() *zerolog.Logger {
 	return ctx.logger
 }
 
+func (ctx *worldContext) Context() context.Context {
+	return ctx.ctx
+}
+
 func (ctx *worldContext) Rand() *rand.Rand {
 	if ctx.rand == nil {
 		// a panic is thrown here instead of returning an error to maintain method chaining.
@@ -173,6 +194,10 @@ func (ctx *worldContext) setLogger(logger zerolog.Logger) {
 	ctx.logger = &logger
 }
 
+func (ctx *worldContext) setContext(c context.Context) {
+	ctx.ctx = c
+}
+
 func (ctx *worldContext) getComponentByName(name string) (types.ComponentMetadata, error) {
 	return ctx.world.GetComponentByName(name)
 }
@@ -207,7 +232,9 @@ func (ctx *worldContext) receiptHistorySize() uint64 {
 	return ctx.world.receiptHistory.Size()
 }
 
-func (ctx *worldContext) addTransaction(id types.MessageID, v any, sig *sign.Transaction) (uint64, types.TxHash) {
+func (ctx *worldContext) addTransaction(
+	id types.MessageID, v any, sig *sign.Transaction,
+) (uint64, types.TxHash, error) {
 	return ctx.world.AddTransaction(id, v, sig)
 }
 
@@ -231,9 +258,28 @@ func (ctx *worldContext) storeReader() gamestate.Reader {
 	return sm
 }
 
+// isWorldReady reports whether systems may create entities. This includes the final tick that processes the
+// transactions still pending when the world starts shutting down.
 func (ctx *worldContext) isWorldReady() bool {
 	stage := ctx.world.worldStage.Current()
 	return stage == worldstage.Ready ||
 		stage == worldstage.Running ||
-		stage == worldstage.Recovering
+		stage == worldstage.Recovering ||
+		stage == worldstage.ShuttingDown
+}
+
+func (ctx *worldContext) getSpatialIndexes() spatialIndexes {
+	return ctx.world.spatialIndexes
+}
+
+func (ctx *worldContext) getComponentRemovalHooks() componentRemovalHooks {
+	return ctx.world.componentRemovalHooks
+}
+
+func (ctx *worldContext) getArchetypeCache(filterKey string) *cache {
+	return ctx.world.archetypeCache(filterKey, !ctx.readOnly)
+}
+
+func (ctx *worldContext) getWorld() *World {
+	return ctx.world
 }
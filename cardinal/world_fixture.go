@@ -20,6 +20,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"pkg.world.dev/world-engine/cardinal/persona/msg"
+	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/sign"
 )
@@ -97,7 +98,9 @@ func NewTestFixture(t testing.TB, redis *miniredis.Miniredis, opts ...WorldOptio
 			}()
 
 			// Next, shut down the world
-			world.Shutdown()
+			if err := world.Shutdown(context.Background()); err != nil {
+				t.Errorf("failed to shut down world: %v", err)
+			}
 
 			// The world is shut down; No more ticks will be started
 			close(startTickCh)
@@ -142,6 +145,32 @@ func (t *TestFixture) DoTick() {
 	<-t.DoneTickCh
 }
 
+// TestWorld is a world for table-driven tests of game logic. Each call to Tick runs exactly one tick synchronously,
+// through the same game loop that runs the ticks of a real world.
+type TestWorld struct {
+	*TestFixture
+}
+
+// NewTestWorld creates a TestWorld with the given options. Like with NewTestFixture, the world is backed by an
+// in-memory redis and is shut down at the end of the test.
+func NewTestWorld(t testing.TB, opts ...WorldOption) *TestWorld {
+	return &TestWorld{TestFixture: NewTestFixture(t, nil, opts...)}
+}
+
+// Tick submits the given messages (see World.SubmitBatch), runs one tick, and returns the receipts of that tick. The
+// world is started on the first tick, so components, messages, and systems must be registered before then.
+func (t *TestWorld) Tick(msgs ...PendingMessage) ([]receipt.Receipt, error) {
+	t.StartWorld()
+	if len(msgs) > 0 {
+		if _, err := t.World.SubmitBatch(msgs); err != nil {
+			return nil, err
+		}
+	}
+	tick := t.World.CurrentTick()
+	t.DoTick()
+	return t.World.GetTransactionReceiptsForTick(tick)
+}
+
 func (t *TestFixture) httpURL(path string) string {
 	return fmt.Sprintf("http://%s/%s", t.BaseURL, path)
 }
@@ -178,7 +207,8 @@ func (t *TestFixture) AddTransaction(txID types.MessageID, tx any, sigs ...*sign
 	if len(sigs) > 0 {
 		sig = sigs[0]
 	}
-	_, id := t.World.AddTransaction(txID, tx, sig)
+	_, id, err := t.World.AddTransaction(txID, tx, sig)
+	assert.NilError(t, err)
 	return id
 }
 
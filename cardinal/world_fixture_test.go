@@ -0,0 +1,80 @@
+package cardinal_test
+
+import (
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+)
+
+type Location struct {
+	X, Y int
+}
+
+func (Location) Name() string { return "location" }
+
+type MoveMsg struct {
+	DeltaX, DeltaY int
+}
+
+// moveSystem moves the only entity with a location by the deltas of each move message, and returns its new location.
+func moveSystem(wCtx cardinal.WorldContext) error {
+	id, err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[Location]())).First(wCtx)
+	if err != nil {
+		return err
+	}
+	return cardinal.EachMessage[MoveMsg, Location](wCtx, func(tx cardinal.TxData[MoveMsg]) (Location, error) {
+		err := cardinal.UpdateComponent[Location](wCtx, id, func(loc *Location) *Location {
+			loc.X += tx.Msg.DeltaX
+			loc.Y += tx.Msg.DeltaY
+			return loc
+		})
+		if err != nil {
+			return Location{}, err
+		}
+		loc, err := cardinal.GetComponent[Location](wCtx, id)
+		if err != nil {
+			return Location{}, err
+		}
+		return *loc, nil
+	})
+}
+
+func TestTestWorldTickReturnsTheReceiptsOfTheTick(t *testing.T) {
+	testCases := []struct {
+		name  string
+		moves []MoveMsg
+		want  Location
+	}{
+		{name: "single move", moves: []MoveMsg{{DeltaX: 1, DeltaY: 2}}, want: Location{X: 1, Y: 2}},
+		{name: "moves add up", moves: []MoveMsg{{DeltaX: 1}, {DeltaX: 2, DeltaY: -1}}, want: Location{X: 3, Y: -1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tw := cardinal.NewTestWorld(t)
+			world := tw.World
+			assert.NilError(t, cardinal.RegisterComponent[Location](world))
+			assert.NilError(t, cardinal.RegisterMessage[MoveMsg, Location](world, "move"))
+			assert.NilError(t, cardinal.RegisterInitSystems(world, func(wCtx cardinal.WorldContext) error {
+				_, err := cardinal.Create(wCtx, Location{})
+				return err
+			}))
+			assert.NilError(t, cardinal.RegisterSystems(world, moveSystem))
+
+			_, err := tw.Tick()
+			assert.NilError(t, err)
+			var last Location
+			for _, move := range tc.moves {
+				receipts, err := tw.Tick(cardinal.PendingMessage{PersonaTag: "alice", MessageName: "move", Value: move})
+				assert.NilError(t, err)
+				assert.Equal(t, len(receipts), 1)
+				assert.Equal(t, len(receipts[0].Errs), 0)
+				var ok bool
+				last, ok = receipts[0].Result.(Location)
+				assert.Assert(t, ok)
+			}
+			assert.Equal(t, last, tc.want)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
 )
 
 type EVMTxReceipt struct {
@@ -17,6 +18,19 @@ func (w *World) GetTransactionReceiptsForTick(tick uint64) ([]receipt.Receipt, e
 	return w.receiptHistory.GetReceiptsForTick(tick)
 }
 
+// ReceiptsForTickRange returns the receipts of the ticks in [start, end] that are still retained in the receipt history.
+// If only part of the range is retained, the available receipts are returned along with an error that wraps
+// receipt.ErrTickRangeTruncated.
+func (w *World) ReceiptsForTickRange(start, end uint64) ([]receipt.Receipt, error) {
+	return w.receiptHistory.GetReceiptsForTickRange(start, end)
+}
+
+// ReceiptByTxHash returns the receipt of the transaction with the given hash if it is still in the receipt history. The
+// hash is the one returned when the transaction was submitted, whether over HTTP, from the base shard, or in-process.
+func (w *World) ReceiptByTxHash(hash types.TxHash) (receipt.Receipt, bool) {
+	return w.receiptHistory.FindReceipt(hash)
+}
+
 // ConsumeEVMMsgResult consumes a tx result from an EVM originated Cardinal message.
 // It will fetch the receipt from the map, and then delete ('consume') it from the map.
 func (w *World) ConsumeEVMMsgResult(evmTxHash string) ([]byte, []error, string, bool) {
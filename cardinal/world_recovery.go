@@ -2,13 +2,18 @@ package cardinal
 
 import (
 	"context"
+	"math"
 
 	"github.com/rotisserie/eris"
-	"github.com/rs/zerolog/log"
 
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/worldstage"
 )
 
+// errReplayDone is used to stop iterating over transactions once the last tick to replay has been reached.
+var errReplayDone = eris.New("replay done")
+
 // recoverFromChain will attempt to recover the state of the engine based on historical transaction data.
 // The function puts the World in a recovery state, and will then query all transaction batches under the World's
 // namespace. The function will continuously ask the EVM base shard for batches, and run ticks for each batch returned.
@@ -20,42 +25,88 @@ func (w *World) recoverFromChain(ctx context.Context) error {
 		)
 	}
 
-	log.Info().Msgf("Synchronizing state from base shard starting from tick %d", w.CurrentTick())
+	w.logger.Info().Msgf("Synchronizing state from base shard starting from tick %d", w.CurrentTick())
+
+	err := w.replayFromIterator(ctx, w.router.TransactionIterator(), w.CurrentTick(), math.MaxUint64)
+	if err != nil {
+		return eris.Wrap(err, "encountered an error while recovering from chain")
+	}
+
+	w.logger.Info().Msgf("Successfully synchronized state from base shard")
+	return nil
+}
+
+// Replay deterministically re-runs the ticks in [fromTick, toTick] from a recorded stream of transactions, such as the
+// one stored on the base shard. Each tick is run with the transactions, tick number, and timestamp it originally had.
+// Ticks without transactions are run with the timestamp of the next tick that has transactions, like during recovery.
+//
+// The following are deterministic during a replay: the order in which systems run, the transactions each message type
+// receives and their order, WorldContext.Timestamp, and WorldContext.Rand, which is seeded by the tick's timestamp.
+// Anything systems read from outside the world, such as the wall clock or global random number generators, is not.
+//
+// The world must be running and must not be ticked by its game loop while replaying. Like during recovery, replayed
+// transactions are not resubmitted to the base shard and tick results are not broadcast.
+func (w *World) Replay(it iterator.Iterator, fromTick, toTick uint64) error {
+	if fromTick > toTick {
+		return eris.Errorf("cannot replay from tick %d to tick %d", fromTick, toTick)
+	}
+	if w.worldStage.Current() != worldstage.Running {
+		return eris.Errorf("world state is %s, expected %s to replay ticks", w.worldStage.Current(), worldstage.Running)
+	}
+	if !w.isReplaying.CompareAndSwap(false, true) {
+		return eris.New("a replay is already in progress")
+	}
+	defer w.isReplaying.Store(false)
+
+	return w.replayFromIterator(context.Background(), it, fromTick, toTick)
+}
 
-	start := w.CurrentTick()
-	err := w.router.TransactionIterator().Each(func(batches []*iterator.TxBatch, tick, timestamp uint64) error {
+// replayFromIterator runs a tick for each tick of transactions in [fromTick, toTick] that the iterator returns.
+func (w *World) replayFromIterator(ctx context.Context, it iterator.Iterator, fromTick, toTick uint64) error {
+	err := it.Each(func(batches []*iterator.TxBatch, tick, timestamp uint64) error {
 		select {
 		case <-ctx.Done():
 			return eris.New("context cancelled, terminating recovery")
 
 		default:
-			log.Info().Msgf("Found transactions for tick %d", tick)
+			if tick < fromTick {
+				return nil
+			}
+			if tick > toTick {
+				return errReplayDone
+			}
+			if tick < w.CurrentTick() {
+				return eris.Errorf("cannot replay tick %d, the world is already at tick %d", tick, w.CurrentTick())
+			}
+			w.logger.Info().Msgf("Found transactions for tick %d", tick)
 
 			if w.CurrentTick() != tick {
-				log.Info().Msgf("Fast forwarding to tick %d from %d", tick, w.CurrentTick())
+				w.logger.Info().Msgf("Fast forwarding to tick %d from %d", tick, w.CurrentTick())
 			}
 			for w.CurrentTick() != tick {
 				if err := w.doTick(context.Background(), timestamp); err != nil {
 					return eris.Wrap(err, "failed to tick world")
 				}
 			}
-			log.Info().Msgf("Successfully fast forwarded to tick %d", tick)
+			w.logger.Info().Msgf("Successfully fast forwarded to tick %d", tick)
 
+			// The transactions of a recovered tick have already been accepted, so they are not subject to the message
+			// queue limit.
+			txs := make([]txpool.TxData, 0, len(batches))
 			for _, batch := range batches {
-				w.AddTransaction(batch.MsgID, batch.MsgValue, batch.Tx)
+				txs = append(txs, txpool.TxData{MsgID: batch.MsgID, Msg: batch.MsgValue, Tx: batch.Tx})
 			}
+			w.txPool.AddTransactionsIgnoringLimit(txs)
 
-			log.Info().Msgf("Executing tick %d in recovery mode", tick)
+			w.logger.Info().Msgf("Executing tick %d in recovery mode", tick)
 			if err := w.doTick(context.Background(), timestamp); err != nil {
 				return eris.Wrap(err, "failed to tick world")
 			}
 			return nil
 		}
-	}, start)
-	if err != nil {
-		return eris.Wrap(err, "encountered an error while recovering from chain")
+	}, fromTick, toTick)
+	if err != nil && !eris.Is(err, errReplayDone) {
+		return err
 	}
-
-	log.Info().Msgf("Successfully synchronized state from base shard")
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
 	"pkg.world.dev/world-engine/cardinal/router/iterator"
 	iteratormocks "pkg.world.dev/world-engine/cardinal/router/iterator/mocks"
 	"pkg.world.dev/world-engine/cardinal/router/mocks"
@@ -91,3 +92,80 @@ func TestWorldRecovery(t *testing.T) {
 
 	controller.Finish()
 }
+
+type replayPower struct {
+	Value int
+}
+
+func (replayPower) Name() string { return "replayPower" }
+
+type replayIn struct {
+	Base int
+}
+
+type replayOut struct{}
+
+// newReplayWorld creates a world with a system that creates an entity for each message with a power that depends on
+// the tick's random number generator. Every tick with messages is recorded in the returned slice.
+func newReplayWorld(t *testing.T) (*cardinal.TestFixture, *[]Iterable) {
+	tf := cardinal.NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, cardinal.RegisterComponent[replayPower](world))
+	assert.NilError(t, cardinal.RegisterMessage[replayIn, replayOut](world, "replay"))
+
+	recorded := &[]Iterable{}
+	assert.NilError(t, cardinal.RegisterSystems(world, func(wCtx cardinal.WorldContext) error {
+		var batches []*iterator.TxBatch
+		msgType, _ := world.GetMessageByFullName("game.replay")
+		err := cardinal.EachMessage[replayIn, replayOut](wCtx,
+			func(tx cardinal.TxData[replayIn]) (replayOut, error) {
+				batches = append(batches, &iterator.TxBatch{Tx: tx.Tx, MsgID: msgType.ID(), MsgValue: tx.Msg})
+				_, err := cardinal.Create(wCtx, replayPower{Value: tx.Msg.Base + wCtx.Rand().Intn(1_000_000)})
+				return replayOut{}, err
+			})
+		if len(batches) > 0 {
+			*recorded = append(*recorded, Iterable{Batches: batches, Tick: wCtx.CurrentTick(), Timestamp: wCtx.Timestamp()})
+		}
+		return err
+	}))
+	return tf, recorded
+}
+
+func collectReplayPowers(t *testing.T, world *cardinal.World) map[types.EntityID]int {
+	wCtx := cardinal.NewReadOnlyWorldContext(world)
+	powers := map[types.EntityID]int{}
+	err := cardinal.NewSearch().Entity(filter.Exact(filter.Component[replayPower]())).Each(wCtx,
+		func(id types.EntityID) bool {
+			power, err := cardinal.GetComponent[replayPower](wCtx, id)
+			assert.NilError(t, err)
+			powers[id] = power.Value
+			return true
+		})
+	assert.NilError(t, err)
+	return powers
+}
+
+func TestReplayMatchesOriginalRun(t *testing.T) {
+	original, recorded := newReplayWorld(t)
+	original.StartWorld()
+	msgType, ok := original.World.GetMessageByFullName("game.replay")
+	assert.Check(t, ok)
+
+	original.AddTransaction(msgType.ID(), replayIn{Base: 1}, &sign.Transaction{PersonaTag: "a", Salt: 1})
+	original.AddTransaction(msgType.ID(), replayIn{Base: 2}, &sign.Transaction{PersonaTag: "a", Salt: 2})
+	original.DoTick()
+	original.DoTick()
+	original.AddTransaction(msgType.ID(), replayIn{Base: 3}, &sign.Transaction{PersonaTag: "a", Salt: 3})
+	original.DoTick()
+	assert.Equal(t, len(*recorded), 2)
+	want := collectReplayPowers(t, original.World)
+	assert.Equal(t, len(want), 3)
+
+	replayed, _ := newReplayWorld(t)
+	replayed.StartWorld()
+	lastTick := (*recorded)[1].Tick
+	assert.NilError(t, replayed.World.Replay(NewFakeIterator(*recorded), 0, lastTick))
+
+	assert.Equal(t, replayed.World.CurrentTick(), lastTick+1)
+	assert.DeepEqual(t, collectReplayPowers(t, replayed.World), want)
+}
@@ -0,0 +1,149 @@
+package cardinal
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/txpool"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// scheduledMessage is an internal component that holds a message scheduled with ScheduleMessage until the tick it is
+// due. Keeping scheduled messages in the world's state means they are included in snapshots and survive restarts.
+type scheduledMessage struct {
+	DueTick     uint64
+	PersonaTag  string
+	MessageName string
+	Body        []byte
+}
+
+func (scheduledMessage) Name() string {
+	return "scheduledMessage"
+}
+
+// ScheduleMessage enqueues a message to be processed in the tick that is afterTicks ticks after the next one, so an
+// afterTicks of 0 processes the message in the next tick. When called from a system, the next tick is the one after
+// the running tick. Like messages returned by pre tick hooks, scheduled messages are not signed and their receipts
+// are marked as internal. An error is returned if the message cannot be resolved or encoded.
+//
+// The message is kept in the state with the absolute tick it is due, so it is included in snapshots. Snapshots don't
+// include the tick of the world, so a message restored into a world at another tick still fires at that same tick.
+func ScheduleMessage(wCtx WorldContext, afterTicks uint64, msg PendingMessage) error {
+	w := wCtx.getWorld()
+	msgType, ok := w.GetMessageByFullName(msg.MessageName)
+	if !ok {
+		msgType, ok = w.GetMessageByName(msg.MessageName)
+	}
+	if !ok {
+		return eris.Errorf("failed to schedule message: message %q is not registered", msg.MessageName)
+	}
+	body, err := msgType.Encode(msg.Value)
+	if err != nil {
+		return eris.Wrapf(err, "failed to schedule message: failed to encode %q", msg.MessageName)
+	}
+
+	// The current tick of a system's context is the running tick, while outside of a tick it is the next one.
+	dueTick := wCtx.CurrentTick() + afterTicks
+	if wCtx.getTxPool() != nil {
+		dueTick++
+	}
+	_, err = Create(wCtx, scheduledMessage{
+		DueTick:     dueTick,
+		PersonaTag:  msg.PersonaTag,
+		MessageName: msgType.FullName(),
+		Body:        body,
+	})
+	if err != nil {
+		return eris.Wrap(err, "failed to schedule message")
+	}
+	return nil
+}
+
+// injectScheduledMessages adds the scheduled messages that are due at the given tick to its pool, in the order they
+// are due, with messages due at the same tick in the order they were scheduled, and marks their receipts as internal.
+// Each message is only removed from the state once it has been added; the messages beyond the MaxBatchSize that can be
+// added to a tick are left for the next tick, and a message that can't be resolved anymore is removed and logged. If
+// inject is not set, the messages are removed from the state without being added, as recovered ticks already include
+// them. It returns the number of salts the added messages used.
+func (w *World) injectScheduledMessages(
+	wCtx WorldContext, pool *txpool.TxPool, tick, timestamp uint64, inject bool,
+) (int, error) {
+	type due struct {
+		id  types.EntityID
+		msg *scheduledMessage
+	}
+	var dueMsgs []due
+	var getErr error
+	err := NewSearch().Entity(filter.Exact(filter.Component[scheduledMessage]())).Each(wCtx,
+		func(id types.EntityID) bool {
+			msg, err := GetComponent[scheduledMessage](wCtx, id)
+			if err != nil {
+				getErr = err
+				return false
+			}
+			if msg.DueTick <= tick {
+				dueMsgs = append(dueMsgs, due{id: id, msg: msg})
+			}
+			return true
+		})
+	if err = cmp.Or(getErr, err); err != nil {
+		return 0, eris.Wrap(err, "failed to find the scheduled messages")
+	}
+	slices.SortFunc(dueMsgs, func(a, b due) int {
+		return cmp.Or(cmp.Compare(a.msg.DueTick, b.msg.DueTick), cmp.Compare(a.id, b.id))
+	})
+	if len(dueMsgs) > MaxBatchSize {
+		dueMsgs = dueMsgs[:MaxBatchSize]
+	}
+
+	txs := make([]txpool.TxData, 0, len(dueMsgs))
+	for _, d := range dueMsgs {
+		tx, err := w.scheduledMessageToTx(d.msg, timestamp, len(txs))
+		if err != nil {
+			w.logger.Error().Err(err).Msgf("Dropped a scheduled message due at tick %d", d.msg.DueTick)
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	if inject {
+		w.injectInternalMessages(pool, txs)
+	}
+	for _, d := range dueMsgs {
+		if err := Remove(wCtx, d.id); err != nil {
+			return 0, eris.Wrap(err, "failed to remove a scheduled message")
+		}
+	}
+	return len(txs), nil
+}
+
+// scheduledMessageToTx decodes the given scheduled message into a transaction with the given timestamp and salt.
+func (w *World) scheduledMessageToTx(msg *scheduledMessage, timestamp uint64, salt int) (txpool.TxData, error) {
+	msgType, ok := w.GetMessageByFullName(msg.MessageName)
+	if !ok {
+		return txpool.TxData{}, eris.Errorf("scheduled message %q is not registered", msg.MessageName)
+	}
+	value, err := msgType.Decode(msg.Body)
+	if err != nil {
+		return txpool.TxData{}, eris.Wrapf(err, "failed to decode scheduled message %q", msg.MessageName)
+	}
+	pending := PendingMessage{PersonaTag: msg.PersonaTag, MessageName: msg.MessageName, Value: value}
+	//nolint:gosec // millisecond timestamps fit an int64, and salts are below MaxBatchSize, so they fit a uint16
+	return w.pendingMessageToTx(pending, int64(timestamp), uint16(salt))
+}
+
+// messageSchedulerPlugin registers the component that holds the messages scheduled with ScheduleMessage.
+type messageSchedulerPlugin struct{}
+
+func newMessageSchedulerPlugin() *messageSchedulerPlugin {
+	return &messageSchedulerPlugin{}
+}
+
+func (*messageSchedulerPlugin) Register(w *World) error {
+	if err := RegisterComponent[scheduledMessage](w); err != nil {
+		return eris.Wrap(err, "failed to register scheduled message component")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package cardinal
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+
+	"github.com/rotisserie/eris"
+
+	"pkg.world.dev/world-engine/cardinal/codec"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// snapshotVersion is the version of the format written by World.Snapshot. It must be increased whenever the format
+// changes in a way that older versions of Restore can't read.
+const snapshotVersion = 1
+
+var ErrUnsupportedSnapshotVersion = eris.New("unsupported snapshot version")
+
+type worldSnapshot struct {
+	Version int                 `json:"version"`
+	State   *gamestate.Snapshot `json:"state"`
+	// Seed is the seed set with WithSeed, if any.
+	Seed *int64 `json:"seed,omitempty"`
+	// RandTick is the tick number that the random number generator of the next tick is derived from, if the world has
+	// a seed. The generator of each tick is derived from the seed and a tick number, so both are needed to continue the
+	// sequence of random numbers.
+	RandTick *uint64 `json:"randTick,omitempty"`
+}
+
+// Snapshot serializes all archetypes, entities, and component values of the world, including changes made during the
+// current tick. Component values are encoded with each component's codec. Taking a snapshot of the same state always
+// produces the same bytes.
+func (w *World) Snapshot() ([]byte, error) {
+	state, err := w.entityStore.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	snap := worldSnapshot{Version: snapshotVersion, State: state, Seed: w.seed, RandTick: nil}
+	if w.seed != nil {
+		randTick := w.randTick()
+		snap.RandTick = &randTick
+	}
+	return codec.Encode(snap)
+}
+
+// Restore recreates the state serialized by Snapshot. Entities keep their IDs, so the world must not have any entities
+// or archetypes yet, and the components of the snapshot must be registered. Like other changes made outside of a
+// system, the restored state is committed at the end of the next tick. If the snapshot was taken from a world created
+// with WithSeed, its seed replaces the seed of this world, and the random number generators of the following ticks
+// continue from where the generators of that world were, whatever the tick numbers of the two worlds are.
+func (w *World) Restore(data []byte) error {
+	snap, err := decodeSnapshot(data)
+	if err != nil {
+		return err
+	}
+	if err := w.entityStore.Restore(snap.State); err != nil {
+		return err
+	}
+	if snap.Seed != nil {
+		w.seed = snap.Seed
+		w.randTickOffset = 0
+		if snap.RandTick != nil {
+			// The offset wraps around like the tick number it is added to.
+			w.randTickOffset = *snap.RandTick - w.CurrentTick()
+		}
+	}
+	return nil
+}
+
+func decodeSnapshot(data []byte) (*worldSnapshot, error) {
+	snap, err := codec.Decode[worldSnapshot](data)
+	if err != nil {
+		return nil, err
+	}
+	if snap.Version != snapshotVersion {
+		return nil, eris.Wrapf(ErrUnsupportedSnapshotVersion,
+			"got version %d, want version %d", snap.Version, snapshotVersion)
+	}
+	if snap.State == nil {
+		return nil, eris.New("snapshot has no state")
+	}
+	return &snap, nil
+}
+
+// WorldDelta is the difference between two snapshots. Entities in each list are ordered by ID.
+type WorldDelta struct {
+	// Added has the entities that only exist in the later snapshot, with all of their components.
+	Added []EntityDelta `json:"added,omitempty"`
+	// Removed has the IDs of the entities that only exist in the earlier snapshot.
+	Removed []types.EntityID `json:"removed,omitempty"`
+	// Changed has the entities that exist in both snapshots, with only the components that were added or whose values
+	// changed, and the names of the components that were removed.
+	Changed []EntityDelta `json:"changed,omitempty"`
+}
+
+// EntityDelta is a change to a single entity. Component values are encoded with each component's codec.
+type EntityDelta struct {
+	ID                types.EntityID             `json:"id"`
+	Components        map[string]json.RawMessage `json:"components,omitempty"`
+	RemovedComponents []string                   `json:"removedComponents,omitempty"`
+}
+
+// IsEmpty reports whether the delta has no changes.
+func (d WorldDelta) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff computes the changes needed to go from the state in the before snapshot to the state in the after snapshot.
+// Both snapshots must have been created with World.Snapshot.
+func Diff(before, after []byte) (WorldDelta, error) {
+	beforeSnap, err := decodeSnapshot(before)
+	if err != nil {
+		return WorldDelta{}, eris.Wrap(err, "failed to decode the before snapshot")
+	}
+	afterSnap, err := decodeSnapshot(after)
+	if err != nil {
+		return WorldDelta{}, eris.Wrap(err, "failed to decode the after snapshot")
+	}
+	beforeEntities, afterEntities := entityComponents(beforeSnap.State), entityComponents(afterSnap.State)
+
+	delta := WorldDelta{}
+	for _, id := range sortedEntityIDs(afterEntities) {
+		afterComps := afterEntities[id]
+		beforeComps, ok := beforeEntities[id]
+		if !ok {
+			delta.Added = append(delta.Added, EntityDelta{ID: id, Components: afterComps, RemovedComponents: nil})
+			continue
+		}
+		change := EntityDelta{ID: id, Components: map[string]json.RawMessage{}, RemovedComponents: nil}
+		for name, value := range afterComps {
+			if beforeValue, ok := beforeComps[name]; !ok || !bytes.Equal(beforeValue, value) {
+				change.Components[name] = value
+			}
+		}
+		for name := range beforeComps {
+			if _, ok := afterComps[name]; !ok {
+				change.RemovedComponents = append(change.RemovedComponents, name)
+			}
+		}
+		if len(change.Components) > 0 || len(change.RemovedComponents) > 0 {
+			slices.Sort(change.RemovedComponents)
+			delta.Changed = append(delta.Changed, change)
+		}
+	}
+	for _, id := range sortedEntityIDs(beforeEntities) {
+		if _, ok := afterEntities[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+	return delta, nil
+}
+
+// entityComponents maps each entity of a snapshot to its encoded component values by component name.
+func entityComponents(state *gamestate.Snapshot) map[types.EntityID]map[string]json.RawMessage {
+	entities := map[types.EntityID]map[string]json.RawMessage{}
+	for _, arch := range state.Archetypes {
+		for _, entity := range arch.Entities {
+			comps := make(map[string]json.RawMessage, len(arch.Components))
+			for i, name := range arch.Components {
+				if i < len(entity.Components) {
+					comps[name] = entity.Components[i]
+				}
+			}
+			entities[entity.ID] = comps
+		}
+	}
+	return entities
+}
+
+func sortedEntityIDs(entities map[types.EntityID]map[string]json.RawMessage) []types.EntityID {
+	ids := make([]types.EntityID, 0, len(entities))
+	for id := range entities {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,253 @@
+package cardinal_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"pkg.world.dev/world-engine/assert"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/gamestate"
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+func newSnapshotTestFixture(t *testing.T) *cardinal.TestFixture {
+	tf := cardinal.NewTestFixture(t, nil)
+	assert.NilError(t, cardinal.RegisterComponent[Alpha](tf.World))
+	assert.NilError(t, cardinal.RegisterComponent[Beta](tf.World))
+	assert.NilError(t, cardinal.RegisterComponent[Gamma](tf.World))
+	tf.StartWorld()
+	return tf
+}
+
+// collectSearch returns the value of the given component of every entity that matches the search, keyed by entity ID.
+func collectSearch[T types.Component](
+	t *testing.T,
+	wCtx cardinal.WorldContext,
+	f filter.ComponentFilter,
+) map[types.EntityID]T {
+	found := map[types.EntityID]T{}
+	err := cardinal.NewSearch().Entity(f).Each(wCtx, func(id types.EntityID) bool {
+		comp, err := cardinal.GetComponent[T](wCtx, id)
+		assert.NilError(t, err)
+		found[id] = *comp
+		return true
+	})
+	assert.NilError(t, err)
+	return found
+}
+
+func TestSnapshotCanBeRestoredIntoANewWorld(t *testing.T) {
+	src := newSnapshotTestFixture(t)
+	srcCtx := cardinal.NewWorldContext(src.World)
+	ids, err := cardinal.CreateMany(srcCtx, 5, Alpha{Name1: "a"}, Beta{Name1: "b"})
+	assert.NilError(t, err)
+	_, err = cardinal.CreateMany(srcCtx, 3, Gamma{Name1: "g"})
+	assert.NilError(t, err)
+	assert.NilError(t, cardinal.SetComponent[Alpha](srcCtx, ids[2], &Alpha{Name1: "changed"}))
+	assert.NilError(t, cardinal.Remove(srcCtx, ids[0]))
+	assert.NilError(t, cardinal.RemoveComponentFrom[Beta](srcCtx, ids[1]))
+	src.DoTick()
+
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	again, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, data, again)
+
+	dst := newSnapshotTestFixture(t)
+	assert.NilError(t, dst.World.Restore(data))
+	dst.DoTick()
+	dstCtx := cardinal.NewWorldContext(dst.World)
+
+	alphas := filter.Contains(filter.Component[Alpha]())
+	assert.DeepEqual(t, collectSearch[Alpha](t, dstCtx, alphas), collectSearch[Alpha](t, srcCtx, alphas))
+	betas := filter.Exact(filter.Component[Alpha](), filter.Component[Beta]())
+	assert.DeepEqual(t, collectSearch[Beta](t, dstCtx, betas), collectSearch[Beta](t, srcCtx, betas))
+	gammas := filter.Contains(filter.Component[Gamma]())
+	assert.DeepEqual(t, collectSearch[Gamma](t, dstCtx, gammas), collectSearch[Gamma](t, srcCtx, gammas))
+	assert.Check(t, !alive(t, dst.World, ids[0]))
+
+	// The restored world continues with the same entity IDs as the original one.
+	srcID, err := cardinal.Create(srcCtx, Gamma{})
+	assert.NilError(t, err)
+	dstID, err := cardinal.Create(dstCtx, Gamma{})
+	assert.NilError(t, err)
+	assert.Equal(t, srcID, dstID)
+
+	restoredData, err := dst.World.Snapshot()
+	assert.NilError(t, err)
+	srcData, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, restoredData, srcData)
+}
+
+func TestSnapshotCannotBeRestoredIntoAPopulatedWorld(t *testing.T) {
+	src := newSnapshotTestFixture(t)
+	_, err := cardinal.Create(cardinal.NewWorldContext(src.World), Alpha{})
+	assert.NilError(t, err)
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+
+	dst := newSnapshotTestFixture(t)
+	_, err = cardinal.Create(cardinal.NewWorldContext(dst.World), Beta{})
+	assert.NilError(t, err)
+	assert.ErrorIs(t, dst.World.Restore(data), gamestate.ErrStateNotEmpty)
+}
+
+func TestRestoredWorldContinuesTheRandomNumbersOfTheSnapshot(t *testing.T) {
+	src := cardinal.NewTestFixture(t, nil, cardinal.WithSeed(42))
+	var srcRolls []int64
+	assert.NilError(t, cardinal.RegisterSystems(src.World, func(wCtx cardinal.WorldContext) error {
+		srcRolls = append(srcRolls, wCtx.Rand().Int63())
+		return nil
+	}))
+	src.StartWorld()
+	for i := 0; i < 5; i++ {
+		src.DoTick()
+	}
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	for i := 0; i < 5; i++ {
+		src.DoTick()
+	}
+
+	// The restored world has another seed, and is at another tick than the world the snapshot was taken from.
+	dst := cardinal.NewTestFixture(t, nil, cardinal.WithSeed(7))
+	var dstRolls []int64
+	assert.NilError(t, cardinal.RegisterSystems(dst.World, func(wCtx cardinal.WorldContext) error {
+		dstRolls = append(dstRolls, wCtx.Rand().Int63())
+		return nil
+	}))
+	dst.StartWorld()
+	dst.DoTick()
+	assert.NilError(t, dst.World.Restore(data))
+	dstRolls = nil
+	for i := 0; i < 5; i++ {
+		dst.DoTick()
+	}
+	assert.DeepEqual(t, dstRolls, srcRolls[5:])
+}
+
+func TestSnapshotWithUnknownVersionIsRejected(t *testing.T) {
+	tf := newSnapshotTestFixture(t)
+	err := tf.World.Restore([]byte(`{"version":999,"state":{}}`))
+	assert.ErrorIs(t, err, cardinal.ErrUnsupportedSnapshotVersion)
+}
+
+func TestDiffListsExactlyTheChangesBetweenSnapshots(t *testing.T) {
+	tf := newSnapshotTestFixture(t)
+	wCtx := cardinal.NewWorldContext(tf.World)
+	ids, err := cardinal.CreateMany(wCtx, 4, Alpha{Name1: "a"}, Beta{Name1: "b"})
+	assert.NilError(t, err)
+	tf.DoTick()
+	before, err := tf.World.Snapshot()
+	assert.NilError(t, err)
+
+	assert.NilError(t, cardinal.SetComponent[Alpha](wCtx, ids[0], &Alpha{Name1: "changed"}))
+	// Setting a component to the value it already has is not a change.
+	assert.NilError(t, cardinal.SetComponent[Alpha](wCtx, ids[1], &Alpha{Name1: "a"}))
+	assert.NilError(t, cardinal.RemoveComponentFrom[Beta](wCtx, ids[2]))
+	assert.NilError(t, cardinal.AddComponentTo[Gamma](wCtx, ids[2]))
+	assert.NilError(t, cardinal.Remove(wCtx, ids[3]))
+	spawned, err := cardinal.Create(wCtx, Gamma{Name1: "new"})
+	assert.NilError(t, err)
+	tf.DoTick()
+	after, err := tf.World.Snapshot()
+	assert.NilError(t, err)
+
+	delta, err := cardinal.Diff(before, after)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, delta, cardinal.WorldDelta{
+		Added: []cardinal.EntityDelta{
+			{ID: spawned, Components: map[string]json.RawMessage{"gamma": json.RawMessage(`{"Name1":"new"}`)}},
+		},
+		Removed: []types.EntityID{ids[3]},
+		Changed: []cardinal.EntityDelta{
+			{ID: ids[0], Components: map[string]json.RawMessage{"alpha": json.RawMessage(`{"Name1":"changed"}`)}},
+			{
+				ID:                ids[2],
+				Components:        map[string]json.RawMessage{"gamma": json.RawMessage(`{"Name1":""}`)},
+				RemovedComponents: []string{"beta"},
+			},
+		},
+	})
+
+	noChange, err := cardinal.Diff(after, after)
+	assert.NilError(t, err)
+	assert.Check(t, noChange.IsEmpty())
+}
+
+type Mass struct {
+	Grams uint32
+}
+
+func (Mass) Name() string { return "mass" }
+
+// massCodec encodes a Mass as 4 big-endian bytes and counts how often it is used.
+type massCodec struct {
+	marshaled, unmarshaled int
+}
+
+func (c *massCodec) Marshal(m Mass) ([]byte, error) {
+	c.marshaled++
+	return binary.BigEndian.AppendUint32(nil, m.Grams), nil
+}
+
+func (c *massCodec) Unmarshal(bz []byte) (Mass, error) {
+	c.unmarshaled++
+	if len(bz) != 4 {
+		return Mass{}, errors.New("mass must be 4 bytes")
+	}
+	return Mass{Grams: binary.BigEndian.Uint32(bz)}, nil
+}
+
+func TestSnapshotRoundTripsThroughCustomCodec(t *testing.T) {
+	newFixture := func(mc *massCodec) *cardinal.TestFixture {
+		tf := cardinal.NewTestFixture(t, nil)
+		assert.NilError(t, cardinal.RegisterComponent[Alpha](tf.World))
+		assert.NilError(t, cardinal.RegisterComponentWithCodec[Mass](tf.World, mc))
+		tf.StartWorld()
+		return tf
+	}
+
+	srcCodec := &massCodec{}
+	src := newFixture(srcCodec)
+	srcCtx := cardinal.NewWorldContext(src.World)
+	id, err := cardinal.Create(srcCtx, Alpha{Name1: "a"}, Mass{Grams: 1500})
+	assert.NilError(t, err)
+	src.DoTick()
+
+	srcCodec.marshaled = 0
+	data, err := src.World.Snapshot()
+	assert.NilError(t, err)
+	assert.Equal(t, srcCodec.marshaled, 1)
+	assert.Check(t, json.Valid(data))
+
+	dstCodec := &massCodec{}
+	dst := newFixture(dstCodec)
+	assert.NilError(t, dst.World.Restore(data))
+	assert.Check(t, dstCodec.unmarshaled > 0)
+	dst.DoTick()
+
+	dstCtx := cardinal.NewReadOnlyWorldContext(dst.World)
+	mass, err := cardinal.GetComponent[Mass](dstCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, *mass, Mass{Grams: 1500})
+	alpha, err := cardinal.GetComponent[Alpha](dstCtx, id)
+	assert.NilError(t, err)
+	assert.Equal(t, alpha.Name1, "a")
+
+	// Clients still see the component as JSON.
+	raw, err := dst.World.StoreReader().GetComponentForEntityInRawJSON(mustComponent(t, dst.World, "mass"), id)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), `{"Grams":1500}`)
+}
+
+func mustComponent(t *testing.T, w *cardinal.World, name string) types.ComponentMetadata {
+	comp, err := w.GetComponentByName(name)
+	assert.NilError(t, err)
+	return comp
+}
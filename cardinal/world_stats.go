@@ -0,0 +1,53 @@
+package cardinal
+
+import (
+	"pkg.world.dev/world-engine/cardinal/types"
+)
+
+// WorldStats is a snapshot of how many entities and archetypes exist in the world.
+type WorldStats struct {
+	EntityCount    int
+	ArchetypeCount int
+	Archetypes     []ArchetypeStats
+}
+
+// ArchetypeStats is the number of entities that belong to a single archetype.
+type ArchetypeStats struct {
+	ArchetypeID types.ArchetypeID
+	Components  []string
+	EntityCount int
+}
+
+// Stats returns the total number of entities, the number of archetypes, and the distribution of entities across
+// archetypes, including any changes made during the current tick. It only reads the size of each archetype's entity
+// list, so it is cheap enough to be called once per tick for metrics.
+func (w *World) Stats() (WorldStats, error) {
+	archCount := w.entityStore.ArchetypeCount()
+	stats := WorldStats{
+		EntityCount:    0,
+		ArchetypeCount: archCount,
+		Archetypes:     make([]ArchetypeStats, 0, archCount),
+	}
+	for i := 0; i < archCount; i++ {
+		archID := types.ArchetypeID(i)
+		comps, err := w.entityStore.GetComponentTypesForArchID(archID)
+		if err != nil {
+			return WorldStats{}, err
+		}
+		ids, err := w.entityStore.GetEntitiesForArchID(archID)
+		if err != nil {
+			return WorldStats{}, err
+		}
+		compNames := make([]string, 0, len(comps))
+		for _, comp := range comps {
+			compNames = append(compNames, comp.Name())
+		}
+		stats.EntityCount += len(ids)
+		stats.Archetypes = append(stats.Archetypes, ArchetypeStats{
+			ArchetypeID: archID,
+			Components:  compNames,
+			EntityCount: len(ids),
+		})
+	}
+	return stats, nil
+}
@@ -5,13 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/rotisserie/eris"
 
 	"pkg.world.dev/world-engine/assert"
 	"pkg.world.dev/world-engine/cardinal/filter"
+	"pkg.world.dev/world-engine/cardinal/receipt"
 	"pkg.world.dev/world-engine/cardinal/types"
 	"pkg.world.dev/world-engine/cardinal/worldstage"
 )
@@ -126,7 +130,7 @@ func TestCanRecoverStateAfterFailedArchetypeChange(t *testing.T) {
 			assert.NilError(t, err)
 			assert.Equal(t, 5, s.Val)
 		}
-		world.Shutdown()
+		assert.NilError(t, world.Shutdown(context.Background()))
 
 		CleanupViper(t)
 	}
@@ -200,7 +204,7 @@ func TestCanIdentifyAndFixSystemError(t *testing.T) {
 	err = doTickCapturePanic(ctx, world)
 	assert.ErrorContains(t, err, errorSystem.Error())
 
-	world.Shutdown()
+	assert.NilError(t, world.Shutdown(context.Background()))
 
 	// Set up a new engine using the same storage layer
 	world2, err := NewWorld(WithPort(getOpenPort(t)))
@@ -234,7 +238,196 @@ func TestCanIdentifyAndFixSystemError(t *testing.T) {
 	p1, err := GetComponent[onePowerComponent](world2Ctx, id)
 	assert.NilError(t, err)
 	assert.Equal(t, 3, p1.Power)
-	world2.Shutdown()
+	assert.NilError(t, world2.Shutdown(context.Background()))
+}
+
+func TestSystemTimeoutAbortsTickWithoutCommittingState(t *testing.T) {
+	ctx := context.Background()
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[onePowerComponent](world))
+
+	slow := false
+	err := RegisterSystemsWithTimeout(world, 50*time.Millisecond, func(wCtx WorldContext) error {
+		id, err := NewSearch().Entity(filter.Exact(filter.Component[onePowerComponent]())).First(wCtx)
+		if err != nil {
+			return nil //nolint:nilerr // no entity has been created yet
+		}
+		if err := UpdateComponent[onePowerComponent](wCtx, id, func(p *onePowerComponent) *onePowerComponent {
+			p.Power++
+			return p
+		}); err != nil {
+			return err
+		}
+		if slow {
+			<-wCtx.Context().Done()
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	tf.StartWorld()
+
+	id, err := Create(NewWorldContext(world), onePowerComponent{})
+	assert.NilError(t, err)
+	world.tickTheEngine(ctx, nil)
+
+	slow = true
+	err = doTickCapturePanic(ctx, world)
+	assert.ErrorContains(t, err, ErrSystemTimeout.Error())
+
+	// The update made before the system timed out was not committed.
+	p, err := GetComponent[onePowerComponent](NewReadOnlyWorldContext(world), id)
+	assert.NilError(t, err)
+	assert.Equal(t, 1, p.Power)
+}
+
+func TestStalledSystemIsReportedByTheWatchdog(t *testing.T) {
+	stalled := make(chan string, 1)
+	onStall := onSystemStall
+	onSystemStall = func(sys systemType) { stalled <- sys.Name }
+	t.Cleanup(func() { onSystemStall = onStall })
+
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	release := make(chan struct{})
+	blocking := false
+	stuckSystem := func(WorldContext) error {
+		if blocking {
+			// Ignores the context of the world context.
+			<-release
+		}
+		return nil
+	}
+	assert.NilError(t, RegisterSystemsWithTimeout(world, 20*time.Millisecond, stuckSystem))
+	tf.StartWorld()
+	world.tickTheEngine(context.Background(), nil)
+
+	blocking = true
+	tickErr := make(chan error, 1)
+	go func() { tickErr <- doTickCapturePanic(context.Background(), world) }()
+	select {
+	case name := <-stalled:
+		assert.Check(t, strings.HasPrefix(name, "cardinal."+t.Name()), name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stalled system was not reported")
+	}
+
+	close(release)
+	assert.ErrorContains(t, <-tickErr, ErrSystemTimeout.Error())
+}
+
+func TestPanickingSystemIsSkippedAndTheLoopSurvives(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	var panicked, after []uint64
+	assert.NilError(t, RegisterSystems(world,
+		func(wCtx WorldContext) error {
+			if wCtx.CurrentTick()%2 == 1 {
+				panicked = append(panicked, wCtx.CurrentTick())
+				panic("system failed")
+			}
+			return nil
+		},
+		func(wCtx WorldContext) error {
+			after = append(after, wCtx.CurrentTick())
+			return nil
+		},
+	))
+
+	for i := 0; i < 4; i++ {
+		tf.DoTick()
+	}
+
+	assert.DeepEqual(t, panicked, []uint64{1, 3})
+	assert.DeepEqual(t, after, []uint64{0, 1, 2, 3})
+	assert.Equal(t, world.CurrentTick(), uint64(4))
+}
+
+func TestChangesOfAPanickingSystemAreRolledBack(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[onePowerComponent](world))
+	assert.NilError(t, RegisterComponent[twoPowerComponent](world))
+	assert.NilError(t, RegisterSystems(world,
+		func(wCtx WorldContext) error {
+			_, err := Create(wCtx, onePowerComponent{Power: 1})
+			return err
+		},
+		func(wCtx WorldContext) error {
+			if _, err := Create(wCtx, twoPowerComponent{Power: 2}); err != nil {
+				return err
+			}
+			panic("system failed")
+		},
+	))
+	tf.StartWorld()
+	tf.DoTick()
+
+	wCtx := NewReadOnlyWorldContext(world)
+	ones, err := NewSearch().Entity(filter.Contains(filter.Component[onePowerComponent]())).Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, ones, 1)
+	twos, err := NewSearch().Entity(filter.Contains(filter.Component[twoPowerComponent]())).Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, twos, 0)
+}
+
+func TestStrictSystemPanicsCrashTheTick(t *testing.T) {
+	ctx := context.Background()
+	tf := NewTestFixture(t, nil, WithStrictSystemPanics())
+	world := tf.World
+	assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+		if wCtx.CurrentTick() == 1 {
+			panic("system failed")
+		}
+		return nil
+	}))
+	tf.StartWorld()
+	world.tickTheEngine(ctx, nil)
+
+	err := doTickCapturePanic(ctx, world)
+	assert.ErrorContains(t, err, "system failed")
+}
+
+func TestMessageHandlerPanicsAreOnlyRecoveredIfTheyAreNotFatalOrStrict(t *testing.T) {
+	type panicIn struct{ Fatal bool }
+	type panicOut struct{}
+	testCases := []struct {
+		name   string
+		opts   []WorldOption
+		fatal  bool
+		wantOK bool
+	}{
+		{name: "recovered", wantOK: true},
+		{name: "fatal", fatal: true},
+		{name: "strict", opts: []WorldOption{WithStrictSystemPanics()}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			tf := NewTestFixture(t, nil, tc.opts...)
+			world := tf.World
+			assert.NilError(t, RegisterMessage[panicIn, panicOut](world, "panic"))
+			assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+				return EachMessage[panicIn, panicOut](wCtx, func(tx TxData[panicIn]) (panicOut, error) {
+					if tx.Msg.Fatal {
+						panicOnFatalError(wCtx, errors.New("state is unavailable"))
+					}
+					panic("handler failed")
+				})
+			}))
+			tf.StartWorld()
+			_, err := world.SubmitBatch([]PendingMessage{{MessageName: "panic", Value: panicIn{Fatal: tc.fatal}}})
+			assert.NilError(t, err)
+
+			err = doTickCapturePanic(ctx, world)
+			if tc.wantOK {
+				assert.NilError(t, err)
+			} else {
+				assert.Assert(t, err != nil)
+			}
+		})
+	}
 }
 
 type Foo struct{}
@@ -342,7 +535,7 @@ func TestSystemsPanicOnRedisError(t *testing.T) {
 			}()
 			<-world.worldStage.NotifyOnStage(worldstage.Running)
 			defer func() {
-				world.Shutdown()
+				assert.NilError(t, world.Shutdown(context.Background()))
 			}()
 
 			// The first tick sets up the entity
@@ -383,3 +576,397 @@ func getOpenPort(t testing.TB) string {
 	assert.NilError(t, err)
 	return fmt.Sprintf("%d", tcpAddr.Port)
 }
+
+func TestWorldStatsTrackSpawnsAndRemoves(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[ScalarComponentStatic](world))
+	assert.NilError(t, RegisterComponent[ScalarComponentToggle](world))
+	tf.StartWorld()
+
+	before, err := world.Stats()
+	assert.NilError(t, err)
+
+	wCtx := NewWorldContext(world)
+	staticIDs, err := CreateMany(wCtx, 5, ScalarComponentStatic{})
+	assert.NilError(t, err)
+	_, err = CreateMany(wCtx, 3, ScalarComponentStatic{}, ScalarComponentToggle{})
+	assert.NilError(t, err)
+	assert.NilError(t, Remove(wCtx, staticIDs[0]))
+	assert.NilError(t, Remove(wCtx, staticIDs[1]))
+
+	stats, err := world.Stats()
+	assert.NilError(t, err)
+	assert.Equal(t, stats.EntityCount, before.EntityCount+6)
+	assert.Equal(t, stats.ArchetypeCount, before.ArchetypeCount+2)
+	assert.Equal(t, len(stats.Archetypes), stats.ArchetypeCount)
+
+	newArchetypes := stats.Archetypes[before.ArchetypeCount:]
+	assert.DeepEqual(t, newArchetypes[0].Components, []string{"static"})
+	assert.Equal(t, newArchetypes[0].EntityCount, 3)
+	assert.Equal(t, len(newArchetypes[1].Components), 2)
+	assert.Equal(t, newArchetypes[1].EntityCount, 3)
+
+	// The stats remain the same once the tick has been committed.
+	tf.DoTick()
+	afterTick, err := world.Stats()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, afterTick, stats)
+}
+
+func TestDirtyComponentsReportOnlyChangedComponents(t *testing.T) {
+	tf := NewTestFixture(t, nil, WithDirtyComponentTracking())
+	world := tf.World
+	assert.NilError(t, RegisterComponent[onePowerComponent](world))
+	assert.NilError(t, RegisterComponent[twoPowerComponent](world))
+
+	var powered types.EntityID
+	assert.NilError(t, RegisterInitSystems(world, func(wCtx WorldContext) error {
+		var err error
+		powered, err = Create(wCtx, onePowerComponent{}, twoPowerComponent{})
+		if err != nil {
+			return err
+		}
+		_, err = Create(wCtx, onePowerComponent{}, twoPowerComponent{})
+		return err
+	}))
+	assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+		if wCtx.CurrentTick() != 1 {
+			return nil
+		}
+		return UpdateComponent[onePowerComponent](wCtx, powered, func(c *onePowerComponent) *onePowerComponent {
+			c.Power++
+			return c
+		})
+	}))
+	tf.StartWorld()
+
+	// Spawning entities sets all of their components.
+	tf.DoTick()
+	assert.Equal(t, len(world.DirtyComponents()), 4)
+
+	tf.DoTick()
+	assert.DeepEqual(t, world.DirtyComponents(), []ComponentChange{
+		{EntityID: powered, ComponentName: onePowerComponent{}.Name()},
+	})
+
+	tf.DoTick()
+	assert.Equal(t, len(world.DirtyComponents()), 0)
+}
+
+func TestDirtyComponentsAreNotTrackedByDefault(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	assert.NilError(t, RegisterComponent[onePowerComponent](world))
+	tf.StartWorld()
+
+	_, err := Create(NewWorldContext(world), onePowerComponent{})
+	assert.NilError(t, err)
+	assert.Check(t, world.DirtyComponents() == nil)
+}
+
+func TestStartGameAsyncShutsDownCleanly(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	world := tf.World
+	type pingIn struct{}
+	type pingOut struct{}
+	assert.NilError(t, RegisterMessage[pingIn, pingOut](world, "ping"))
+	pings := 0
+	assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+		return EachMessage[pingIn, pingOut](wCtx, func(TxData[pingIn]) (pingOut, error) {
+			pings++
+			return pingOut{}, nil
+		})
+	}))
+	goroutinesBefore := runtime.NumGoroutine()
+
+	errCh, err := world.StartGameAsync()
+	assert.NilError(t, err)
+	assert.Check(t, world.IsGameRunning())
+	for i := 0; i < 3; i++ {
+		tf.StartTickCh <- time.Now()
+		<-tf.DoneTickCh
+	}
+	tickBeforeShutdown := world.CurrentTick()
+
+	// A message that is still waiting when the world shuts down is processed in a final tick.
+	pingMsg, ok := world.GetMessageByFullName("game.ping")
+	assert.Check(t, ok)
+	tf.AddTransaction(pingMsg.ID(), pingIn{})
+	go func() {
+		for range tf.DoneTickCh { //nolint:revive // This pattern drains the channel until closed
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NilError(t, world.Shutdown(ctx))
+	assert.NilError(t, <-errCh)
+	assert.Equal(t, pings, 1)
+	assert.Equal(t, world.CurrentTick(), tickBeforeShutdown+1)
+
+	// Once shut down, the only goroutine the world leaves behind is the one that unblocks late calls to
+	// WaitForNextTick.
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutinesBefore+1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Check(t, runtime.NumGoroutine() <= goroutinesBefore+1,
+		"%d goroutines before starting, %d after shutting down", goroutinesBefore, runtime.NumGoroutine())
+}
+
+func TestShutdownHooksRunInReverseOrder(t *testing.T) {
+	var calls []string
+	tf := NewTestFixture(t, nil,
+		WithShutdownHook(func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.Check(t, hasDeadline)
+			calls = append(calls, "first")
+			return nil
+		}),
+		WithShutdownHook(func(context.Context) error {
+			calls = append(calls, "second")
+			// A failing hook does not prevent the first hook from running.
+			return errors.New("failed to flush")
+		}),
+	)
+	tf.StartWorld()
+	assert.Equal(t, len(calls), 0)
+
+	assert.NilError(t, tf.World.Shutdown(context.Background()))
+	assert.DeepEqual(t, calls, []string{"second", "first"})
+}
+
+// countTicks starts the world of tf and returns the number of ticks that are completed within the given window.
+func countTicks(tf *TestFixture, window time.Duration) int {
+	tf.StartWorld()
+	ticks := 0
+	timeout := time.After(window)
+	for {
+		select {
+		case <-tf.DoneTickCh:
+			ticks++
+		case <-timeout:
+			return ticks
+		}
+	}
+}
+
+func TestTickRateRunsTicksAtAFixedRate(t *testing.T) {
+	tf := NewTestFixture(t, nil, WithTickRate(20))
+	ticks := countTicks(tf, time.Second)
+	assert.Check(t, ticks >= 15 && ticks <= 25, "got %d ticks in one second at 20 ticks per second", ticks)
+}
+
+func TestTickRateBuildsOnTheTickRateConfig(t *testing.T) {
+	t.Setenv("CARDINAL_TICK_RATE", "20")
+	tf := NewTestFixture(t, nil)
+	assert.Equal(t, tf.World.tickRate, uint64(20))
+
+	tf = NewTestFixture(t, nil, WithTickRate(50))
+	assert.Equal(t, tf.World.tickRate, uint64(50))
+	assert.Check(t, tf.World.tickChannel != nil)
+}
+
+func TestTickRateDropsTicksThatAreMissedByASlowTick(t *testing.T) {
+	tf := NewTestFixture(t, nil, WithTickRate(20))
+	assert.NilError(t, RegisterSystems(tf.World, func(WorldContext) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	}))
+	// Without dropping missed ticks, the game loop would try to run 20 ticks in this second.
+	ticks := countTicks(tf, time.Second)
+	assert.Check(t, ticks <= 6, "got %d ticks in one second with ticks that take 200ms", ticks)
+}
+
+func TestPostTickHookRunsOnceAfterEachTick(t *testing.T) {
+	type tickInfo struct {
+		Tick, Timestamp uint64
+		Receipts        int
+	}
+	var ticks []tickInfo
+	tf := NewTestFixture(t, nil,
+		WithPostTickHook(func(tick, timestamp uint64, receipts []receipt.Receipt) error {
+			ticks = append(ticks, tickInfo{tick, timestamp, len(receipts)})
+			return nil
+		}),
+		// Without strict post tick hooks, a failing hook is logged and the game loop keeps going.
+		WithPostTickHook(func(uint64, uint64, []receipt.Receipt) error {
+			return errors.New("failed to broadcast")
+		}),
+	)
+	world := tf.World
+	type pingIn struct{}
+	type pingOut struct{}
+	assert.NilError(t, RegisterMessage[pingIn, pingOut](world, "ping"))
+	assert.NilError(t, RegisterSystems(world, func(wCtx WorldContext) error {
+		return EachMessage[pingIn, pingOut](wCtx, func(TxData[pingIn]) (pingOut, error) {
+			return pingOut{}, nil
+		})
+	}))
+	tf.StartWorld()
+
+	pingMsg, ok := world.GetMessageByFullName("game.ping")
+	assert.Check(t, ok)
+	tf.DoTick()
+	tf.AddTransaction(pingMsg.ID(), pingIn{})
+	tf.DoTick()
+	tf.DoTick()
+
+	assert.Equal(t, len(ticks), 3)
+	for i, info := range ticks {
+		assert.Equal(t, info.Tick, uint64(i))
+		assert.Check(t, info.Timestamp > 0)
+	}
+	assert.Equal(t, ticks[0].Receipts, 0)
+	assert.Equal(t, ticks[1].Receipts, 1)
+	assert.Equal(t, ticks[2].Receipts, 0)
+}
+
+func TestStrictPostTickHookErrorFailsTheTick(t *testing.T) {
+	fail := false
+	tf := NewTestFixture(t, nil,
+		WithStrictPostTickHooks(),
+		WithPostTickHook(func(uint64, uint64, []receipt.Receipt) error {
+			if fail {
+				return errors.New("failed to broadcast")
+			}
+			return nil
+		}),
+	)
+	tf.StartWorld()
+	tf.DoTick()
+
+	fail = true
+	err := doTickCapturePanic(context.Background(), tf.World)
+	assert.ErrorContains(t, err, "failed to broadcast")
+	// The hook failed before the tick was committed.
+	assert.Equal(t, tf.World.CurrentTick(), uint64(1))
+	tick, err := tf.World.entityStore.GetLastFinalizedTick()
+	assert.NilError(t, err)
+	assert.Equal(t, tick, uint64(1))
+}
+
+type spatialIndexPosition struct {
+	X, Y float64
+}
+
+func (spatialIndexPosition) Name() string {
+	return "spatialIndexPosition"
+}
+
+func (p spatialIndexPosition) Position() (float64, float64) {
+	return p.X, p.Y
+}
+
+func TestSpatialIndexDropsThePositionsOfAFailedTick(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	assert.NilError(t, RegisterComponent[spatialIndexPosition](tf.World))
+	idx, err := RegisterSpatialIndex[spatialIndexPosition](tf.World, 10)
+	assert.NilError(t, err)
+
+	fail := false
+	err = RegisterSystems(tf.World, func(wCtx WorldContext) error {
+		if _, err := Create(wCtx, spatialIndexPosition{X: 1, Y: 1}); err != nil {
+			return err
+		}
+		if fail {
+			return errors.New("system failed")
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+	tf.StartWorld()
+	tf.DoTick()
+	assert.Equal(t, len(idx.QueryRadius(Point{X: 0, Y: 0}, 2)), 1)
+
+	fail = true
+	err = doTickCapturePanic(context.Background(), tf.World)
+	assert.ErrorContains(t, err, "system failed")
+	assert.Equal(t, len(idx.QueryRadius(Point{X: 0, Y: 0}, 2)), 1)
+}
+
+func TestHealthReportsAdvancingTicks(t *testing.T) {
+	tf := NewTestFixture(t, nil, WithHealthStallThreshold(time.Second))
+	tf.StartWorld()
+
+	tf.DoTick()
+	first := tf.World.Health()
+	assert.Check(t, first.Healthy)
+	assert.Check(t, first.TimeSinceLastTick < time.Second)
+	assert.Check(t, !first.ShardConnected)
+
+	tf.DoTick()
+	second := tf.World.Health()
+	assert.Check(t, second.Healthy)
+	assert.Equal(t, second.Tick, first.Tick+1)
+	assert.Check(t, second.TimeSinceLastTick < time.Second)
+}
+
+func TestHealthReportsStalledTickLoop(t *testing.T) {
+	tf := NewTestFixture(t, nil, WithHealthStallThreshold(50*time.Millisecond))
+	tf.StartWorld()
+	tf.DoTick()
+
+	time.Sleep(100 * time.Millisecond)
+	health := tf.World.Health()
+	assert.Check(t, !health.Healthy)
+	assert.Check(t, health.TimeSinceLastTick >= 100*time.Millisecond)
+}
+
+// unkeyedFilter matches the same archetypes as the filter it wraps, but has no key.
+type unkeyedFilter struct {
+	filter.ComponentFilter
+}
+
+func TestOnlySearchesWithoutAFilterKeyCacheArchetypesPerWorld(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	assert.NilError(t, RegisterComponent[ScalarComponentStatic](tf.World))
+	tf.StartWorld()
+	wCtx := NewWorldContext(tf.World)
+	_, err := Create(wCtx, ScalarComponentStatic{})
+	assert.NilError(t, err)
+
+	contains := filter.Contains(filter.Component[ScalarComponentStatic]())
+	keyed := NewSearch().Entity(contains).(*Search)
+	unkeyed := NewSearch().Entity(unkeyedFilter{contains}).(*Search)
+	for _, search := range []*Search{keyed, unkeyed} {
+		count, err := search.Count(wCtx)
+		assert.NilError(t, err)
+		assert.Equal(t, count, 1)
+	}
+	// The world caches the archetypes of the keyed search, so only the unkeyed search holds on to the world.
+	assert.Equal(t, len(keyed.archMatches.byWorld), 0)
+	assert.Equal(t, len(unkeyed.archMatches.byWorld), 1)
+
+	unkeyed.Reset()
+	assert.Equal(t, len(unkeyed.archMatches.byWorld), 0)
+}
+
+func TestSearchResetRescansTheArchetypesOfTheWorld(t *testing.T) {
+	tf := NewTestFixture(t, nil)
+	assert.NilError(t, RegisterComponent[ScalarComponentStatic](tf.World))
+	tf.StartWorld()
+	wCtx := NewWorldContext(tf.World)
+	_, err := CreateMany(wCtx, 3, ScalarComponentStatic{})
+	assert.NilError(t, err)
+
+	search := NewSearch().Entity(filter.Exact(filter.Component[ScalarComponentStatic]())).(*Search)
+	count, err := search.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+
+	// Forget the matched archetypes without forgetting that they were scanned, so that only a re-scan from the first
+	// archetype finds them again.
+	cache := tf.World.archetypeCache(search.filterKey, true)
+	cache.mu.Lock()
+	cache.archetypes = nil
+	cache.mu.Unlock()
+	count, err = search.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 0)
+
+	search.Reset()
+	count, err = search.Count(wCtx)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+}
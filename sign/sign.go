@@ -42,6 +42,7 @@ type Transaction struct {
 	Namespace  string          `json:"namespace"`
 	Timestamp  int64           `json:"timestamp"`                 // unix millisecond timestamp
 	Salt       uint16          `json:"salt,omitempty"`            // an optional field for additional hash uniqueness
+	Nonce      uint64          `json:"nonce,omitempty"`           // an optional field for replay protection
 	Signature  string          `json:"signature"`                 // hex encoded string
 	Hash       common.Hash     `json:"-"`                         // don't marshal or unmarshal for json
 	Body       json.RawMessage `json:"body" swaggertype:"object"` // json string
@@ -111,6 +112,7 @@ func MappedTransaction(tx map[string]interface{}) (*Transaction, error) {
 		"signature":  true,
 		"timestamp":  true,
 		"salt":       true,
+		"nonce":      true,
 		"body":       true,
 		"hash":       true,
 	}
@@ -177,6 +179,11 @@ func normalizeJSON(data any) ([]byte, error) {
 // sign uses the given private key to sign the personaTag, namespace, timestamp, and data. The timestamp is set
 // automatically to the wall time by the sign function just before signing.
 func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transaction, error) {
+	return signWithNonce(pk, personaTag, namespace, 0, data)
+}
+
+// signWithNonce is like sign, but also signs the given nonce. A zero nonce is not part of the transaction.
+func signWithNonce(pk *ecdsa.PrivateKey, personaTag, namespace string, nonce uint64, data any) (*Transaction, error) {
 	if data == nil || reflect.ValueOf(data).IsZero() {
 		return nil, ErrCannotSignEmptyBody
 	}
@@ -195,6 +202,7 @@ func sign(pk *ecdsa.PrivateKey, personaTag, namespace string, data any) (*Transa
 		Namespace:  namespace,
 		Timestamp:  TimestampNow(),
 		Salt:       uint16(rand.Intn(math.MaxUint16)), //nolint: gosec // additional uniqueness for each hash and sign
+		Nonce:      nonce,
 		Body:       bz,
 	}
 	sp.populateHash()
@@ -224,6 +232,21 @@ func NewTransaction(
 	return sign(pk, personaTag, namespace, data)
 }
 
+// NewTransactionWithNonce signs a given body, tag, and nonce with the given private key. Worlds that enable nonce
+// protection only accept transactions from a persona with a nonce greater than the last one they accepted from it.
+func NewTransactionWithNonce(
+	pk *ecdsa.PrivateKey,
+	personaTag,
+	namespace string,
+	nonce uint64,
+	data any,
+) (*Transaction, error) {
+	if len(personaTag) == 0 || personaTag == SystemPersonaTag {
+		return nil, ErrInvalidPersonaTag
+	}
+	return signWithNonce(pk, personaTag, namespace, nonce, data)
+}
+
 func (s *Transaction) IsSystemTransaction() bool {
 	return s.PersonaTag == SystemPersonaTag
 }
@@ -280,22 +303,20 @@ func (s *Transaction) Verify(hexAddress string) error {
 }
 
 func (s *Transaction) populateHash() {
+	data := [][]byte{
+		[]byte(s.PersonaTag),
+		[]byte(s.Namespace),
+		[]byte(strconv.FormatInt(s.Timestamp, 10)),
+	}
+	// salt and nonce are only included in the hash if they are set
+	// this is needed for kms test with precomputed signature
 	if s.Salt != 0 {
-		s.Hash = crypto.Keccak256Hash(
-			[]byte(s.PersonaTag),
-			[]byte(s.Namespace),
-			[]byte(strconv.FormatInt(s.Timestamp, 10)),
-			[]byte(strconv.FormatInt(int64(s.Salt), 10)),
-			s.Body,
-		)
-	} else {
-		// salt not set, don't include it in the hash
-		// this is needed for kms test with precomputed signature
-		s.Hash = crypto.Keccak256Hash(
-			[]byte(s.PersonaTag),
-			[]byte(s.Namespace),
-			[]byte(strconv.FormatInt(s.Timestamp, 10)),
-			s.Body,
-		)
+		data = append(data, []byte(strconv.FormatInt(int64(s.Salt), 10)))
+	}
+	if s.Nonce != 0 {
+		// The nonce is prefixed so that it can't be mistaken for a salt.
+		data = append(data, []byte("nonce:"+strconv.FormatUint(s.Nonce, 10)))
 	}
+	data = append(data, s.Body)
+	s.Hash = crypto.Keccak256Hash(data...)
 }
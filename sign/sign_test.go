@@ -353,3 +353,23 @@ func TestUnsortedJSONBlobsCanBeSignedAndVerified(t *testing.T) {
 
 	assert.NilError(t, gotTx.Verify(addr))
 }
+
+func TestNonceIsPartOfTheSignedPayload(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	addressHex := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	sp, err := NewTransactionWithNonce(key, "my-tag", "my-namespace", 7, `{"msg": "hello"}`)
+	assert.NilError(t, err)
+	buf, err := sp.Marshal()
+	assert.NilError(t, err)
+	toBeVerified, err := UnmarshalTransaction(buf)
+	assert.NilError(t, err)
+	assert.Equal(t, toBeVerified.Nonce, uint64(7))
+	assert.NilError(t, toBeVerified.Verify(addressHex))
+
+	// Changing the nonce invalidates the signature.
+	toBeVerified.Nonce = 8
+	toBeVerified.Hash = common.Hash{}
+	assert.ErrorIs(t, eris.Unwrap(toBeVerified.Verify(addressHex)), ErrSignatureValidationFailed)
+}